@@ -2,10 +2,23 @@ package devices
 
 import (
 	"context"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// dropReportInterval rate-limits the "dropped N events" summary.
+const dropReportInterval = 10 * time.Second
+
+// Stats counts the events and samples handled by a device.
+type Stats struct {
+	EventsEmitted    uint64 `json:"events_emitted"`
+	EventsDropped    uint64 `json:"events_dropped"`
+	SamplesPublished uint64 `json:"samples_published"`
+	SamplesDropped   uint64 `json:"samples_dropped"`
+}
+
 // Base provides device name and event publishing helpers.
 // Base owns its event channel and manages its lifecycle.
 type Base struct {
@@ -14,6 +27,17 @@ type Base struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	once   sync.Once
+
+	eventsEmitted    atomic.Uint64
+	eventsDropped    atomic.Uint64
+	samplesPublished atomic.Uint64
+	samplesDropped   atomic.Uint64
+
+	// dropped-event summary state
+	dropMu      sync.Mutex
+	dropPending uint64
+	dropLast    time.Time
+	dropEvery   time.Duration
 }
 
 // NewBase constructs a Base with a buffered event channel.
@@ -24,10 +48,11 @@ func NewBase(name string, eventBuf int) Base {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	return Base{
-		name:   name,
-		events: make(chan Event, eventBuf),
-		ctx:    ctx,
-		cancel: cancel,
+		name:      name,
+		events:    make(chan Event, eventBuf),
+		ctx:       ctx,
+		cancel:    cancel,
+		dropEvery: dropReportInterval,
 	}
 }
 
@@ -41,8 +66,26 @@ func (b *Base) Events() <-chan Event {
 	return b.events
 }
 
+// Stats returns a snapshot of the device's counters.
+func (b *Base) Stats() Stats {
+	return Stats{
+		EventsEmitted:    b.eventsEmitted.Load(),
+		EventsDropped:    b.eventsDropped.Load(),
+		SamplesPublished: b.samplesPublished.Load(),
+		SamplesDropped:   b.samplesDropped.Load(),
+	}
+}
+
+// SamplePublished counts a value delivered to the device's output channel.
+func (b *Base) SamplePublished() { b.samplesPublished.Add(1) }
+
+// SampleDropped counts a value dropped because the output channel was full.
+func (b *Base) SampleDropped() { b.samplesDropped.Add(1) }
+
 // Emit publishes an event without blocking.
-// Events are silently dropped if the channel buffer is full or if Base is closed.
+// Events are dropped if the channel buffer is full or if Base is closed.
+// Dropped events are counted in Stats and reported by a rate-limited
+// EventInfo "dropped N events" summary once the consumer catches up.
 func (b *Base) Emit(kind EventKind, msg string, err error, meta map[string]string) {
 	select {
 	case <-b.ctx.Done():
@@ -58,11 +101,61 @@ func (b *Base) Emit(kind EventKind, msg string, err error, meta map[string]strin
 		Err:    err,
 		Meta:   meta,
 	}
+	b.publish(e)
+}
+
+// publish sends a prepared event without blocking.
+// It lets wrappers forward events from other devices unchanged.
+func (b *Base) publish(e Event) {
+	select {
+	case <-b.ctx.Done():
+		return
+	default:
+	}
 
+	b.reportDrops(false)
+
+	select {
+	case b.events <- e:
+		b.eventsEmitted.Add(1)
+	default:
+		// drop if slow consumer
+		b.eventsDropped.Add(1)
+		b.dropMu.Lock()
+		b.dropPending++
+		b.dropMu.Unlock()
+	}
+}
+
+// reportDrops emits a summary of events dropped since the last report,
+// at most once per dropEvery unless force is set.
+func (b *Base) reportDrops(force bool) {
+	b.dropMu.Lock()
+	defer b.dropMu.Unlock()
+
+	if b.dropPending == 0 {
+		return
+	}
+	now := time.Now()
+	if !force && !b.dropLast.IsZero() && now.Sub(b.dropLast) < b.dropEvery {
+		return
+	}
+
+	n := strconv.FormatUint(b.dropPending, 10)
+	e := Event{
+		Device: b.name,
+		Kind:   EventInfo,
+		Time:   now,
+		Msg:    "dropped " + n + " events",
+		Meta:   map[string]string{"dropped": n},
+	}
 	select {
 	case b.events <- e:
+		b.eventsEmitted.Add(1)
+		b.dropPending = 0
+		b.dropLast = now
 	default:
-		// drop if slow consumer. TODO log dropped events, but must be rate limited.
+		// still backed up; try again with the next event
 	}
 }
 
@@ -89,6 +182,7 @@ func (b *Base) EmitBlocking(kind EventKind, msg string, err error, meta map[stri
 	case <-b.ctx.Done():
 		return
 	case b.events <- e:
+		b.eventsEmitted.Add(1)
 	}
 }
 
@@ -116,6 +210,7 @@ func (b *Base) EmitWithContext(ctx context.Context, kind EventKind, msg string,
 	case <-b.ctx.Done():
 		return nil
 	case b.events <- e:
+		b.eventsEmitted.Add(1)
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -128,7 +223,8 @@ func (b *Base) EmitWithContext(ctx context.Context, kind EventKind, msg string,
 // Close is safe to call multiple times.
 func (b *Base) Close() error {
 	b.once.Do(func() {
-		b.cancel() // Signal shutdown
+		b.reportDrops(true) // last chance to report drops
+		b.cancel()          // Signal shutdown
 		close(b.events)
 	})
 	return nil
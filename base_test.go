@@ -511,3 +511,68 @@ func TestBaseNamePersistence(t *testing.T) {
 	b.Close()
 	require.Equal(t, name, b.Name())
 }
+
+func TestBaseStatsCountsEmittedAndDropped(t *testing.T) {
+	t.Parallel()
+
+	b := NewBase("dev", 2)
+	b.Emit(EventInfo, "1", nil, nil)
+	b.Emit(EventInfo, "2", nil, nil)
+	b.Emit(EventInfo, "3", nil, nil) // dropped
+	b.SamplePublished()
+	b.SampleDropped()
+	b.SampleDropped()
+
+	assert.Equal(t, Stats{
+		EventsEmitted:    2,
+		EventsDropped:    1,
+		SamplesPublished: 1,
+		SamplesDropped:   2,
+	}, b.Stats())
+}
+
+func TestBaseReportsDroppedEvents(t *testing.T) {
+	t.Parallel()
+
+	b := NewBase("dev", 1)
+	b.Emit(EventInfo, "fill", nil, nil)
+	b.Emit(EventInfo, "lost1", nil, nil)
+	b.Emit(EventInfo, "lost2", nil, nil)
+
+	require.Equal(t, "fill", (<-b.Events()).Msg)
+
+	// consumer caught up: the next emit is preceded by a summary,
+	// which takes the only slot, so "next" is dropped in turn
+	b.Emit(EventInfo, "next", nil, nil)
+	ev := <-b.Events()
+	assert.Equal(t, EventInfo, ev.Kind)
+	assert.Equal(t, "dropped 2 events", ev.Msg)
+	assert.Equal(t, "2", ev.Meta["dropped"])
+
+	// rate limited: no second summary within the interval
+	b.Emit(EventInfo, "after", nil, nil)
+	assert.Equal(t, "after", (<-b.Events()).Msg)
+
+	// Close flushes the pending count regardless of the interval
+	b.Close()
+	ev, ok := <-b.Events()
+	require.True(t, ok)
+	assert.Equal(t, "dropped 1 events", ev.Msg)
+}
+
+func TestBaseDropReportInterval(t *testing.T) {
+	t.Parallel()
+
+	b := NewBase("dev", 1)
+	b.dropEvery = time.Millisecond
+
+	b.Emit(EventInfo, "fill", nil, nil)
+	b.Emit(EventInfo, "lost", nil, nil)
+	<-b.Events()
+	b.Emit(EventInfo, "next", nil, nil) // summary goes out, "next" dropped
+	assert.Equal(t, "dropped 1 events", (<-b.Events()).Msg)
+
+	time.Sleep(2 * time.Millisecond)
+	b.Emit(EventInfo, "again", nil, nil)
+	assert.Equal(t, "dropped 1 events", (<-b.Events()).Msg)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// adcReading is the output of adc read.
+type adcReading struct {
+	Chip    string    `json:"chip"`
+	Bus     string    `json:"bus"`
+	Addr    string    `json:"addr,omitempty"`
+	Channel int       `json:"channel"`
+	Minus   *int      `json:"minus,omitempty"`
+	Time    time.Time `json:"time"`
+	Volts   float64   `json:"volts"`
+}
+
+func (r adcReading) String() string {
+	where := r.Bus
+	if r.Addr != "" {
+		where += " " + r.Addr
+	}
+	ch := fmt.Sprintf("ch%d", r.Channel)
+	if r.Minus != nil {
+		ch += fmt.Sprintf("-ch%d", *r.Minus)
+	}
+	return fmt.Sprintf("%s %s %s %.4f V", r.Chip, where, ch, r.Volts)
+}
+
+func adcRead(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("BUS ADDR CHANNEL")
+	chip := fs.String("chip", drivers.ChipADS1115, "ADC chip: ads1115 or ads1015 (I2C), or mcp3008 (SPI; BUS is the SPI port and ADDR is -)")
+	gain := fs.Int("gain", 0, "ADS1x15 full-scale range in `mV`: 6144, 4096, 2048, 1024, 512 or 256 (default 2048)")
+	rate := fs.Int("rate", 0, "ADS1x15 data rate in samples per second (default the chip's)")
+	continuous := fs.Bool("continuous", false, "run the ADS1x15 in continuous conversion mode")
+	minus := fs.Int("minus", -1, "read CHANNEL differentially against channel `N`")
+	count := fs.Int("count", 1, "number of readings (0: until interrupted)")
+	interval := fs.Duration("interval", time.Second, "time between readings")
+	args, err := e.parse(fs, args, 3)
+	if err != nil {
+		return err
+	}
+	bus := args[0]
+	var addr uint64
+	if args[1] != "-" {
+		addr, err = parseUint("address", args[1], 7)
+		if err != nil {
+			return err
+		}
+	}
+	channel, err := parseInt("channel", args[2])
+	if err != nil {
+		return err
+	}
+
+	f, err := drivers.ADCFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	adc, err := f.OpenADC(drivers.ADCConfig{
+		Chip:       *chip,
+		Bus:        bus,
+		Addr:       uint16(addr),
+		Gain:       drivers.ADS1x15Gain(*gain),
+		DataRate:   *rate,
+		Continuous: *continuous,
+	})
+	if err != nil {
+		return err
+	}
+	defer adc.Close()
+
+	read := adc.ReadVolts
+	base := adcReading{Chip: *chip, Bus: bus, Channel: channel}
+	if addr != 0 {
+		base.Addr = fmt.Sprintf("0x%02x", addr)
+	}
+	if *minus >= 0 {
+		diff, ok := adc.(drivers.DiffADC)
+		if !ok {
+			return fmt.Errorf("%s on this backend cannot read differentially", *chip)
+		}
+		read = func(ctx context.Context, plus int) (float64, error) {
+			return diff.ReadDiffVolts(ctx, plus, *minus)
+		}
+		base.Minus = minus
+	}
+
+	return repeat(ctx, *count, *interval, func() error {
+		v, err := read(ctx, channel)
+		if err != nil {
+			return err
+		}
+		r := base
+		r.Time, r.Volts = time.Now(), v
+		return e.print(r, r.String())
+	})
+}
+
+// repeat calls fn count times (forever if count is 0), interval apart,
+// until it fails or ctx is canceled.
+func repeat(ctx context.Context, count int, interval time.Duration, fn func() error) error {
+	t := time.NewTicker(max(interval, time.Millisecond))
+	defer t.Stop()
+	for n := 0; count == 0 || n < count; n++ {
+		if n > 0 {
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
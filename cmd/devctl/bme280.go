@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rustyeddy/devices/config"
+	"github.com/rustyeddy/devices/devices/bme280"
+)
+
+// envReading is the output of bme280 read.
+type envReading struct {
+	Bus  string `json:"bus"`
+	Addr string `json:"addr"`
+	bme280.Env
+	Time time.Time `json:"time"`
+}
+
+func (r envReading) String() string {
+	return fmt.Sprintf("%s %s %.2f °C %.0f Pa %.1f %%RH", r.Bus, r.Addr, r.Temperature, r.Pressure, r.Humidity)
+}
+
+func bme280Read(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("")
+	bus := fs.String("bus", "1", "I2C bus")
+	addr := fs.String("addr", "0x76", "I2C address (0x76 or 0x77)")
+	count := fs.Int("count", 1, "number of readings (0: until interrupted)")
+	interval := fs.Duration("interval", time.Second, "time between readings")
+	if _, err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+	a, err := parseUint("address", *addr, 7)
+	if err != nil {
+		return err
+	}
+
+	// The station builder knows how to run a BME280 on every backend,
+	// including the simulated sensor of the mock backend.
+	st := config.Station{
+		Name: "devctl",
+		Devices: []config.DeviceConfig{{
+			Name:     "bme280",
+			Kind:     config.KindBME280,
+			Backend:  e.opts.backend,
+			Bus:      *bus,
+			Addr:     config.Addr(a),
+			Interval: config.Duration(max(*interval, time.Millisecond)),
+		}},
+	}
+	devs, err := st.Build()
+	if err != nil {
+		return err
+	}
+	dev := devs[0].(*bme280.BME280)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- dev.Run(ctx) }()
+
+	for n := 0; *count == 0 || n < *count; n++ {
+		select {
+		case env, ok := <-dev.Out():
+			if !ok {
+				if err := <-errc; err != nil && !errors.Is(err, context.Canceled) {
+					return err
+				}
+				return nil
+			}
+			r := envReading{Bus: *bus, Addr: fmt.Sprintf("0x%02x", a), Env: env, Time: time.Now()}
+			if err := e.print(r, r.String()); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// lineValue is the output of gpio read and gpio write.
+type lineValue struct {
+	Chip   string `json:"chip"`
+	Offset int    `json:"offset"`
+	Value  bool   `json:"value"`
+}
+
+func (v lineValue) String() string {
+	return fmt.Sprintf("%s %d %s", v.Chip, v.Offset, level(v.Value))
+}
+
+// lineEvent is the output of gpio watch.
+type lineEvent struct {
+	Chip   string       `json:"chip"`
+	Offset int          `json:"offset"`
+	Time   time.Time    `json:"time"`
+	Edge   drivers.Edge `json:"edge"`
+	Value  bool         `json:"value"`
+	Seq    uint64       `json:"seq"`
+}
+
+func (v lineEvent) String() string {
+	return fmt.Sprintf("%s %s %d %s %s #%d",
+		v.Time.Format(time.RFC3339Nano), v.Chip, v.Offset, v.Edge, level(v.Value), v.Seq)
+}
+
+func level(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// lineArgs parses CHIP OFFSET.
+func lineArgs(args []string) (string, int, error) {
+	offset, err := parseInt("offset", args[1])
+	return args[0], offset, err
+}
+
+func gpioRead(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("CHIP OFFSET")
+	bias := fs.String("bias", string(drivers.BiasDefault), "bias: default, pullup or pulldown")
+	args, err := e.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if !drivers.Bias(*bias).Valid() {
+		return e.usage(fs, "invalid bias %q (want default, pullup or pulldown)", *bias)
+	}
+	chip, offset, err := lineArgs(args)
+	if err != nil {
+		return err
+	}
+
+	f, err := drivers.GPIOFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	in, err := f.OpenInput(chip, offset, drivers.EdgeNone, drivers.Bias(*bias), 0)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	v, err := in.Read()
+	if err != nil {
+		return err
+	}
+	out := lineValue{Chip: chip, Offset: offset, Value: v}
+	return e.print(out, out.String())
+}
+
+func gpioWrite(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("CHIP OFFSET VALUE")
+	hold := fs.Duration("hold", 0, "keep driving the line this long before releasing it")
+	args, err := e.parse(fs, args, 3)
+	if err != nil {
+		return err
+	}
+	chip, offset, err := lineArgs(args)
+	if err != nil {
+		return err
+	}
+	v, err := parseLevel(args[2])
+	if err != nil {
+		return err
+	}
+
+	f, err := drivers.GPIOFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	line, err := f.OpenOutput(chip, offset, v)
+	if err != nil {
+		return err
+	}
+	defer line.Close()
+
+	if err := line.Write(v); err != nil {
+		return err
+	}
+	out := lineValue{Chip: chip, Offset: offset, Value: v}
+	if err := e.print(out, out.String()); err != nil {
+		return err
+	}
+
+	if *hold > 0 {
+		select {
+		case <-time.After(*hold):
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+// parseLevel accepts 1/0, true/false, on/off and high/low.
+func parseLevel(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "high":
+		return true, nil
+	case "off", "low":
+		return false, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q (want 1/0, true/false, on/off or high/low)", s)
+	}
+	return v, nil
+}
+
+func gpioWatch(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("CHIP OFFSET")
+	edge := fs.String("edge", string(drivers.EdgeBoth), "edges to report: rising, falling or both")
+	bias := fs.String("bias", string(drivers.BiasDefault), "bias: default, pullup or pulldown")
+	debounce := fs.Duration("debounce", 0, "debounce period")
+	count := fs.Int("count", 0, "stop after this many events (0: until interrupted)")
+	args, err := e.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	switch drivers.Edge(*edge) {
+	case drivers.EdgeRising, drivers.EdgeFalling, drivers.EdgeBoth:
+	default:
+		return e.usage(fs, "invalid edge %q (want rising, falling or both)", *edge)
+	}
+	if !drivers.Bias(*bias).Valid() {
+		return e.usage(fs, "invalid bias %q (want default, pullup or pulldown)", *bias)
+	}
+	chip, offset, err := lineArgs(args)
+	if err != nil {
+		return err
+	}
+
+	f, err := drivers.GPIOFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	in, err := f.OpenInput(chip, offset, drivers.Edge(*edge), drivers.Bias(*bias), *debounce)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := in.Events(ctx)
+	if err != nil {
+		return err
+	}
+
+	for n := 0; *count == 0 || n < *count; n++ {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			out := lineEvent{Chip: chip, Offset: offset, Time: ev.Time, Edge: ev.Edge, Value: ev.Value, Seq: ev.Seq}
+			if err := e.print(out, out.String()); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
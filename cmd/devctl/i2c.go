@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rustyeddy/devices/config"
+	"github.com/rustyeddy/devices/drivers"
+	"github.com/rustyeddy/devices/drivers/i2cscan"
+)
+
+func i2cScan(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("BUS")
+	first := fs.String("first", "0x03", "first address to probe")
+	last := fs.String("last", "0x77", "last address to probe")
+	quick := fs.Bool("quick", false, "probe with an SMBus quick write instead of a read")
+	station := fs.String("station", "", "print a station config named `NAME` seeded with the devices found")
+	args, err := e.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	opts := i2cscan.Options{Bus: args[0], Quick: *quick}
+	for _, a := range []struct {
+		s   string
+		dst *uint16
+	}{{*first, &opts.First}, {*last, &opts.Last}} {
+		v, err := parseUint("address", a.s, 7)
+		if err != nil {
+			return err
+		}
+		*a.dst = uint16(v)
+	}
+
+	f, err := drivers.I2CFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	bus, err := f.OpenI2C(args[0])
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	found, err := i2cscan.Scan(ctx, bus, opts)
+	if err != nil {
+		return err
+	}
+
+	if *station != "" {
+		st, skipped := config.Seed(*station, found)
+		for _, d := range skipped {
+			fmt.Fprintf(e.stderr, "devctl: %s (%s at %s) not added to the station\n", d.Name, d.Kind, d.Attributes["addr"])
+		}
+		data, err := yaml.Marshal(st)
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(data)
+		return err
+	}
+
+	for _, d := range found {
+		text := fmt.Sprintf("%s %s %s", d.Attributes["addr"], d.Kind, d.Name)
+		if id := chipDetail(d.Attributes); id != "" {
+			text += " " + id
+		}
+		if err := e.print(d, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chipDetail formats the identification registers read by the scan.
+func chipDetail(attrs map[string]string) string {
+	var parts []string
+	for _, k := range []string{"chip_id", "config"} {
+		if v := attrs[k]; v != "" {
+			parts = append(parts, k+"="+v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,207 @@
+// Command devctl pokes at hardware through the drivers package, for bench
+// bring-up without writing a program per device.
+//
+// Usage:
+//
+//	devctl [flags] gpio read   CHIP OFFSET
+//	devctl [flags] gpio write  CHIP OFFSET VALUE
+//	devctl [flags] gpio watch  CHIP OFFSET
+//	devctl [flags] i2c scan    BUS
+//	devctl [flags] adc read    BUS ADDR CHANNEL
+//	devctl [flags] serial tail PORT BAUD
+//	devctl [flags] oled text   "TEXT"
+//	devctl [flags] bme280 read
+//
+// Common flags, accepted before the command or after it:
+//
+//	--backend NAME   driver backend: gpiocdev, vpio, periph, serial or mock
+//	                 (default $DEVICES_BACKEND, else hardware on a Pi and
+//	                 mock elsewhere)
+//	--json           print one JSON object per line instead of text
+//
+// Run "devctl CMD SUB -h" for the flags of each subcommand.
+//
+// Examples:
+//
+//	devctl gpio write gpiochip0 17 on
+//	devctl --json gpio watch --edge falling gpiochip0 27
+//	devctl i2c scan --station garden 1 > station.yaml
+//	devctl adc read --count 5 1 0x48 0
+//	devctl adc read --chip mcp3008 SPI0.0 - 5
+//	devctl adc read --gain 4096 --minus 3 1 0x49 1
+//	devctl serial tail /dev/ttyUSB0 9600
+//	devctl serial tail --timeout 5s --frame 8N1 /dev/ttyAMA0 921600
+//	devctl oled text --y 20 "hello"
+//	devctl --backend mock --json bme280 read
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const usage = `usage: devctl [--backend NAME] [--json] COMMAND SUBCOMMAND [flags] ARGS...
+
+commands:
+  gpio read   CHIP OFFSET         read a GPIO line
+  gpio write  CHIP OFFSET VALUE   drive a GPIO line (1/0, true/false, on/off)
+  gpio watch  CHIP OFFSET         print edge events until interrupted
+  i2c scan    BUS                 find and identify the chips on an I2C bus
+  adc read    BUS ADDR CHANNEL    read an ADS1x15 or MCP3008 channel in volts
+  serial tail PORT BAUD           print NMEA fixes read from a serial port
+  oled text   "TEXT"              show text on an SSD1306
+  bme280 read                     read temperature, pressure and humidity
+`
+
+// errUsage is returned for bad command lines; the usage text has already
+// been printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "devctl:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// options are the flags shared by every subcommand.
+type options struct {
+	backend string
+	json    bool
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.backend, "backend", o.backend, "driver backend: gpiocdev, vpio, periph, serial or mock (default $DEVICES_BACKEND or auto)")
+	fs.BoolVar(&o.json, "json", o.json, "print one JSON object per line")
+}
+
+// command is one subcommand: args are what follows "CMD SUB".
+type command func(ctx context.Context, env *env, args []string) error
+
+var commands = map[string]map[string]command{
+	"gpio": {
+		"read":  gpioRead,
+		"write": gpioWrite,
+		"watch": gpioWatch,
+	},
+	"i2c":    {"scan": i2cScan},
+	"adc":    {"read": adcRead},
+	"serial": {"tail": serialTail},
+	"oled":   {"text": oledText},
+	"bme280": {"read": bme280Read},
+}
+
+// run parses args and runs the selected subcommand.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	opts := &options{}
+	fs := flag.NewFlagSet("devctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	opts.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	args = fs.Args()
+	if len(args) < 2 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(stderr, "devctl: unknown command %q\n", strings.Join(args[:2], " "))
+		fs.Usage()
+		return errUsage
+	}
+
+	e := &env{opts: opts, name: args[0] + " " + args[1], stdout: stdout, stderr: stderr}
+	return cmd(ctx, e, args[2:])
+}
+
+// env is what a subcommand runs with.
+type env struct {
+	opts   *options
+	name   string
+	stdout io.Writer
+	stderr io.Writer
+
+	mu sync.Mutex // serializes output
+}
+
+// flags returns a FlagSet for the subcommand, with the common flags
+// registered. argsUsage describes the positional arguments.
+func (e *env) flags(argsUsage string) *flag.FlagSet {
+	fs := flag.NewFlagSet("devctl "+e.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: devctl %s [flags] %s\n\nflags:\n", e.name, argsUsage)
+		fs.PrintDefaults()
+	}
+	e.opts.register(fs)
+	return fs
+}
+
+// parse parses args with fs and checks the positional argument count.
+func (e *env) parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() != n {
+		fmt.Fprintf(e.stderr, "devctl %s: want %d arguments, got %d\n", e.name, n, fs.NArg())
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// usage reports a bad flag value, prints the usage of fs and returns
+// errUsage.
+func (e *env) usage(fs *flag.FlagSet, format string, args ...any) error {
+	fmt.Fprintf(e.stderr, "devctl %s: %s\n", e.name, fmt.Sprintf(format, args...))
+	fs.Usage()
+	return errUsage
+}
+
+// print writes v as one JSON line with --json, or as text.
+func (e *env) print(v any, text string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.opts.json {
+		return json.NewEncoder(e.stdout).Encode(v)
+	}
+	_, err := fmt.Fprintln(e.stdout, text)
+	return err
+}
+
+// parseUint parses a decimal or 0x-prefixed number such as an I2C address.
+func parseUint(name, s string, bits int) (uint64, error) {
+	v, err := strconv.ParseUint(s, 0, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return v, nil
+}
+
+// parseInt parses a non-negative decimal number such as a line offset.
+func parseInt(name, s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return v, nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// devctl runs the command line and returns stdout.
+func devctl(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	err := run(ctx, args, &stdout, &stderr)
+	if err != nil {
+		t.Logf("stderr: %s", stderr.String())
+	}
+	return stdout.String(), err
+}
+
+// decode parses the JSON lines of out.
+func decode(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var rows []map[string]any
+	dec := json.NewDecoder(strings.NewReader(out))
+	for {
+		var row map[string]any
+		err := dec.Decode(&row)
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err, out)
+		rows = append(rows, row)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	_, err := devctl(t)
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "gpio", "blink")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "gpio", "read", "gpiochip0")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "--backend", "vpio", "gpio", "read", "--bias", "up", "chip", "1")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "--backend", "vpio", "gpio", "watch", "--edge", "fallng", "chip", "1")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "--backend", "vpio", "gpio", "watch", "--edge", "none", "chip", "1")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = devctl(t, "--backend", "nope", "gpio", "read", "gpiochip0", "1")
+	assert.ErrorIs(t, err, drivers.ErrUnknownBackend)
+	_, err = devctl(t, "--backend", "vpio", "adc", "read", "1", "0x48", "0")
+	assert.ErrorIs(t, err, drivers.ErrUnsupported)
+}
+
+func TestGPIOWriteRead(t *testing.T) {
+	t.Parallel()
+
+	out, err := devctl(t, "--backend", "vpio", "gpio", "write", "rw", "5", "on")
+	require.NoError(t, err)
+	assert.Equal(t, "rw 5 1\n", out)
+
+	// flags are accepted after the subcommand too
+	out, err = devctl(t, "gpio", "read", "--backend=vpio", "--json", "rw", "5")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"chip": "rw", "offset": 5.0, "value": true}}, decode(t, out))
+
+	_, err = devctl(t, "--backend", "vpio", "gpio", "write", "rw", "5", "maybe")
+	assert.ErrorContains(t, err, `invalid value "maybe"`)
+}
+
+func TestGPIOWatch(t *testing.T) {
+	t.Parallel()
+
+	b, err := drivers.Lookup(drivers.BackendVPIO)
+	require.NoError(t, err)
+	vpio := b.GPIO.(*drivers.VPIOFactory)
+	_, err = vpio.OpenInput("watch", 3, drivers.EdgeBoth, drivers.BiasDefault, 0)
+	require.NoError(t, err)
+	vpio.InjectEdge("watch", 3, drivers.EdgeFalling, false)
+	vpio.InjectEdge("watch", 3, drivers.EdgeRising, true)
+
+	out, err := devctl(t, "--backend", "vpio", "--json", "gpio", "watch", "--count", "2", "watch", "3")
+	require.NoError(t, err)
+	rows := decode(t, out)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "falling", rows[0]["edge"])
+	assert.Equal(t, "rising", rows[1]["edge"])
+	assert.Equal(t, true, rows[1]["value"])
+}
+
+func TestI2CScan(t *testing.T) {
+	t.Parallel()
+
+	b, err := drivers.Lookup(drivers.BackendMock)
+	require.NoError(t, err)
+	bus := b.I2C.(*drivers.SimI2CFactory).Bus("scan")
+	bme := drivers.NewRegisterMap()
+	bme.SetBytes(0xd0, 0x60)
+	require.NoError(t, bus.Attach(0x77, bme))
+	require.NoError(t, bus.Attach(0x3d, drivers.NewRegisterMap()))
+
+	out, err := devctl(t, "--backend", "mock", "i2c", "scan", "scan")
+	require.NoError(t, err)
+	assert.Equal(t, "0x3d oled ssd1306_3d\n0x77 bme280 bme280_77 chip_id=0x60\n", out)
+
+	out, err = devctl(t, "--backend", "mock", "--json", "i2c", "scan", "--first", "0x70", "scan")
+	require.NoError(t, err)
+	rows := decode(t, out)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "bme280_77", rows[0]["name"])
+
+	out, err = devctl(t, "--backend", "mock", "i2c", "scan", "--station", "bench", "scan")
+	require.NoError(t, err)
+	assert.Contains(t, out, "name: bench\n")
+	assert.Contains(t, out, "kind: bme280\n")
+	assert.Contains(t, out, "interval: 30s\n")
+}
+
+func TestADCRead(t *testing.T) {
+	t.Parallel()
+
+	out, err := devctl(t, "--backend", "mock", "--json", "adc", "read", "--count", "2", "--interval", "1ms", "1", "0x48", "2")
+	require.NoError(t, err)
+	rows := decode(t, out)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "0x48", rows[0]["addr"])
+	assert.Equal(t, 2.0, rows[0]["channel"])
+	assert.Equal(t, 1.0, rows[0]["volts"])
+
+	_, err = devctl(t, "--backend", "mock", "adc", "read", "1", "0x48", "7")
+	assert.ErrorContains(t, err, "invalid channel 7")
+	_, err = devctl(t, "--backend", "mock", "adc", "read", "1", "0x80", "0")
+	assert.ErrorContains(t, err, `invalid address "0x80"`)
+
+	out, err = devctl(t, "--backend", "mock", "adc", "read", "--chip", "mcp3008", "SPI0.0", "-", "7")
+	require.NoError(t, err)
+	assert.Equal(t, "mcp3008 SPI0.0 ch7 1.0000 V\n", out)
+	_, err = devctl(t, "--backend", "mock", "adc", "read", "--chip", "mcp3008", "--minus", "6", "SPI0.0", "-", "7")
+	assert.ErrorContains(t, err, "cannot read differentially")
+	_, err = devctl(t, "--backend", "mock", "adc", "read", "--chip", "lm35", "1", "-", "0")
+	assert.ErrorIs(t, err, drivers.ErrUnknownADC)
+}
+
+func TestSerialTail(t *testing.T) {
+	t.Parallel()
+
+	b, err := drivers.Lookup(drivers.BackendMock)
+	require.NoError(t, err)
+	f := b.Serial.(*drivers.MockSerialFactory)
+
+	go func() {
+		for {
+			// Retry until the port serial tail opens is there to write to.
+			if p, ok := f.Port("tail0"); ok {
+				if _, err := io.WriteString(p, "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n"); err == nil {
+					return
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	out, err := devctl(t, "--backend", "mock", "--json", "serial", "tail", "--raw", "--count", "1", "tail0", "9600")
+	require.NoError(t, err)
+	rows := decode(t, out)
+	require.Len(t, rows, 2)
+	assert.Contains(t, rows[0]["sentence"], "$GPGGA,123519")
+	fix := rows[1]["fix"].(map[string]any)
+	assert.InDelta(t, 48.1173, fix["Lat"], 0.0001)
+	assert.Equal(t, 8.0, fix["Satellites"])
+}
+
+func TestSerialTailOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := devctl(t, "--backend", "mock", "serial", "tail", "--timeout", "20ms", "--frame", "7e1", "silent0", "9600")
+	assert.ErrorIs(t, err, drivers.ErrSerialTimeout)
+
+	_, err = devctl(t, "--backend", "mock", "serial", "tail", "--frame", "9X1", "silent1", "9600")
+	assert.ErrorContains(t, err, `invalid frame "9X1"`)
+
+	var cfg drivers.SerialConfig
+	require.NoError(t, parseFrame("7E2", &cfg))
+	assert.Equal(t, drivers.SerialConfig{DataBits: 7, Parity: drivers.ParityEven, StopBits: 2}, cfg)
+}
+
+func TestOLEDText(t *testing.T) {
+	t.Parallel()
+
+	out, err := devctl(t, "--backend", "mock", "oled", "text", "--y", "20", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "1 0x3c (0,20) \"hello\"\n", out)
+
+	_, err = devctl(t, "--backend", "mock", "oled", "text", "--addr", "0x3c0", "hello")
+	assert.ErrorContains(t, err, `invalid address "0x3c0"`)
+}
+
+func TestBME280Read(t *testing.T) {
+	t.Parallel()
+
+	out, err := devctl(t, "--backend", "mock", "--json", "bme280", "read", "--count", "2", "--interval", "1ms")
+	require.NoError(t, err)
+	rows := decode(t, out)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "0x76", rows[0]["addr"])
+	assert.InDelta(t, 20.0, rows[0]["temperature"], 0.01)
+	assert.InDelta(t, 101325.0, rows[0]["pressure"], 1)
+
+	_, err = devctl(t, "--backend", "mock", "bme280", "read", "--addr", "0x40")
+	assert.ErrorContains(t, err, "addr 0x40 is invalid")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// oledOutput is the output of oled text.
+type oledOutput struct {
+	Bus  string `json:"bus"`
+	Addr string `json:"addr"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Text string `json:"text"`
+}
+
+func (o oledOutput) String() string {
+	return fmt.Sprintf("%s %s (%d,%d) %q", o.Bus, o.Addr, o.X, o.Y, o.Text)
+}
+
+func oledText(ctx context.Context, e *env, args []string) error {
+	fs := e.flags(`"TEXT"`)
+	bus := fs.String("bus", "1", "I2C bus")
+	addr := fs.String("addr", "0x3c", "I2C address")
+	width := fs.Int("width", 128, "display width in pixels")
+	height := fs.Int("height", 64, "display height in pixels")
+	x := fs.Int("x", 0, "text origin x")
+	y := fs.Int("y", 12, "text baseline y")
+	keep := fs.Bool("keep", false, "draw over the current contents instead of clearing first")
+	args, err := e.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	a, err := parseUint("address", *addr, 7)
+	if err != nil {
+		return err
+	}
+
+	f, err := drivers.OLEDFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+	oled := display.NewOLED(display.OLEDConfig{
+		Name:    "oled",
+		Factory: f,
+		Bus:     *bus,
+		Addr:    uint16(a),
+		Width:   *width,
+		Height:  *height,
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	errc := make(chan error, 1)
+	go func() { errc <- oled.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-errc
+	}()
+
+	cmds := []display.OLEDCommand{
+		{Type: display.CmdClear},
+		{Type: display.CmdText, X: *x, Y: *y, Text: args[0]},
+		{Type: display.CmdFlush},
+	}
+	if *keep {
+		cmds = cmds[1:]
+	}
+	for _, cmd := range cmds {
+		if err := apply(ctx, oled, errc, cmd); err != nil {
+			return fmt.Errorf("oled %s: %w", cmd.Type, err)
+		}
+	}
+
+	out := oledOutput{Bus: *bus, Addr: fmt.Sprintf("0x%02x", a), X: *x, Y: *y, Text: args[0]}
+	return e.print(out, out.String())
+}
+
+var errOLEDTimeout = errors.New("display did not respond")
+
+// apply sends cmd and waits until the display has applied it. errc reports
+// a Run that failed (for example, to open the display) before that.
+func apply(ctx context.Context, oled *display.OLED, errc chan error, cmd display.OLEDCommand) error {
+	cmd.Done = make(chan error, 1)
+	timeout := time.After(5 * time.Second)
+	select {
+	case oled.In() <- cmd:
+	case err := <-errc:
+		errc <- err // for the deferred wait
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return errOLEDTimeout
+	}
+	select {
+	case err := <-cmd.Done:
+		return err
+	case err := <-errc:
+		errc <- err
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return errOLEDTimeout
+	}
+}
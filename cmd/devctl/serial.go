@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rustyeddy/devices/devices/gtu7"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// sentence is printed for each raw NMEA line with --raw.
+type sentence struct {
+	Port     string `json:"port"`
+	Sentence string `json:"sentence"`
+}
+
+// gpsFix is the output of serial tail.
+type gpsFix struct {
+	Port string      `json:"port"`
+	Fix  gtu7.GPSFix `json:"fix"`
+}
+
+func (f gpsFix) String() string {
+	s := fmt.Sprintf("%s lat=%.6f lon=%.6f alt=%.1fm sats=%d hdop=%.1f speed=%.2fm/s course=%.1f",
+		f.Port, f.Fix.Lat, f.Fix.Lon, f.Fix.AltMeters, f.Fix.Satellites, f.Fix.HDOP, f.Fix.SpeedMPS, f.Fix.CourseDeg)
+	if f.Fix.TimeValid {
+		s += " time=" + f.Fix.Time.Format(time.RFC3339Nano)
+	}
+	return s
+}
+
+func serialTail(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("PORT BAUD")
+	raw := fs.Bool("raw", false, "also print every line read")
+	count := fs.Int("count", 0, "stop after this many fixes (0: until interrupted)")
+	frame := fs.String("frame", "8N1", "data bits, parity (N, O, E, M or S) and stop bits")
+	rtscts := fs.Bool("rtscts", false, "use RTS/CTS hardware flow control")
+	timeout := fs.Duration("timeout", 0, "fail if the port is silent this long (0: wait forever)")
+	args, err := e.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	baud, err := parseInt("baud", args[1])
+	if err != nil {
+		return err
+	}
+
+	cfg := drivers.SerialConfig{Port: name, Baud: baud, RTSCTS: *rtscts, ReadTimeout: *timeout}
+	if err := parseFrame(*frame, &cfg); err != nil {
+		return err
+	}
+
+	f, err := drivers.SerialFor(e.opts.backend)
+	if err != nil {
+		return err
+	}
+
+	gcfg := gtu7.GTU7Config{Name: name, Serial: cfg, Factory: f}
+	if *raw {
+		// Lines are echoed on their way to the parser.
+		gcfg.OnLine = func(line string) {
+			_ = e.print(sentence{Port: name, Sentence: line}, line)
+		}
+	}
+	gps := gtu7.NewGTU7(gcfg)
+
+	// Canceling makes Run close the port, which unblocks the reader.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- gps.Run(ctx) }()
+
+	for n := 0; *count == 0 || n < *count; n++ {
+		fix, ok := <-gps.Out()
+		if !ok {
+			// The port failed or ended on its own, or ctx was canceled.
+			return <-errc
+		}
+		out := gpsFix{Port: name, Fix: fix}
+		if err := e.print(out, out.String()); err != nil {
+			return err
+		}
+	}
+	cancel()
+	return <-errc
+}
+
+// parseFrame parses framing such as "8N1" or "7E2" into cfg.
+func parseFrame(s string, cfg *drivers.SerialConfig) error {
+	parities := map[byte]drivers.Parity{
+		'N': drivers.ParityNone,
+		'O': drivers.ParityOdd,
+		'E': drivers.ParityEven,
+		'M': drivers.ParityMark,
+		'S': drivers.ParitySpace,
+	}
+	s = strings.ToUpper(s)
+	if len(s) != 3 || s[0] < '5' || s[0] > '8' || parities[s[1]] == "" || (s[2] != '1' && s[2] != '2') {
+		return fmt.Errorf("invalid frame %q (want e.g. 8N1 or 7E2)", s)
+	}
+	cfg.DataBits = int(s[0] - '0')
+	cfg.Parity = parities[s[1]]
+	cfg.StopBits = int(s[2] - '0')
+	return nil
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/devices/bme280"
+	"github.com/rustyeddy/devices/devices/button"
+	"github.com/rustyeddy/devices/devices/gtu7"
+	"github.com/rustyeddy/devices/devices/led"
+	"github.com/rustyeddy/devices/devices/relay"
+	"github.com/rustyeddy/devices/devices/vh400"
+	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// Defaults applied by Build when a field is left empty.
+const (
+	DefaultChip       = "gpiochip0"
+	DefaultVH400Addr  = 0x48
+	DefaultOLEDAddr   = 0x3c
+	DefaultOLEDWidth  = 128
+	DefaultOLEDHeight = 64
+	DefaultBaud       = 9600
+)
+
+// NewManager builds the station's devices and registers them, in file
+// order, with a Manager named after the station.
+func (s *Station) NewManager() (*devices.Manager, error) {
+	devs, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+	m := devices.NewManager(s.Name, 0)
+	for _, d := range devs {
+		if err := m.Add(d); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Build validates the station and constructs its devices in file order.
+// Devices are ready to Run. Factories come from the drivers backend
+// registry, so devices on the same backend share its factory (the vpio
+// and mock GPIO lines, for example, are visible to every device and test
+// that opens them).
+func (s *Station) Build() ([]devices.Device, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	out := make([]devices.Device, 0, len(s.Devices))
+	for i, d := range s.Devices {
+		backend, err := s.backend(d)
+		var dev devices.Device
+		if err == nil {
+			dev, err = build(d, backend)
+		}
+		if err != nil {
+			return nil, &Error{Index: i, Name: d.Name, Line: s.line(i), Err: err}
+		}
+		out = append(out, dev)
+	}
+	return out, nil
+}
+
+func build(d DeviceConfig, backend drivers.Backend) (devices.Device, error) {
+	emitInitial := d.EmitInitial == nil || *d.EmitInitial
+
+	switch d.Kind {
+	case KindButton, KindRelay, KindLED:
+		f := backend.GPIO
+		chip := d.Chip
+		if chip == "" {
+			chip = DefaultChip
+		}
+		switch d.Kind {
+		case KindButton:
+			return button.NewButton(button.ButtonConfig{
+				Name:     d.Name,
+				Factory:  f,
+				Chip:     chip,
+				Offset:   *d.Offset,
+				Bias:     drivers.Bias(d.Bias),
+				Edge:     drivers.Edge(d.Edge),
+				Debounce: d.Debounce.D(),
+			}), nil
+		case KindRelay:
+			return relay.New(relay.RelayConfig{Name: d.Name, Factory: f, Chip: chip, Offset: *d.Offset, Initial: d.Initial}), nil
+		default:
+			return led.New(led.LEDConfig{Name: d.Name, Factory: f, Chip: chip, Offset: *d.Offset, Initial: d.Initial}), nil
+		}
+
+	case KindVH400:
+		addr := uint16(d.Addr)
+		if addr == 0 && d.ADC != drivers.ChipMCP3008 {
+			addr = DefaultVH400Addr
+		}
+		return vh400.NewVH400(vh400.VH400Config{
+			Name:        d.Name,
+			Factory:     backend.ADC,
+			Chip:        d.ADC,
+			Bus:         d.Bus,
+			Addr:        addr,
+			Channel:     d.Channel,
+			Interval:    d.Interval.D(),
+			EmitInitial: emitInitial,
+		}), nil
+
+	case KindBME280:
+		cfg := bme280.Config{
+			Name:        d.Name,
+			Bus:         d.Bus,
+			Addr:        uint16(d.Addr),
+			Interval:    d.Interval.D(),
+			EmitInitial: emitInitial,
+			DropOnFull:  true,
+		}
+		if backend.Mock {
+			cfg.InitHost = func() error { return nil }
+			cfg.OpenBus = func(string) (i2c.BusCloser, error) { return mockBus{}, nil }
+			cfg.NewDev = func(i2c.Bus, uint16) (bme280.Sensor, error) { return mockEnv{}, nil }
+		}
+		return bme280.New(cfg), nil
+
+	case KindOLED:
+		cfg := display.OLEDConfig{
+			Name:    d.Name,
+			Factory: backend.OLED,
+			Bus:     d.Bus,
+			Addr:    uint16(d.Addr),
+			Width:   d.Width,
+			Height:  d.Height,
+		}
+		if cfg.Addr == 0 {
+			cfg.Addr = DefaultOLEDAddr
+		}
+		if cfg.Width == 0 {
+			cfg.Width, cfg.Height = DefaultOLEDWidth, DefaultOLEDHeight
+		}
+		return display.NewOLED(cfg), nil
+
+	case KindGTU7:
+		return gps(d, backend), nil
+	}
+	return nil, fmt.Errorf("kind %q %w", d.Kind, ErrInvalid)
+}
+
+// gps builds a GTU7 on the device's port. On mock backends the port is a
+// loopback fed with simulated NMEA.
+func gps(d DeviceConfig, backend drivers.Backend) *gtu7.GTU7 {
+	port := d.Port
+	if port == "" {
+		port = d.Name
+	}
+	baud := d.Baud
+	if baud == 0 {
+		baud = DefaultBaud
+	}
+	f := backend.Serial
+	if backend.Mock {
+		f = nmeaFeeder{f: f, interval: d.Interval.D()}
+	}
+	return gtu7.NewGTU7(gtu7.GTU7Config{
+		Name:    d.Name,
+		Serial:  drivers.SerialConfig{Port: port, Baud: baud},
+		Factory: f,
+	})
+}
+
+// nmeaFeeder opens loopback ports and keeps writing mockGGA to them
+// until they are closed.
+type nmeaFeeder struct {
+	f        drivers.SerialFactory
+	interval time.Duration
+}
+
+func (n nmeaFeeder) OpenSerial(cfg drivers.SerialConfig) (drivers.SerialPort, error) {
+	p, err := n.f.OpenSerial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &fedPort{SerialPort: p, stop: feedNMEA(p, n.interval)}, nil
+}
+
+// fedPort stops its feeder on Close.
+type fedPort struct {
+	drivers.SerialPort
+	stop context.CancelFunc
+}
+
+func (p *fedPort) Close() error {
+	p.stop()
+	return p.SerialPort.Close()
+}
+
+// mockGGA is a fixed GGA sentence (Munich, 8 satellites).
+const mockGGA = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n"
+
+// feedNMEA writes mockGGA to w every interval (default 1s) until the
+// returned stop is called or a write fails.
+func feedNMEA(w io.Writer, interval time.Duration) (stop context.CancelFunc) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			if _, err := io.WriteString(w, mockGGA); err != nil {
+				return
+			}
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// mockBus and mockEnv stand in for the I2C bus and BME280 on the mock
+// backend: 20°C, 1013.25 hPa, 50 %RH.
+type mockBus struct{}
+
+func (mockBus) String() string                    { return "mock-i2c" }
+func (mockBus) Tx(addr uint16, w, r []byte) error { return nil }
+func (mockBus) SetSpeed(physic.Frequency) error   { return nil }
+func (mockBus) Close() error                      { return nil }
+
+type mockEnv struct{}
+
+func (mockEnv) Sense(e *physic.Env) error {
+	e.Temperature = physic.ZeroCelsius + 20*physic.Kelvin
+	e.Pressure = 101325 * physic.Pascal
+	e.Humidity = 50 * physic.PercentRH
+	return nil
+}
+
+func (mockEnv) Halt() error { return nil }
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/devices/bme280"
+	"github.com/rustyeddy/devices/devices/button"
+	"github.com/rustyeddy/devices/devices/gtu7"
+	"github.com/rustyeddy/devices/devices/led"
+	"github.com/rustyeddy/devices/devices/relay"
+	"github.com/rustyeddy/devices/devices/vh400"
+	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+func TestBuildMockStation(t *testing.T) {
+	t.Parallel()
+
+	st, err := Load("testdata/station.yaml")
+	require.NoError(t, err)
+
+	m, err := st.NewManager()
+	require.NoError(t, err)
+	assert.Equal(t, "garden", m.Name())
+
+	devs := m.Devices()
+	require.Len(t, devs, 7)
+	assert.IsType(t, &relay.Relay{}, devs[0])
+	assert.IsType(t, &button.Button{}, devs[1])
+	assert.IsType(t, &vh400.VH400{}, devs[2])
+	assert.IsType(t, &bme280.BME280{}, devs[3])
+	assert.IsType(t, &display.OLED{}, devs[5])
+	assert.IsType(t, &led.LED{}, devs[6])
+
+	descs := m.Descriptors()
+	assert.Equal(t, "gpiochip0", descs[0].Attributes["chip"], "default chip")
+	assert.Equal(t, "17", descs[0].Attributes["offset"])
+	assert.Equal(t, "falling", descs[1].Attributes["edge"])
+	assert.Equal(t, "2", descs[2].Attributes["channel"])
+	assert.Equal(t, "0x77", descs[3].Attributes["addr"])
+	assert.Equal(t, "gps", descs[4].Kind)
+	assert.Equal(t, "0x3d", descs[5].Attributes["addr"])
+	assert.Equal(t, "128", descs[5].Attributes["width"], "default size")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- m.Run(ctx) }()
+
+	soil := devs[2].(devices.Source[float64])
+	env := devs[3].(devices.Source[bme280.Env])
+	gps := devs[4].(devices.Source[gtu7.GPSFix])
+
+	recvWithin(t, soil.Out())
+	e := recvWithin(t, env.Out())
+	assert.InDelta(t, 20.0, e.Temperature, 0.01)
+	assert.InDelta(t, 101325.0, e.Pressure, 1)
+	fix := recvWithin(t, gps.Out())
+	assert.InDelta(t, 48.1173, fix.Lat, 0.0001)
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("station did not stop")
+	}
+}
+
+func TestBuildJSONStation(t *testing.T) {
+	t.Parallel()
+
+	st, err := Load("testdata/station.json")
+	require.NoError(t, err)
+	devs, err := st.Build()
+	require.NoError(t, err)
+	require.Len(t, devs, 2)
+	assert.Equal(t, "light", devs[0].Name())
+	assert.Equal(t, "env", devs[1].Name())
+}
+
+func TestBuildVH400Chip(t *testing.T) {
+	t.Parallel()
+
+	st := &Station{Backend: drivers.BackendMock, Devices: []DeviceConfig{
+		{Name: "soil", Kind: KindVH400, Interval: Duration(time.Second)},
+		{Name: "pot", Kind: KindVH400, ADC: drivers.ChipMCP3008, Bus: "SPI0.0", Channel: 7, Interval: Duration(time.Second)},
+	}}
+	devs, err := st.Build()
+	require.NoError(t, err)
+
+	soil := devs[0].(devices.Described).Descriptor()
+	assert.Equal(t, drivers.ChipADS1115, soil.Attributes["adc"])
+	assert.Equal(t, "0x48", soil.Attributes["addr"])
+
+	pot := devs[1].(devices.Described).Descriptor()
+	assert.Equal(t, drivers.ChipMCP3008, pot.Attributes["adc"])
+	assert.Equal(t, "SPI0.0", pot.Attributes["bus"])
+	assert.Equal(t, "7", pot.Attributes["channel"])
+}
+
+func TestBuildOpensSerialInRun(t *testing.T) {
+	t.Parallel()
+
+	st := &Station{Devices: []DeviceConfig{{Name: "gps", Kind: KindGTU7, Backend: drivers.BackendSerial, Port: "/nonexistent/tty"}}}
+	devs, err := st.Build()
+	require.NoError(t, err)
+
+	err = devs[0].Run(context.Background())
+	require.ErrorContains(t, err, "/nonexistent/tty")
+	var last devices.Event
+	for ev := range devs[0].Events() {
+		last = ev
+	}
+	assert.Equal(t, "open serial failed", last.Msg)
+}
+
+func TestBuildErrorPointsAtEntry(t *testing.T) {
+	t.Parallel()
+
+	st := &Station{Devices: []DeviceConfig{
+		{Name: "gps0", Kind: KindGTU7, Backend: drivers.BackendMock},
+		{Name: "gps", Kind: KindGTU7, Backend: "nonesuch"},
+	}}
+	_, err := st.Build()
+	var ce *Error
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, 1, ce.Index)
+	assert.Equal(t, "gps", ce.Name)
+}
+
+func recvWithin[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a value")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,267 @@
+// Package config loads a station description from YAML or JSON and
+// builds its devices.
+//
+// Example station.yaml:
+//
+//	name: garden
+//	backend: auto            # default backend; see DeviceConfig.Backend
+//	devices:
+//	  - name: pump
+//	    kind: relay
+//	    chip: gpiochip0
+//	    offset: 17
+//	  - name: door
+//	    kind: button
+//	    offset: 27
+//	    bias: pullup
+//	    debounce: 20ms
+//	  - name: soil
+//	    kind: vh400
+//	    bus: "1"
+//	    addr: 0x48
+//	    channel: 0
+//	    interval: 10s
+//	  - name: env
+//	    kind: bme280
+//	    bus: "1"
+//	    addr: 0x76
+//	    interval: 30s
+//	  - name: gps
+//	    kind: gtu7
+//	    port: /dev/ttyUSB0
+//	    baud: 9600
+//	  - name: screen
+//	    kind: oled
+//	    bus: "1"
+//	    addr: 0x3c
+//	    width: 128
+//	    height: 64
+//
+// Load it and run the station:
+//
+//	st, err := config.Load("station.yaml")
+//	if err != nil { ... }
+//	m, err := st.NewManager()
+//	if err != nil { ... }
+//	err = m.Run(ctx)
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of a config file.
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+)
+
+// Station is a set of devices run together.
+type Station struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Backend is the default backend for devices that do not set one
+	// and whose kind supports it. Empty or "auto" leaves the choice to
+	// drivers.Resolve ($DEVICES_BACKEND, else hardware on a Pi and mock
+	// elsewhere).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	Devices []DeviceConfig `yaml:"devices" json:"devices"`
+
+	// lines holds the YAML line of each device entry (0 for JSON).
+	lines []int
+}
+
+// DeviceConfig describes one device. Which fields apply depends on Kind:
+//
+//	button, relay, led   chip, offset (+ bias, edge, debounce for button; initial for outputs)
+//	vh400                adc, bus, addr, channel, interval
+//	bme280               bus, addr, interval
+//	oled                 bus, addr, width, height
+//	gtu7                 port, baud
+type DeviceConfig struct {
+	Name string `yaml:"name" json:"name"`
+	Kind string `yaml:"kind" json:"kind"`
+
+	// Backend names a drivers backend: "gpiocdev", "vpio" or "mock" for
+	// GPIO devices, "periph" or "mock" for I2C devices, "serial" or "mock"
+	// for gtu7, or any backend registered with drivers.Register. Empty
+	// uses the station backend, else the drivers default.
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// GPIO
+	Chip     string   `yaml:"chip,omitempty" json:"chip,omitempty"`
+	Offset   *int     `yaml:"offset,omitempty" json:"offset,omitempty"`
+	Initial  bool     `yaml:"initial,omitempty" json:"initial,omitempty"`
+	Bias     string   `yaml:"bias,omitempty" json:"bias,omitempty"`
+	Edge     string   `yaml:"edge,omitempty" json:"edge,omitempty"`
+	Debounce Duration `yaml:"debounce,omitempty" json:"debounce,omitempty"`
+
+	// I2C
+	Bus     string `yaml:"bus,omitempty" json:"bus,omitempty"`
+	Addr    Addr   `yaml:"addr,omitempty" json:"addr,omitempty"`
+	Channel int    `yaml:"channel,omitempty" json:"channel,omitempty"`
+	Width   int    `yaml:"width,omitempty" json:"width,omitempty"`
+	Height  int    `yaml:"height,omitempty" json:"height,omitempty"`
+
+	// ADC is the converter a vh400 reads through: "ads1115" (default)
+	// or "ads1015" on bus at addr, or "mcp3008" on the SPI port named
+	// by bus.
+	ADC string `yaml:"adc,omitempty" json:"adc,omitempty"`
+
+	// Serial
+	Port string `yaml:"port,omitempty" json:"port,omitempty"`
+	Baud int    `yaml:"baud,omitempty" json:"baud,omitempty"`
+
+	// Polling
+	Interval    Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	EmitInitial *bool    `yaml:"emit_initial,omitempty" json:"emit_initial,omitempty"`
+}
+
+// Load reads and validates a station file. The format is chosen by
+// extension: .yaml/.yml or .json.
+func Load(path string) (*Station, error) {
+	var format Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = YAML
+	case ".json":
+		format = JSON
+	default:
+		return nil, fmt.Errorf("config: %s: unknown extension (want .yaml, .yml or .json)", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	st, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// Parse decodes and validates a station. Unknown fields are rejected.
+func Parse(data []byte, format Format) (*Station, error) {
+	var st Station
+	switch format {
+	case YAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&st); err != nil {
+			return nil, err
+		}
+		st.lines = yamlLines(data)
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&st); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// yamlLines returns the source line of every devices entry.
+func yamlLines(data []byte) []int {
+	var doc struct {
+		Devices []yaml.Node `yaml:"devices"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	lines := make([]int, len(doc.Devices))
+	for i, n := range doc.Devices {
+		lines[i] = n.Line
+	}
+	return lines
+}
+
+// Duration is a time.Duration written as a string such as "500ms" or "10s".
+type Duration time.Duration
+
+// D returns d as a time.Duration.
+func (d Duration) D() time.Duration { return time.Duration(d) }
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (want e.g. \"500ms\", \"10s\")", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	return d.parse(n.Value)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (any, error) { return d.String(), nil }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid duration %s (want a string such as \"10s\")", b)
+	}
+	return d.parse(s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(d.String()) }
+
+// Addr is an I2C address, written as a number (0x76, 118) or a string ("0x76").
+type Addr uint16
+
+func (a *Addr) parse(s string) error {
+	v, err := strconv.ParseUint(strings.TrimSpace(s), 0, 16)
+	if err != nil || v > 0x7f {
+		return fmt.Errorf("invalid i2c address %q", s)
+	}
+	*a = Addr(v)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (a *Addr) UnmarshalYAML(n *yaml.Node) error {
+	return a.parse(n.Value)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (a Addr) MarshalYAML() (any, error) { return a.String(), nil }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Addr) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return a.parse(s)
+	}
+	return a.parse(string(b))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a Addr) MarshalJSON() ([]byte, error) { return json.Marshal(a.String()) }
+
+func (a Addr) String() string { return fmt.Sprintf("0x%02x", uint16(a)) }
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+func TestLoadYAML(t *testing.T) {
+	t.Parallel()
+
+	st, err := Load("testdata/station.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "garden", st.Name)
+	assert.Equal(t, drivers.BackendMock, st.Backend)
+	require.Len(t, st.Devices, 7)
+
+	door := st.Devices[1]
+	assert.Equal(t, "button", door.Kind)
+	require.NotNil(t, door.Offset)
+	assert.Equal(t, 27, *door.Offset)
+	assert.Equal(t, 20*time.Millisecond, door.Debounce.D())
+
+	soil := st.Devices[2]
+	assert.Equal(t, Addr(0x48), soil.Addr)
+	assert.Equal(t, 10*time.Second, soil.Interval.D())
+
+	assert.Equal(t, []int{4, 7, 14, 20, 24, 27, 30}, st.lines)
+}
+
+func TestLoadJSON(t *testing.T) {
+	t.Parallel()
+
+	st, err := Load("testdata/station.json")
+	require.NoError(t, err)
+	assert.Equal(t, "porch", st.Name)
+	require.Len(t, st.Devices, 2)
+	assert.Equal(t, Addr(0x76), st.Devices[1].Addr)
+	assert.Equal(t, time.Second, st.Devices[1].Interval.D())
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load("station.toml")
+	assert.ErrorContains(t, err, "unknown extension")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Parse([]byte("devices:\n  - name: a\n    kind: relay\n    ofset: 1\n"), YAML)
+	assert.ErrorContains(t, err, "field ofset not found")
+
+	_, err = Parse([]byte(`{"devices":[{"name":"a","kind":"relay","ofset":1}]}`), JSON)
+	assert.ErrorContains(t, err, `unknown field "ofset"`)
+
+	_, err = Parse([]byte("devices:\n  - {name: a, kind: vh400, interval: 10}\n"), YAML)
+	assert.ErrorContains(t, err, "invalid duration")
+
+	_, err = Parse([]byte("devices:\n  - {name: a, kind: bme280, addr: 0x200}\n"), YAML)
+	assert.ErrorContains(t, err, "invalid i2c address")
+}
+
+func TestAddrAndDurationJSON(t *testing.T) {
+	t.Parallel()
+
+	var a Addr
+	require.NoError(t, a.UnmarshalJSON([]byte(`118`)))
+	assert.Equal(t, Addr(0x76), a)
+	require.NoError(t, a.UnmarshalJSON([]byte(`"0x3c"`)))
+	assert.Equal(t, Addr(0x3c), a)
+
+	b, err := a.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `"0x3c"`, string(b))
+
+	d := Duration(1500 * time.Millisecond)
+	b, err = d.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `"1.5s"`, string(b))
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rustyeddy/devices"
+)
+
+// SeedInterval is the poll interval given to seeded sensors.
+const SeedInterval = 30 * time.Second
+
+// Seed starts a station from discovered devices, such as the result of
+// i2cscan.Scan. Descriptors of a kind the station can build (bme280,
+// oled) become device entries named after the descriptor; the rest are
+// returned as skipped, to be added by hand.
+//
+// The station is not validated; write it out, edit it, then Load it.
+func Seed(name string, descs []devices.Descriptor) (st *Station, skipped []devices.Descriptor) {
+	st = &Station{Name: name}
+	for _, desc := range descs {
+		d := DeviceConfig{
+			Name: desc.Name,
+			Kind: desc.Kind,
+			Bus:  desc.Attributes["bus"],
+		}
+		var addr Addr
+		if err := addr.parse(desc.Attributes["addr"]); err != nil {
+			skipped = append(skipped, desc)
+			continue
+		}
+		d.Addr = addr
+
+		switch desc.Kind {
+		case KindBME280:
+			d.Interval = Duration(SeedInterval)
+		case KindOLED:
+			d.Width, _ = strconv.Atoi(desc.Attributes["width"])
+			d.Height, _ = strconv.Atoi(desc.Attributes["height"])
+		default:
+			skipped = append(skipped, desc)
+			continue
+		}
+		st.Devices = append(st.Devices, d)
+	}
+	return st, skipped
+}
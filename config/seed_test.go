@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+func TestSeed(t *testing.T) {
+	t.Parallel()
+
+	descs := []devices.Descriptor{
+		{Name: "bme280_77", Kind: "bme280", Attributes: map[string]string{"bus": "1", "addr": "0x77"}},
+		{Name: "ssd1306_3c", Kind: "oled", Attributes: map[string]string{"bus": "1", "addr": "0x3c"}},
+		{Name: "ads1115_48", Kind: "ads1115", Attributes: map[string]string{"bus": "1", "addr": "0x48"}},
+		{Name: "odd", Kind: "bme280", Attributes: map[string]string{"addr": "nope"}},
+	}
+	st, skipped := Seed("bench", descs)
+	require.Len(t, st.Devices, 2)
+	assert.Equal(t, []string{"ads1115_48", "odd"}, []string{skipped[0].Name, skipped[1].Name})
+
+	env := st.Devices[0]
+	assert.Equal(t, KindBME280, env.Kind)
+	assert.Equal(t, Addr(0x77), env.Addr)
+	assert.Equal(t, SeedInterval, env.Interval.D())
+
+	// The seed round-trips through YAML into a buildable station.
+	data, err := yaml.Marshal(st)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "addr: \"0x77\"")
+
+	loaded, err := Parse(data, YAML)
+	require.NoError(t, err)
+	loaded.Backend = drivers.BackendMock
+	devs, err := loaded.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "bme280_77", devs[0].Name())
+	assert.Equal(t, "ssd1306_3c", devs[1].Name())
+}
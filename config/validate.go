@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// Device kinds.
+const (
+	KindButton = "button"
+	KindRelay  = "relay"
+	KindLED    = "led"
+	KindVH400  = "vh400"
+	KindBME280 = "bme280"
+	KindGTU7   = "gtu7"
+	KindOLED   = "oled"
+)
+
+// classes maps each kind to the driver class its backend must provide.
+// The BME280 follows the I2C selection; on mock backends it reads a
+// simulated sensor.
+var classes = map[string]drivers.Class{
+	KindButton: drivers.ClassGPIO,
+	KindRelay:  drivers.ClassGPIO,
+	KindLED:    drivers.ClassGPIO,
+	KindVH400:  drivers.ClassADC,
+	KindBME280: drivers.ClassI2C,
+	KindOLED:   drivers.ClassOLED,
+	KindGTU7:   drivers.ClassSerial,
+}
+
+var (
+	ErrNoDevices = errors.New("no devices")
+	ErrRequired  = errors.New("is required")
+	ErrInvalid   = errors.New("is invalid")
+)
+
+// Error reports a problem with one device entry.
+type Error struct {
+	Index int    // position in Station.Devices
+	Name  string // device name, if set
+	Line  int    // YAML source line, 0 if unknown
+	Err   error
+}
+
+func (e *Error) Error() string {
+	s := "devices[" + strconv.Itoa(e.Index) + "]"
+	if e.Name != "" {
+		s += " " + strconv.Quote(e.Name)
+	}
+	if e.Line > 0 {
+		s += " (line " + strconv.Itoa(e.Line) + ")"
+	}
+	return s + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Validate checks every device entry and returns all problems found,
+// joined; each is an *Error naming the entry.
+func (s *Station) Validate() error {
+	if len(s.Devices) == 0 {
+		return ErrNoDevices
+	}
+	if s.Backend != "" && s.Backend != drivers.BackendAuto {
+		if _, err := drivers.Lookup(s.Backend); err != nil {
+			return fmt.Errorf("backend %q %w: %w", s.Backend, ErrInvalid, err)
+		}
+	}
+
+	var errs []error
+	seen := map[string]int{}
+	for i, d := range s.Devices {
+		fail := func(err error) {
+			errs = append(errs, &Error{Index: i, Name: d.Name, Line: s.line(i), Err: err})
+		}
+
+		if d.Name == "" {
+			fail(fmt.Errorf("name %w", ErrRequired))
+		} else if j, dup := seen[d.Name]; dup {
+			fail(fmt.Errorf("name already used by devices[%d]", j))
+		} else {
+			seen[d.Name] = i
+		}
+
+		for _, err := range s.validate(d) {
+			fail(err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Station) line(i int) int {
+	if i < len(s.lines) {
+		return s.lines[i]
+	}
+	return 0
+}
+
+// backendName returns the backend d asks for within station s: its own,
+// else the station's if that supports d's kind, else "" for the drivers
+// default (see drivers.Resolve).
+func (s *Station) backendName(d DeviceConfig) string {
+	if d.Backend != "" {
+		return d.Backend
+	}
+	if s.Backend != "" && s.Backend != drivers.BackendAuto {
+		if b, err := drivers.Lookup(s.Backend); err == nil && b.Supports(classes[d.Kind]) {
+			return s.Backend
+		}
+	}
+	return ""
+}
+
+// backend resolves the driver backend d uses within station s.
+func (s *Station) backend(d DeviceConfig) (drivers.Backend, error) {
+	return drivers.Resolve(s.backendName(d), classes[d.Kind])
+}
+
+// validate checks d's kind, backend and the fields that apply to its kind.
+func (s *Station) validate(d DeviceConfig) []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	class, ok := classes[d.Kind]
+	if !ok {
+		if d.Kind == "" {
+			add("kind %w", ErrRequired)
+		} else {
+			add("kind %q %w (want button, relay, led, vh400, bme280, gtu7 or oled)", d.Kind, ErrInvalid)
+		}
+		return errs
+	}
+	backend, err := s.backend(d)
+	if err != nil {
+		if d.Backend != "" {
+			add("backend %q %w for %s (want one of %v)", d.Backend, ErrInvalid, d.Kind, drivers.BackendsFor(class))
+		} else {
+			add("backend %w", err)
+		}
+	}
+
+	switch d.Kind {
+	case KindButton, KindRelay, KindLED:
+		if d.Offset == nil {
+			add("offset %w", ErrRequired)
+		} else if *d.Offset < 0 {
+			add("offset %d %w", *d.Offset, ErrInvalid)
+		}
+		switch d.Bias {
+		case "", "default", "pullup", "pulldown":
+		default:
+			add("bias %q %w (want default, pullup or pulldown)", d.Bias, ErrInvalid)
+		}
+		switch d.Edge {
+		case "", "none", "rising", "falling", "both":
+		default:
+			add("edge %q %w (want none, rising, falling or both)", d.Edge, ErrInvalid)
+		}
+		if d.Debounce < 0 {
+			add("debounce %s %w", d.Debounce, ErrInvalid)
+		}
+
+	case KindVH400:
+		if n, err := drivers.ADCChannels(d.ADC); err != nil {
+			add("adc %q %w (want one of %v)", d.ADC, ErrInvalid, drivers.ADCChips())
+		} else if d.Channel < 0 || d.Channel >= n {
+			add("channel %d %w (want 0-%d)", d.Channel, ErrInvalid, n-1)
+		}
+		if d.Interval <= 0 {
+			add("interval %w", ErrRequired)
+		}
+
+	case KindBME280:
+		if d.Addr != 0 && d.Addr != 0x76 && d.Addr != 0x77 {
+			add("addr %s %w (want 0x76 or 0x77)", d.Addr, ErrInvalid)
+		}
+		if d.Interval <= 0 {
+			add("interval %w", ErrRequired)
+		}
+
+	case KindOLED:
+		if d.Width < 0 || d.Height < 0 || (d.Width == 0) != (d.Height == 0) {
+			add("width and height %w (%dx%d)", ErrInvalid, d.Width, d.Height)
+		}
+
+	case KindGTU7:
+		if d.Port == "" && !backend.Mock {
+			add("port %w", ErrRequired)
+		}
+		if d.Baud < 0 {
+			add("baud %d %w", d.Baud, ErrInvalid)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+func TestValidatePointsAtEntry(t *testing.T) {
+	t.Parallel()
+
+	src := `name: bad
+devices:
+  - name: pump
+    kind: relay
+    offset: 17
+  - name: soil
+    kind: vh400
+    channel: 5
+    interval: 10s
+  - name: pump
+    kind: relay
+    offset: 3
+  - kind: thermostat
+  - name: door
+    kind: button
+    backend: periph
+`
+	_, err := Parse([]byte(src), YAML)
+	require.Error(t, err)
+
+	var entries []*Error
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ce *Error
+		require.True(t, errors.As(e, &ce), e)
+		entries = append(entries, ce)
+	}
+
+	msgs := make([]string, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.Error()
+	}
+	assert.Equal(t, []string{
+		`devices[1] "soil" (line 6): channel 5 is invalid (want 0-3)`,
+		`devices[2] "pump" (line 10): name already used by devices[0]`,
+		`devices[3] (line 13): name is required`,
+		`devices[3] (line 13): kind "thermostat" is invalid (want button, relay, led, vh400, bme280, gtu7 or oled)`,
+		`devices[4] "door" (line 14): backend "periph" is invalid for button (want one of [gpiocdev mock vpio])`,
+		`devices[4] "door" (line 14): offset is required`,
+	}, msgs)
+
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorIs(t, err, ErrRequired)
+}
+
+func TestValidateKinds(t *testing.T) {
+	t.Parallel()
+
+	off := 1
+	cases := []struct {
+		dev  DeviceConfig
+		want string
+	}{
+		{DeviceConfig{Name: "a", Kind: "button", Offset: &off, Bias: "up"}, `bias "up" is invalid`},
+		{DeviceConfig{Name: "a", Kind: "button", Offset: &off, Edge: "up"}, `edge "up" is invalid`},
+		{DeviceConfig{Name: "a", Kind: "vh400"}, "interval is required"},
+		{DeviceConfig{Name: "a", Kind: "vh400", Channel: 4, Interval: 1}, "channel 4 is invalid (want 0-3)"},
+		{DeviceConfig{Name: "a", Kind: "vh400", ADC: "mcp3008", Channel: 8, Interval: 1}, "channel 8 is invalid (want 0-7)"},
+		{DeviceConfig{Name: "a", Kind: "vh400", ADC: "lm35", Interval: 1}, `adc "lm35" is invalid`},
+		{DeviceConfig{Name: "a", Kind: "bme280", Addr: 0x40, Interval: 1}, "addr 0x40 is invalid"},
+		{DeviceConfig{Name: "a", Kind: "oled", Width: 128}, "width and height is invalid"},
+		{DeviceConfig{Name: "a", Kind: "gtu7", Backend: "serial"}, "port is required"},
+	}
+	for _, c := range cases {
+		st := Station{Devices: []DeviceConfig{c.dev}}
+		assert.ErrorContains(t, st.Validate(), c.want)
+	}
+
+	st := Station{Devices: []DeviceConfig{{Name: "a", Kind: "gtu7", Backend: "mock"}}}
+	assert.NoError(t, st.Validate(), "mock gps needs no port")
+
+	assert.ErrorIs(t, (&Station{}).Validate(), ErrNoDevices)
+	assert.ErrorIs(t, (&Station{Backend: "nope", Devices: st.Devices}).Validate(), ErrInvalid)
+}
+
+func TestBackendResolution(t *testing.T) {
+	t.Parallel()
+
+	st := Station{Backend: drivers.BackendVPIO}
+	assert.Equal(t, drivers.BackendVPIO, st.backendName(DeviceConfig{Kind: KindRelay}))
+	assert.Equal(t, "", st.backendName(DeviceConfig{Kind: KindVH400}), "vpio does not apply to i2c")
+	assert.Equal(t, drivers.BackendMock, st.backendName(DeviceConfig{Kind: KindVH400, Backend: drivers.BackendMock}))
+	assert.Equal(t, "", (&Station{}).backendName(DeviceConfig{Kind: KindButton}))
+
+	b, err := st.backend(DeviceConfig{Kind: KindGTU7, Backend: drivers.BackendSerial})
+	require.NoError(t, err)
+	assert.Equal(t, drivers.BackendSerial, b.Name)
+	assert.NotNil(t, b.Serial)
+
+	_, err = st.backend(DeviceConfig{Kind: KindOLED, Backend: drivers.BackendVPIO})
+	assert.ErrorIs(t, err, drivers.ErrUnsupported)
+}
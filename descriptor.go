@@ -19,13 +19,13 @@ type Described interface {
 
 // Descriptor describes static device metadata.
 type Descriptor struct {
-	Name       string
-	Kind       string // "relay", "button", "temperature", "gps"
-	ValueType  string // "bool", "float64", "struct"
-	Access     AccessMode
-	Unit       string // "C", "%", "rpm", etc.
-	Min        *float64
-	Max        *float64
-	Tags       []string
-	Attributes map[string]string // gpio=17, i2c=0x76, etc.
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind,omitempty"`       // "relay", "button", "temperature", "gps"
+	ValueType  string            `json:"value_type,omitempty"` // "bool", "float64", "struct"
+	Access     AccessMode        `json:"access,omitempty"`
+	Unit       string            `json:"unit,omitempty"` // "C", "%", "rpm", etc.
+	Min        *float64          `json:"min,omitempty"`
+	Max        *float64          `json:"max,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"` // gpio=17, i2c=0x76, etc.
 }
@@ -1,6 +1,7 @@
 package devices
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -339,3 +340,11 @@ func TestDescriptorPointerFieldsIndependence(t *testing.T) {
 	assert.Equal(t, 20.0, *d.Min)
 	assert.Equal(t, 200.0, *d.Max)
 }
+
+func TestDescriptorJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(Descriptor{Name: "pump", Kind: "relay", Access: ReadWrite})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"pump","kind":"relay","access":"rw"}`, string(b))
+}
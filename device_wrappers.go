@@ -1,402 +1,336 @@
 package devices
 
-// //
-// // RateLimit
-// //
-
-// // RateLimit emits at most one value per period.
-// // Implementation: keeps the most recent value seen and emits it on each tick.
-// func RateLimit[T any](src Source[T], period time.Duration) Source[T] {
-// 	return &rateLimit[T]{
-// 		src:    src,
-// 		period: period,
-// 		out:    make(chan T, 1),
-// 	}
-// }
-
-// type rateLimit[T any] struct {
-// 	src     Source[T]
-// 	period  time.Duration
-// 	out     chan T
-// 	closeMu sync.Once
-// 	cancel  context.CancelFunc
-// }
-
-// func (r *rateLimit[T]) Read() <-chan T { return r.out }
-
-// func (r *rateLimit[T]) Run(ctx context.Context) error {
-// 	ctx, r.cancel = context.WithCancel(ctx)
-
-// 	// Start source first.
-// 	if err := r.src.Run(ctx); err != nil {
-// 		return err
-// 	}
-
-// 	in := r.src.Read()
-
-// 	go func() {
-// 		defer close(r.out)
-
-// 		t := time.NewTicker(r.period)
-// 		defer t.Stop()
-
-// 		var (
-// 			last T
-// 			have bool
-// 		)
-
-// 		for {
-// 			select {
-// 			case <-ctx.Done():
-// 				return
-// 			case v, ok := <-in:
-// 				if !ok {
-// 					return
-// 				}
-// 				last = v
-// 				have = true
-// 			case <-t.C:
-// 				if have {
-// 					// Non-blocking-ish: if downstream is slow, keep latest in buffer.
-// 					select {
-// 					case r.out <- last:
-// 					default:
-// 						// Drop tick if consumer is behind; next tick will send latest.
-// 					}
-// 				}
-// 			}
-// 		}
-// 	}()
-
-// 	return nil
-// }
-
-// func (r *rateLimit[T]) Close() error {
-// 	r.closeMu.Do(func() {
-// 		if r.cancel != nil {
-// 			r.cancel()
-// 		}
-// 	})
-// 	return r.src.Close()
-// }
-
-// //
-// // Debounce
-// //
-
-// // DebounceComparable emits a value only after it has remained unchanged for d.
-// // Requires T comparable.
-// func DebounceComparable[T comparable](src Source[T], d time.Duration) Source[T] {
-// 	return DebounceFunc(src, d, func(a, b T) bool { return a == b })
-// }
-
-// // DebounceFunc is Debounce with a custom equality comparator.
-// func DebounceFunc[T any](src Source[T], d time.Duration, equal func(a, b T) bool) Source[T] {
-// 	return &debounce[T]{
-// 		src:   src,
-// 		d:     d,
-// 		equal: equal,
-// 		out:   make(chan T, 1),
-// 	}
-// }
-
-// type debounce[T any] struct {
-// 	src   Source[T]
-// 	d     time.Duration
-// 	equal func(a, b T) bool
-// 	out   chan T
-
-// 	closeMu sync.Once
-// 	cancel  context.CancelFunc
-// }
-
-// func (d *debounce[T]) Read() <-chan T { return d.out }
-
-// func (d *debounce[T]) Run(ctx context.Context) error {
-// 	ctx, d.cancel = context.WithCancel(ctx)
-
-// 	if err := d.src.Run(ctx); err != nil {
-// 		return err
-// 	}
-
-// 	in := d.src.Read()
-
-// 	go func() {
-// 		defer close(d.out)
-
-// 		var (
-// 			pending     T
-// 			havePending bool
-// 			timer       *time.Timer
-// 			timerC      <-chan time.Time
-// 		)
-
-// 		stopTimer := func() {
-// 			if timer != nil {
-// 				if !timer.Stop() {
-// 					select {
-// 					case <-timer.C:
-// 					default:
-// 					}
-// 				}
-// 				timer = nil
-// 				timerC = nil
-// 			}
-// 		}
-
-// 		resetTimer := func() {
-// 			stopTimer()
-// 			timer = time.NewTimer(d.d)
-// 			timerC = timer.C
-// 		}
-
-// 		for {
-// 			select {
-// 			case <-ctx.Done():
-// 				stopTimer()
-// 				return
-
-// 			case v, ok := <-in:
-// 				if !ok {
-// 					stopTimer()
-// 					return
-// 				}
-
-// 				if !havePending || !d.equal(v, pending) {
-// 					pending = v
-// 					havePending = true
-// 					resetTimer()
-// 				} else {
-// 					// Same value; push debounce window out.
-// 					resetTimer()
-// 				}
-
-// 			case <-timerC:
-// 				// Window elapsed; emit pending
-// 				if havePending {
-// 					select {
-// 					case d.out <- pending:
-// 					case <-ctx.Done():
-// 						return
-// 					}
-// 				}
-// 				// After emitting, wait for next change.
-// 				havePending = false
-// 				stopTimer()
-// 			}
-// 		}
-// 	}()
-
-// 	return nil
-// }
-
-// func (d *debounce[T]) Close() error {
-// 	d.closeMu.Do(func() {
-// 		if d.cancel != nil {
-// 			d.cancel()
-// 		}
-// 	})
-// 	return d.src.Close()
-// }
-
-// //
-// // LastValue
-// //
-
-// // LastValued is a Source with an additional Last() getter.
-// type LastValued[T any] interface {
-// 	Source[T]
-// 	Last() (T, bool)
-// }
-
-// // LastValue wraps a sensor and remembers the last value observed.
-// func LastValue[T any](src Source[T]) LastValued[T] {
-// 	return &lastValue[T]{
-// 		src: src,
-// 		out: make(chan T, 1),
-// 	}
-// }
-
-// type lastValue[T any] struct {
-// 	src Source[T]
-// 	out chan T
-
-// 	// store last value + have flag
-// 	mu   sync.RWMutex
-// 	last T
-// 	have bool
-
-// 	closeMu sync.Once
-// 	cancel  context.CancelFunc
-// }
-
-// func (l *lastValue[T]) Read() <-chan T { return l.out }
-
-// func (l *lastValue[T]) Last() (T, bool) {
-// 	l.mu.RLock()
-// 	defer l.mu.RUnlock()
-// 	return l.last, l.have
-// }
-
-// func (l *lastValue[T]) Run(ctx context.Context) error {
-// 	ctx, l.cancel = context.WithCancel(ctx)
-
-// 	if err := l.src.Run(ctx); err != nil {
-// 		return err
-// 	}
-
-// 	in := l.src.In()
-
-// 	go func() {
-// 		defer close(l.out)
-// 		for {
-// 			select {
-// 			case <-ctx.Done():
-// 				return
-// 			case v, ok := <-in:
-// 				if !ok {
-// 					return
-// 				}
-// 				l.mu.Lock()
-// 				l.last = v
-// 				l.have = true
-// 				l.mu.Unlock()
-
-// 				select {
-// 				case l.out <- v:
-// 				case <-ctx.Done():
-// 					return
-// 				}
-// 			}
-// 		}
-// 	}()
-
-// 	return nil
-// }
-
-// func (l *lastValue[T]) Close() error {
-// 	l.closeMu.Do(func() {
-// 		if l.cancel != nil {
-// 			l.cancel()
-// 		}
-// 	})
-// 	return l.src.Close()
-// }
-
-// //
-// // FanOut
-// //
-
-// // FanOutHub lets multiple consumers subscribe to the same sensor stream.
-// type FanOutHub[T any] interface {
-// 	Run(ctx context.Context) error
-// 	Close() error
-// 	Subscribe() <-chan T
-// 	Subscribers() int
-// }
-
-// // FanOut creates a hub that broadcasts each reading to all subscribers.
-// // n = initial subscribers created; buf = per-subscriber buffer.
-// func FanOut[T any](src Source[T], n int, buf int) FanOutHub[T] {
-// 	h := &fanOut[T]{
-// 		src: src,
-// 		buf: buf,
-// 	}
-// 	for i := 0; i < n; i++ {
-// 		h.Subscribe()
-// 	}
-// 	return h
-// }
-
-// type fanOut[T any] struct {
-// 	src Source[T]
-// 	buf int
-
-// 	mu     sync.RWMutex
-// 	subs   []chan T
-// 	closed atomic.Bool
-
-// 	closeMu sync.Once
-// 	cancel  context.CancelFunc
-// }
-
-// func (f *fanOut[T]) Subscribers() int {
-// 	f.mu.RLock()
-// 	defer f.mu.RUnlock()
-// 	return len(f.subs)
-// }
-
-// func (f *fanOut[T]) Subscribe() <-chan T {
-// 	f.mu.Lock()
-// 	defer f.mu.Unlock()
-
-// 	if f.closed.Load() {
-// 		ch := make(chan T)
-// 		close(ch)
-// 		return ch
-// 	}
-
-// 	ch := make(chan T, f.buf)
-// 	f.subs = append(f.subs, ch)
-// 	return ch
-// }
-
-// func (f *fanOut[T]) Run(ctx context.Context) error {
-// 	ctx, f.cancel = context.WithCancel(ctx)
-
-// 	if err := f.src.Run(ctx); err != nil {
-// 		return err
-// 	}
-
-// 	in := f.src.In()
-
-// 	go func() {
-// 		defer f.closeAll()
-
-// 		for {
-// 			select {
-// 			case <-ctx.Done():
-// 				return
-// 			case v, ok := <-in:
-// 				if !ok {
-// 					return
-// 				}
-
-// 				// Snapshot subscribers under RLock; avoid holding lock during sends.
-// 				f.mu.RLock()
-// 				snapshot := append([]chan T(nil), f.subs...)
-// 				f.mu.RUnlock()
-
-// 				for _, ch := range snapshot {
-// 					// Don’t let one slow subscriber block everyone.
-// 					select {
-// 					case ch <- v:
-// 					default:
-// 						// drop for this subscriber
-// 					}
-// 				}
-// 			}
-// 		}
-// 	}()
-
-// 	return nil
-// }
-
-// func (f *fanOut[T]) Close() error {
-// 	f.closeMu.Do(func() {
-// 		if f.cancel != nil {
-// 			f.cancel()
-// 		}
-// 		f.closed.Store(true)
-// 	})
-// 	return f.src.Close()
-// }
-
-// func (f *fanOut[T]) closeAll() {
-// 	f.mu.Lock()
-// 	defer f.mu.Unlock()
-// 	for _, ch := range f.subs {
-// 		close(ch)
-// 	}
-// 	f.subs = nil
-// }
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Wrappers are Sources built on top of another Source.
+//
+// A wrapper owns its goroutines and channels like any Device:
+//   - Run(ctx) runs the wrapped source and blocks until it stops
+//   - events from the wrapped source are passed through unchanged
+//   - the wrapper closes its Out() and Events() before Run returns
+//   - Close closes the wrapped source as well
+//
+// Wrappers take the wrapped source's name (and Descriptor, if any), so they
+// can be registered with a Manager in place of the source.
+
+// wrapper holds the plumbing shared by all wrappers.
+type wrapper[T, U any] struct {
+	Base
+	src Source[T]
+	out chan U
+}
+
+func newWrapper[T, U any](src Source[T], buf int) wrapper[T, U] {
+	if buf <= 0 {
+		buf = 16
+	}
+	return wrapper[T, U]{
+		Base: NewBase(src.Name(), 16),
+		src:  src,
+		out:  make(chan U, buf),
+	}
+}
+
+// Out returns the wrapped value stream.
+func (w *wrapper[T, U]) Out() <-chan U { return w.out }
+
+// Descriptor returns the wrapped source's Descriptor, if it has one.
+func (w *wrapper[T, U]) Descriptor() Descriptor {
+	if d, ok := w.src.(Described); ok {
+		return d.Descriptor()
+	}
+	return Descriptor{Name: w.Name()}
+}
+
+// Close closes the wrapped source and the wrapper's events.
+func (w *wrapper[T, U]) Close() error {
+	err := w.src.Close()
+	w.Base.Close()
+	return err
+}
+
+// send publishes without blocking; values are dropped if Out is full.
+func (w *wrapper[T, U]) send(v U) {
+	select {
+	case w.out <- v:
+	default:
+	}
+}
+
+// run runs the wrapped source, forwarding its events, and calls loop with
+// its output. loop must return once in is closed. run closes Out and the
+// wrapper's events before returning the source's Run error.
+func (w *wrapper[T, U]) run(ctx context.Context, loop func(in <-chan T)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- w.src.Run(ctx)
+		close(done)
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forward(w.src.Events(), done, w.publish)
+	}()
+
+	in := make(chan T)
+	go func() {
+		defer wg.Done()
+		defer close(in)
+		forward(w.src.Out(), done, func(v T) {
+			select {
+			case in <- v:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	loop(in)
+
+	cancel()
+	err := <-errCh
+	wg.Wait()
+
+	close(w.out)
+	w.Base.Close()
+	return err
+}
+
+//
+// RateLimit
+//
+
+// RateLimit emits at most one value per period.
+//
+// The first value is passed through immediately; values arriving within
+// the following period are coalesced and only the latest is emitted when
+// the period ends.
+func RateLimit[T any](src Source[T], period time.Duration) Source[T] {
+	return &rateLimit[T]{
+		wrapper: newWrapper[T, T](src, 16),
+		period:  period,
+	}
+}
+
+type rateLimit[T any] struct {
+	wrapper[T, T]
+	period time.Duration
+}
+
+func (r *rateLimit[T]) Run(ctx context.Context) error {
+	return r.run(ctx, func(in <-chan T) {
+		var (
+			pending   T
+			have      bool
+			throttled bool
+			timer     *time.Timer
+			timerC    <-chan time.Time
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		startTimer := func() {
+			timer = time.NewTimer(r.period)
+			timerC = timer.C
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if have {
+						r.send(pending)
+					}
+					return
+				}
+				if !throttled {
+					r.send(v)
+					throttled = true
+					startTimer()
+					continue
+				}
+				pending = v
+				have = true
+
+			case <-timerC:
+				if have {
+					r.send(pending)
+					have = false
+					startTimer()
+					continue
+				}
+				throttled = false
+				timer = nil
+				timerC = nil
+			}
+		}
+	})
+}
+
+//
+// Debounce
+//
+
+// DebounceComparable emits a value only after it has remained unchanged for d.
+// Requires T comparable.
+func DebounceComparable[T comparable](src Source[T], d time.Duration) Source[T] {
+	return DebounceFunc(src, d, func(a, b T) bool { return a == b })
+}
+
+// DebounceFunc is Debounce with a custom equality comparator.
+//
+// Each new (unequal) value restarts the window; a value is emitted once no
+// different value has arrived for d. Repeats of the pending value do not
+// extend the window. When the source ends, the pending value is emitted
+// without waiting out the window, as RateLimit does.
+func DebounceFunc[T any](src Source[T], d time.Duration, equal func(a, b T) bool) Source[T] {
+	return &debounce[T]{
+		wrapper: newWrapper[T, T](src, 16),
+		d:       d,
+		equal:   equal,
+	}
+}
+
+type debounce[T any] struct {
+	wrapper[T, T]
+	d     time.Duration
+	equal func(a, b T) bool
+}
+
+func (d *debounce[T]) Run(ctx context.Context) error {
+	return d.run(ctx, func(in <-chan T) {
+		var (
+			pending     T
+			havePending bool
+			timer       *time.Timer
+			timerC      <-chan time.Time
+		)
+
+		stopTimer := func() {
+			if timer != nil {
+				timer.Stop()
+				timer = nil
+				timerC = nil
+			}
+		}
+		defer stopTimer()
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if havePending {
+						d.send(pending)
+					}
+					return
+				}
+				if havePending && d.equal(v, pending) {
+					continue
+				}
+				pending = v
+				havePending = true
+				stopTimer()
+				timer = time.NewTimer(d.d)
+				timerC = timer.C
+
+			case <-timerC:
+				// Window elapsed; emit pending and wait for the next change.
+				d.send(pending)
+				havePending = false
+				timer = nil
+				timerC = nil
+			}
+		}
+	})
+}
+
+//
+// LastValue
+//
+
+// LastValued is a Source with an additional Last() getter.
+type LastValued[T any] interface {
+	Source[T]
+	Last() (T, bool)
+}
+
+// LastValue wraps a source and remembers the last value observed.
+// Values are passed through unchanged.
+func LastValue[T any](src Source[T]) LastValued[T] {
+	return &lastValue[T]{
+		wrapper: newWrapper[T, T](src, 16),
+	}
+}
+
+type lastValue[T any] struct {
+	wrapper[T, T]
+
+	mu   sync.RWMutex
+	last T
+	have bool
+}
+
+// Last returns the most recent value and whether one has been seen.
+func (l *lastValue[T]) Last() (T, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.last, l.have
+}
+
+func (l *lastValue[T]) Run(ctx context.Context) error {
+	return l.run(ctx, func(in <-chan T) {
+		for v := range in {
+			l.mu.Lock()
+			l.last = v
+			l.have = true
+			l.mu.Unlock()
+			l.send(v)
+		}
+	})
+}
+
+//
+// FanOut
+//
+
+// FanOutHub lets multiple consumers subscribe to the same source stream.
+type FanOutHub[T any] interface {
+	Device
+	Subscribe() <-chan T
+	Subscribers() int
+}
+
+// FanOut creates a hub that broadcasts each value to all subscribers,
+// each with a buffer of buf values.
+//
+// FanOut is a Hub whose subscribers all use DropNewest: a slow subscriber
+// does not block the others. Subscriber channels are closed when Run returns.
+func FanOut[T any](src Source[T], buf int) FanOutHub[T] {
+	return &fanOut[T]{
+		Hub: NewHub(src),
+		buf: buf,
+	}
+}
+
+type fanOut[T any] struct {
+	*Hub[T]
+	buf int
+}
+
+// Subscribe adds a subscriber. After Run returns it returns a closed channel.
+func (f *fanOut[T]) Subscribe() <-chan T {
+	ch, _ := f.Hub.Subscribe(f.buf)
+	return ch
+}
@@ -0,0 +1,202 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedSource returns a test source that emits the values sent on feed
+// until feed is closed, then waits for ctx.
+func feedSource(name string, feed <-chan int) *testSource {
+	return newTestSource(name, func(ctx context.Context, out chan int) error {
+		for {
+			select {
+			case v, ok := <-feed:
+				if !ok {
+					<-ctx.Done()
+					return nil
+				}
+				out <- v
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+func recv[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for value")
+	}
+	var zero T
+	return zero
+}
+
+func TestLastValuePassThrough(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	src := feedSource("src", feed)
+	lv := LastValue[int](src)
+	require.Equal(t, "src", lv.Name())
+
+	_, ok := lv.Last()
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- lv.Run(ctx) }()
+
+	feed <- 1
+	assert.Equal(t, 1, recv(t, lv.Out()))
+	feed <- 2
+	assert.Equal(t, 2, recv(t, lv.Out()))
+
+	v, ok := lv.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	_, ok = <-lv.Out()
+	assert.False(t, ok)
+}
+
+func TestWrapperPassesEventsAndErrors(t *testing.T) {
+	t.Parallel()
+
+	errOpen := errors.New("open failed")
+	src := newTestSource("src", func(ctx context.Context, out chan int) error {
+		return errOpen
+	})
+	src.Emit(EventError, "open failed", errOpen, nil)
+
+	w := LastValue[int](src)
+	require.ErrorIs(t, w.Run(context.Background()), errOpen)
+
+	evs := drainEvents(w.Events())
+	require.Len(t, evs, 1)
+	assert.Equal(t, "src", evs[0].Device)
+	assert.Equal(t, EventError, evs[0].Kind)
+
+	desc := w.(Described).Descriptor()
+	assert.Equal(t, "src", desc.Name)
+}
+
+func TestRateLimitCoalesces(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	rl := RateLimit[int](feedSource("src", feed), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- rl.Run(ctx) }()
+
+	feed <- 1
+	assert.Equal(t, 1, recv(t, rl.Out()), "first value passes immediately")
+
+	feed <- 2
+	feed <- 3
+	assert.Equal(t, 3, recv(t, rl.Out()), "only the latest value within the period")
+
+	select {
+	case v := <-rl.Out():
+		t.Fatalf("unexpected value %d", v)
+	case <-time.After(120 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestDebounceComparableEmitsStableValue(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	db := DebounceComparable[int](feedSource("src", feed), 30*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- db.Run(ctx) }()
+
+	// bouncing input settles on 0
+	for _, v := range []int{1, 0, 1, 0} {
+		feed <- v
+	}
+	assert.Equal(t, 0, recv(t, db.Out()))
+
+	select {
+	case v := <-db.Out():
+		t.Fatalf("unexpected value %d", v)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestDebounceFlushesOnClose(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	src := newTestSource("src", func(ctx context.Context, out chan int) error {
+		for v := range feed {
+			out <- v
+		}
+		return nil
+	})
+	db := DebounceComparable[int](src, time.Hour)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- db.Run(context.Background()) }()
+
+	feed <- 1
+	feed <- 2
+	close(feed)
+	require.NoError(t, <-errCh)
+
+	var got []int
+	for v := range db.Out() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{2}, got, "pending value flushed, not dropped")
+}
+
+func TestFanOutBroadcasts(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	hub := FanOut[int](feedSource("src", feed), 4)
+	assert.Equal(t, 0, hub.Subscribers(), "no subscribers until Subscribe")
+
+	a := hub.Subscribe()
+	b := hub.Subscribe()
+	assert.Equal(t, 2, hub.Subscribers())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- hub.Run(ctx) }()
+
+	feed <- 7
+	assert.Equal(t, 7, recv(t, a))
+	assert.Equal(t, 7, recv(t, b))
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	_, ok := <-a
+	assert.False(t, ok)
+	_, ok = <-hub.Subscribe()
+	assert.False(t, ok, "subscribing after Run returns yields a closed channel")
+}
@@ -68,8 +68,9 @@ type Config struct {
 // BME280 is a channels-based BME280 sensor device.
 type BME280 struct {
 	devices.Base
-	cfg Config
-	out chan Env
+	cfg     Config
+	out     chan Env
+	samples chan devices.Sample[Env]
 }
 
 // New constructs a new BME280 device.
@@ -81,15 +82,19 @@ func New(cfg Config) *BME280 {
 		cfg.Addr = DefaultAddr
 	}
 	return &BME280{
-		Base: devices.NewBase(cfg.Name, 16),
-		cfg:  cfg,
-		out:  make(chan Env, 16),
+		Base:    devices.NewBase(cfg.Name, 16),
+		cfg:     cfg,
+		out:     make(chan Env, 16),
+		samples: make(chan devices.Sample[Env], 16),
 	}
 }
 
 // Out returns the sample stream.
 func (b *BME280) Out() <-chan Env { return b.out }
 
+// OutSamples returns the sample stream stamped with the time of each read.
+func (b *BME280) OutSamples() <-chan devices.Sample[Env] { return b.samples }
+
 // Descriptor returns metadata for discovery/introspection.
 func (b *BME280) Descriptor() devices.Descriptor {
 	return devices.Descriptor{
@@ -177,6 +182,7 @@ func (b *BME280) Run(ctx context.Context) error {
 		EmitInitial:    b.cfg.EmitInitial,
 		DropOnFull:     b.cfg.DropOnFull,
 		Read:           read,
+		Samples:        b.samples,
 		SampleEventMsg: "sample",
 		SampleMeta: func(v Env) map[string]string {
 			return map[string]string{
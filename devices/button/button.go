@@ -2,6 +2,7 @@ package button
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/rustyeddy/devices"
@@ -22,7 +23,9 @@ type ButtonConfig struct {
 // Button reports a GPIO input line as a boolean stream.
 type Button struct {
 	devices.Base
-	out chan bool
+	out     chan bool
+	samples chan devices.Sample[bool]
+	seq     uint64
 
 	cfg  ButtonConfig
 	line drivers.InputLine
@@ -40,15 +43,20 @@ func NewButton(cfg ButtonConfig) *Button {
 		cfg.Bias = drivers.BiasPullUp
 	}
 	return &Button{
-		Base: devices.NewBase(cfg.Name, 16),
-		out:  make(chan bool, 16),
-		cfg:  cfg,
+		Base:    devices.NewBase(cfg.Name, 16),
+		out:     make(chan bool, 16),
+		samples: make(chan devices.Sample[bool], 16),
+		cfg:     cfg,
 	}
 }
 
 // Out returns the button state stream.
 func (b *Button) Out() <-chan bool { return b.out }
 
+// OutSamples returns the button state stream stamped with the edge time
+// reported by the line driver.
+func (b *Button) OutSamples() <-chan devices.Sample[bool] { return b.samples }
+
 // Descriptor returns the button metadata.
 func (b *Button) Descriptor() devices.Descriptor {
 	return devices.Descriptor{
@@ -87,6 +95,7 @@ func (b *Button) Run(ctx context.Context) error {
 	defer func() {
 		_ = b.line.Close()
 		close(b.out)
+		close(b.samples)
 		b.Emit(devices.EventClose, "stop", nil, nil)
 		b.Close()
 	}()
@@ -94,10 +103,7 @@ func (b *Button) Run(ctx context.Context) error {
 	// Emit initial state so MQTT state is meaningful immediately.  Todo, what if
 	initial, err := b.line.Read()
 	if err == nil {
-		select {
-		case b.out <- initial:
-		default:
-		}
+		b.publish(initial, time.Now())
 	}
 
 	evCh, err := b.line.Events(ctx)
@@ -108,6 +114,7 @@ func (b *Button) Run(ctx context.Context) error {
 
 	var state = initial
 	var last time.Time
+	var lastSeq uint64
 
 	for {
 		select {
@@ -116,6 +123,16 @@ func (b *Button) Run(ctx context.Context) error {
 				return nil
 			}
 
+			// Seq gaps mean edges were lost between the kernel and us.
+			if ev.Seq > 0 {
+				if lastSeq > 0 && ev.Seq > lastSeq+1 {
+					b.Emit(devices.EventInfo, "edges dropped", nil, map[string]string{
+						"dropped": strconv.FormatUint(ev.Seq-lastSeq-1, 10),
+					})
+				}
+				lastSeq = ev.Seq
+			}
+
 			// Extra debounce guard (kernel debounce may already handle it, but harmless)
 			if b.cfg.Debounce > 0 && !last.IsZero() && ev.Time.Sub(last) < b.cfg.Debounce {
 				continue
@@ -123,10 +140,7 @@ func (b *Button) Run(ctx context.Context) error {
 			last = ev.Time
 
 			state = ev.Value
-			select {
-			case b.out <- state:
-			default:
-			}
+			b.publish(state, ev.Time)
 
 			b.Emit(devices.EventEdge, "edge", nil, map[string]string{"edge": string(ev.Edge)})
 
@@ -136,6 +150,21 @@ func (b *Button) Run(ctx context.Context) error {
 	}
 }
 
+// publish sends a state to Out and OutSamples without blocking.
+func (b *Button) publish(state bool, at time.Time) {
+	b.seq++
+	select {
+	case b.out <- state:
+		b.SamplePublished()
+	default:
+		b.SampleDropped()
+	}
+	select {
+	case b.samples <- devices.Sample[bool]{Value: state, Time: at, Seq: b.seq, Quality: devices.QualityGood}:
+	default:
+	}
+}
+
 type devicesErr string
 
 func (e devicesErr) Error() string { return string(e) }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rustyeddy/devices"
 	"github.com/rustyeddy/devices/drivers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,3 +53,128 @@ func TestButtonRunEmitsState(t *testing.T) {
 	cancel()
 	require.NoError(t, <-errCh)
 }
+
+func TestButtonOutSamples(t *testing.T) {
+	t.Parallel()
+
+	f := drivers.NewVPIOFactory()
+	btn := NewButton(ButtonConfig{
+		Name:     "btn",
+		Factory:  f,
+		Chip:     "chip0",
+		Offset:   2,
+		Debounce: time.Nanosecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- btn.Run(ctx) }()
+
+	initial := <-btn.OutSamples()
+	assert.False(t, initial.Value)
+	assert.Equal(t, uint64(1), initial.Seq)
+
+	before := time.Now()
+	f.InjectEdge("chip0", 2, drivers.EdgeRising, true)
+	s := <-btn.OutSamples()
+	assert.True(t, s.Value)
+	assert.Equal(t, uint64(2), s.Seq)
+	assert.Equal(t, devices.QualityGood, s.Quality)
+	assert.False(t, s.Time.Before(before))
+
+	cancel()
+	require.NoError(t, <-errCh)
+	_, ok := <-btn.OutSamples()
+	assert.False(t, ok)
+}
+
+// scriptedLine is an InputLine replaying fixed events.
+type scriptedLine struct {
+	events []drivers.LineEvent
+}
+
+func (l *scriptedLine) Read() (bool, error) { return false, nil }
+func (l *scriptedLine) Close() error        { return nil }
+
+func (l *scriptedLine) Events(ctx context.Context) (<-chan drivers.LineEvent, error) {
+	ch := make(chan drivers.LineEvent, len(l.events))
+	for _, ev := range l.events {
+		ch <- ev
+	}
+	return ch, nil
+}
+
+type scriptedFactory struct{ line *scriptedLine }
+
+func (f scriptedFactory) OpenInput(string, int, drivers.Edge, drivers.Bias, time.Duration) (drivers.InputLine, error) {
+	return f.line, nil
+}
+
+func (f scriptedFactory) OpenOutput(string, int, bool) (drivers.OutputLine, error) {
+	return nil, nil
+}
+
+func TestButtonReportsDroppedEdges(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	line := &scriptedLine{events: []drivers.LineEvent{
+		{Time: now, Edge: drivers.EdgeRising, Value: true, Seq: 1},
+		{Time: now.Add(time.Second), Edge: drivers.EdgeFalling, Value: false, Seq: 4},
+	}}
+	btn := NewButton(ButtonConfig{Name: "btn", Factory: scriptedFactory{line: line}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- btn.Run(ctx) }()
+
+	for {
+		ev := <-btn.Events()
+		if ev.Msg == "edges dropped" {
+			assert.Equal(t, devices.EventInfo, ev.Kind)
+			assert.Equal(t, "2", ev.Meta["dropped"])
+			break
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestButtonSingleEdgeNoSpuriousDrops(t *testing.T) {
+	t.Parallel()
+
+	f := drivers.NewVPIOFactory()
+	btn := NewButton(ButtonConfig{
+		Name:     "btn",
+		Factory:  f,
+		Chip:     "chip0",
+		Offset:   3,
+		Edge:     drivers.EdgeRising,
+		Debounce: time.Nanosecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- btn.Run(ctx) }()
+	<-btn.Out() // initial
+
+	// The falling edges in between are filtered, not lost.
+	for range 3 {
+		f.InjectEdge("chip0", 3, drivers.EdgeRising, true)
+		assert.True(t, <-btn.Out())
+		f.InjectEdge("chip0", 3, drivers.EdgeFalling, false)
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+	for ev := range btn.Events() {
+		assert.NotEqual(t, "edges dropped", ev.Msg)
+	}
+}
@@ -0,0 +1,181 @@
+package gtu7
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// Clock sources.
+const (
+	ClockSourceNMEA = "nmea" // sentence arrival: off by the serial latency, ~0.1-0.5s
+	ClockSourcePPS  = "pps"  // 1PPS edge: as good as the edge timestamp
+)
+
+// ClockConfig configures a Clock.
+type ClockConfig struct {
+	Name string
+
+	// PPS, if set, is the input wired to the receiver's 1PPS output. Its
+	// rising edges mark the start of each UTC second. Run reads it and
+	// closes it when done.
+	PPS drivers.InputLine
+
+	// MaxAge is how long after the last GPS time the offset is trusted,
+	// as the system clock drifts. Default 10m.
+	MaxAge time.Duration
+
+	// ReportEvery rate-limits the "offset" events. Default 1m.
+	ReportEvery time.Duration
+
+	// Now is the system clock. Default time.Now.
+	Now func() time.Time
+}
+
+// Clock is a time source disciplined by GPS, for stations without NTP.
+// A GTU7 with GTU7Config.Clock set feeds it every GPS time it reads; it
+// tracks the offset of GPS time from the system clock and reports it as
+// EventInfo "offset" events.
+//
+// Run is only needed to read PPS edges and publish events. The Clock
+// keeps working after Run returns; only its events stop.
+type Clock struct {
+	devices.Base
+	cfg ClockConfig
+
+	mu       sync.Mutex
+	offset   time.Duration // GPS minus system time
+	source   string
+	synced   time.Time // system time of the last sync
+	edge     time.Time // last PPS rising edge
+	reported time.Time
+}
+
+// NewClock constructs a Clock with defaults applied.
+func NewClock(cfg ClockConfig) *Clock {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 10 * time.Minute
+	}
+	if cfg.ReportEvery <= 0 {
+		cfg.ReportEvery = time.Minute
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Clock{
+		Base: devices.NewBase(cfg.Name, 16),
+		cfg:  cfg,
+	}
+}
+
+// Now returns the system time corrected to GPS time. It reports false
+// if there has been no GPS time within MaxAge.
+func (c *Clock) Now() (time.Time, bool) {
+	off, ok := c.Offset()
+	return c.cfg.Now().Add(off), ok
+}
+
+// Offset returns GPS time minus system time: positive when the system
+// clock is behind. It reports false if there has been no GPS time
+// within MaxAge.
+func (c *Clock) Offset() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offset, c.fresh()
+}
+
+// Source returns ClockSourcePPS or ClockSourceNMEA for the last sync,
+// or "" before the first.
+func (c *Clock) Source() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
+}
+
+// fresh reports whether the last sync is within MaxAge. c.mu is held.
+func (c *Clock) fresh() bool {
+	return !c.synced.IsZero() && c.cfg.Now().Sub(c.synced) <= c.cfg.MaxAge
+}
+
+// Observe records that the receiver reported GPS time gps in a sentence
+// read at system time at. Within a second of a PPS edge, gps labels the
+// second that started at the edge, which gives the exact offset.
+func (c *Clock) Observe(gps, at time.Time) {
+	c.mu.Lock()
+	off, src := gps.Sub(at), ClockSourceNMEA
+	if !c.edge.IsZero() {
+		if d := at.Sub(c.edge); d >= 0 && d < time.Second {
+			off, src = gps.Truncate(time.Second).Sub(c.edge), ClockSourcePPS
+		}
+	}
+	c.sync(off, src, at)
+}
+
+// pulse records a PPS rising edge at system time e. Once synced, the
+// edge is the start of the GPS second nearest to it.
+func (c *Clock) pulse(e time.Time) {
+	c.mu.Lock()
+	c.edge = e
+	if c.synced.IsZero() {
+		c.mu.Unlock()
+		return
+	}
+	gps := e.Add(c.offset).Round(time.Second)
+	c.sync(gps.Sub(e), ClockSourcePPS, e)
+}
+
+// sync stores a new offset and reports it, rate-limited. It unlocks c.mu.
+// The event is emitted with c.mu held so that Run cannot close Base
+// under an Observe from the GPS goroutine.
+func (c *Clock) sync(off time.Duration, src string, at time.Time) {
+	defer c.mu.Unlock()
+	first := c.synced.IsZero() || src != c.source
+	c.offset, c.source, c.synced = off, src, at
+	if !first && at.Sub(c.reported) < c.cfg.ReportEvery {
+		return
+	}
+	c.reported = at
+	c.Emit(devices.EventInfo, "offset", nil, map[string]string{
+		"offset": off.String(),
+		"source": src,
+	})
+}
+
+// Run reads PPS edges, if configured, until ctx is canceled.
+func (c *Clock) Run(ctx context.Context) error {
+	c.Emit(devices.EventOpen, "run", nil, nil)
+	defer func() {
+		c.Emit(devices.EventClose, "stop", nil, nil)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.Close()
+	}()
+
+	if c.cfg.PPS == nil {
+		<-ctx.Done()
+		return nil
+	}
+	defer func() { _ = c.cfg.PPS.Close() }()
+
+	evs, err := c.cfg.PPS.Events(ctx)
+	if err != nil {
+		c.Emit(devices.EventError, "pps events failed", err, nil)
+		return err
+	}
+	for {
+		select {
+		case ev, ok := <-evs:
+			if !ok {
+				return nil
+			}
+			if ev.Edge == drivers.EdgeRising {
+				c.pulse(ev.Time)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
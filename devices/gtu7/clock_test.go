@@ -0,0 +1,180 @@
+package gtu7
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+var _ devices.Device = (*Clock)(nil)
+
+// fakeClock is a settable system clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// ppsLine is an InputLine whose edges the test sends.
+type ppsLine struct {
+	ch     chan drivers.LineEvent
+	closed atomic.Bool
+}
+
+func (l *ppsLine) Read() (bool, error) { return false, nil }
+func (l *ppsLine) Events(context.Context) (<-chan drivers.LineEvent, error) {
+	return l.ch, nil
+}
+func (l *ppsLine) Close() error { l.closed.Store(true); return nil }
+
+var sys0 = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) // system clock, 3h slow
+
+func TestClockNMEAOffset(t *testing.T) {
+	t.Parallel()
+
+	fc := &fakeClock{now: sys0}
+	c := NewClock(ClockConfig{Now: fc.Now, MaxAge: time.Minute})
+
+	gps := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	c.Observe(gps, sys0.Add(150*time.Millisecond)) // sentence latency
+
+	off, ok := c.Offset()
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Hour-150*time.Millisecond, off)
+	now, ok := c.Now()
+	require.True(t, ok)
+	assert.Equal(t, gps.Add(-150*time.Millisecond), now)
+
+	// Stale after MaxAge without GPS time.
+	fc.Set(sys0.Add(2 * time.Minute))
+	_, ok = c.Now()
+	assert.False(t, ok)
+}
+
+func TestClockPPS(t *testing.T) {
+	t.Parallel()
+
+	fc := &fakeClock{now: sys0}
+	line := &ppsLine{ch: make(chan drivers.LineEvent, 4)}
+	c := NewClock(ClockConfig{Name: "clock", PPS: line, Now: fc.Now})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	// The edge starts GPS second 12:00:00; its sentence follows 150ms on.
+	edge := sys0.Add(20 * time.Millisecond)
+	line.ch <- drivers.LineEvent{Time: edge, Edge: drivers.EdgeRising, Value: true}
+	line.ch <- drivers.LineEvent{Time: edge.Add(100 * time.Millisecond), Edge: drivers.EdgeFalling}
+	gps := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.edge.Equal(edge)
+	}, time.Second, time.Millisecond)
+	c.Observe(gps, edge.Add(150*time.Millisecond))
+
+	off, ok := c.Offset()
+	require.True(t, ok)
+	assert.Equal(t, gps.Sub(edge), off)
+	assert.Equal(t, ClockSourcePPS, c.Source())
+
+	// The next edge, 1.000002s later by the system clock, refines it.
+	line.ch <- drivers.LineEvent{Time: edge.Add(time.Second + 2*time.Microsecond), Edge: drivers.EdgeRising, Value: true}
+	want := gps.Add(time.Second).Sub(edge.Add(time.Second + 2*time.Microsecond))
+	require.Eventually(t, func() bool {
+		off, _ := c.Offset()
+		return off == want
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.True(t, line.closed.Load())
+
+	var offsets []string
+	for ev := range c.Events() {
+		if ev.Msg == "offset" {
+			offsets = append(offsets, ev.Meta["source"]+" "+ev.Meta["offset"])
+		}
+	}
+	// The refinement falls within ReportEvery of the first report.
+	assert.Equal(t, []string{"pps " + gps.Sub(edge).String()}, offsets)
+}
+
+func TestClockPPSIgnoresStaleEdge(t *testing.T) {
+	t.Parallel()
+
+	c := NewClock(ClockConfig{Now: func() time.Time { return sys0 }})
+	c.pulse(sys0) // before any GPS time: remembered, no sync
+	_, ok := c.Offset()
+	require.False(t, ok)
+
+	// A sentence 1.5s after the edge is not its second's.
+	gps := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	c.Observe(gps, sys0.Add(1500*time.Millisecond))
+	off, ok := c.Offset()
+	require.True(t, ok)
+	assert.Equal(t, gps.Sub(sys0.Add(1500*time.Millisecond)), off)
+	assert.Equal(t, ClockSourceNMEA, c.Source())
+}
+
+func TestClockObserveAfterRun(t *testing.T) {
+	t.Parallel()
+
+	// The GPS goroutine keeps feeding the clock while Run stops; the
+	// window for a send on a closed events channel is narrow, so go
+	// round a few times.
+	gps := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for range 50 {
+		c := NewClock(ClockConfig{ReportEvery: time.Nanosecond})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- c.Run(ctx) }()
+
+		stop := make(chan struct{})
+		fed := make(chan struct{})
+		go func() {
+			defer close(fed)
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				c.Observe(gps.Add(time.Duration(i)*time.Second), time.Now())
+				runtime.Gosched()
+			}
+		}()
+		require.Eventually(t, func() bool {
+			_, ok := c.Offset()
+			return ok
+		}, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+		close(stop)
+		<-fed
+
+		_, ok := c.Offset()
+		assert.True(t, ok, "the clock still works after Run")
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rustyeddy/devices"
 	"github.com/rustyeddy/devices/drivers"
+	"github.com/rustyeddy/devices/nmea"
 )
 
 type GPSFix struct {
@@ -26,271 +29,270 @@ type GPSFix struct {
 	CourseDeg  float64
 
 	Status string // RMC: A/V
-	Date   string // DDMMYY
+
+	// Date is the RMC date, DDMMYY.
+	//
+	// Deprecated: use Time.
+	Date string
+
+	// Time is the UTC time of the last sentence that carried one: RMC
+	// and ZDA, or GGA once a date is known. TimeValid is set if the
+	// receiver vouched for it (RMC status A, a GGA fix, or a complete
+	// ZDA).
+	Time      time.Time
+	TimeValid bool
 }
 
+// GTU7Config configures a GT-U7 (u-blox NEO-6M) GPS receiver.
 type GTU7Config struct {
-	Name    string
-	Serial  drivers.SerialConfig
+	Name string
+
+	// Serial is the port Run opens, e.g. /dev/ttyAMA0 at 9600 baud.
+	Serial drivers.SerialConfig
+
+	// Factory opens Serial. Default drivers.LinuxSerialFactory.
 	Factory drivers.SerialFactory
 
-	// Test injection
+	// Reader, if set, is read instead of opening Serial (tests). Run
+	// closes it when it returns if it is an io.Closer.
 	Reader io.Reader
+
+	// OnLine, if set, is called with every line read, before it is
+	// parsed.
+	OnLine func(line string)
+
+	// Buf sizes the out channel. Default 4.
+	Buf int
+
+	// Clock, if set, is fed each valid GPS time.
+	Clock *Clock
 }
 
+// GTU7 reads NMEA sentences from a serial GPS and publishes the fix
+// built up from GGA, RMC and VTG, from any talker (GP, GN, GL, ...).
 type GTU7 struct {
-	name string
-	out  chan GPSFix
-	r    io.Reader
+	devices.Base
+	out    chan GPSFix
+	cfg    GTU7Config
+	parser nmea.Parser
 }
 
+// NewGTU7 constructs a GTU7. The port is opened by Run.
 func NewGTU7(cfg GTU7Config) *GTU7 {
 	if cfg.Factory == nil {
 		cfg.Factory = drivers.LinuxSerialFactory{}
 	}
-
-	var r io.Reader
-	if cfg.Reader != nil {
-		r = cfg.Reader
-	} else {
-		port, err := cfg.Factory.OpenSerial(cfg.Serial)
-		if err != nil {
-			panic(err)
-		}
-		r = port
+	if cfg.Buf <= 0 {
+		cfg.Buf = 4
 	}
-
-	return &GTU7{
-		name: cfg.Name,
-		out:  make(chan GPSFix, 4),
-		r:    r,
+	g := &GTU7{
+		Base: devices.NewBase(cfg.Name, 16),
+		out:  make(chan GPSFix, cfg.Buf),
+		cfg:  cfg,
 	}
+	// The GT-U7 checksums every sentence.
+	g.parser.RequireChecksum = true
+	return g
 }
 
 func (g *GTU7) Out() <-chan GPSFix { return g.out }
 
 func (g *GTU7) Descriptor() devices.Descriptor {
-	return devices.Descriptor{
-		Name:      g.name,
+	d := devices.Descriptor{
+		Name:      g.Name(),
 		Kind:      "gps",
 		ValueType: "GPSFix",
+		Access:    devices.ReadOnly,
+		Tags:      []string{"gps", "serial"},
 	}
+	if g.cfg.Reader == nil {
+		d.Attributes = map[string]string{
+			"port": g.cfg.Serial.Port,
+			"baud": strconv.Itoa(g.cfg.Serial.Baud),
+		}
+	}
+	return d
 }
 
+// Run opens the port and publishes a fix for each sentence that updates
+// it, until ctx is canceled or the port fails. Bad sentences are
+// reported as EventError and skipped.
 func (g *GTU7) Run(ctx context.Context) error {
-	defer close(g.out)
+	g.Emit(devices.EventOpen, "run", nil, nil)
+
+	r := g.cfg.Reader
+	if r == nil {
+		port, err := g.cfg.Factory.OpenSerial(g.cfg.Serial)
+		if err != nil {
+			g.Emit(devices.EventError, "open serial failed", err, nil)
+			close(g.out)
+			g.Close()
+			return err
+		}
+		r = port
+	}
+
+	// Closing the port is the only way to unblock a pending Read.
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		if c, ok := r.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+		close(g.out)
+		g.Emit(devices.EventClose, "stop", nil, nil)
+		g.Close()
+	}()
+
+	err := g.scan(ctx, r)
+	if ctx.Err() != nil {
+		return nil // the read failed because we closed the port
+	}
+	if err != nil {
+		g.Emit(devices.EventError, "read failed", err, nil)
+	}
+	return err
+}
 
+// scan reads lines until r ends or ctx is canceled.
+func (g *GTU7) scan(ctx context.Context, r io.Reader) error {
 	var last GPSFix
 	haveFix := false
 
+	// date is the last date seen, for GGA's time of day; observed is
+	// the last time handed to the Clock.
+	var date nmea.Date
+	var observed time.Time
+
 	// RMC precedence flags
 	haveRMCSpeed := false
 	haveRMCCourse := false
 
-	sc := bufio.NewScanner(g.r)
+	sc := bufio.NewScanner(r)
 	for sc.Scan() {
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			return nil
-		default:
 		}
 
+		at := time.Now()
 		line := strings.TrimSpace(sc.Text())
 		if line == "" {
 			continue
 		}
+		if g.cfg.OnLine != nil {
+			g.cfg.OnLine(line)
+		}
 
-		if fix, ok := parseGPGGA(line); ok {
-			last.Lat = fix.Lat
-			last.Lon = fix.Lon
-			last.AltMeters = fix.AltMeters
-			last.HDOP = fix.HDOP
-			last.Satellites = fix.Satellites
-			last.Quality = fix.Quality
-
-			haveFix = true
-			g.emit(last)
+		s, err := g.parser.Parse(line)
+		switch {
+		case errors.Is(err, nmea.ErrUnsupported):
+			continue
+		case errors.Is(err, nmea.ErrChecksum):
+			g.Emit(devices.EventError, "bad checksum", err, map[string]string{"sentence": line})
+			continue
+		case err != nil:
+			g.Emit(devices.EventError, "bad sentence", err, map[string]string{"sentence": line})
 			continue
 		}
 
-		if fix, ok := parseGPRMC(line); ok {
-			if !math.IsNaN(fix.Lat) {
-				last.Lat = fix.Lat
-				last.Lon = fix.Lon
+		switch s := s.(type) {
+		case nmea.GGA:
+			if !math.IsNaN(s.Lat) {
+				last.Lat = s.Lat
+				last.Lon = s.Lon
+				last.AltMeters = s.Altitude
+				haveFix = true
+			}
+			last.HDOP = s.HDOP
+			last.Satellites = s.Satellites
+			last.Quality = s.Quality
+			setTime(&last, date, s.Time, s.Quality > 0)
+
+		case nmea.RMC:
+			if !math.IsNaN(s.Lat) {
+				last.Lat = s.Lat
+				last.Lon = s.Lon
 				haveFix = true
 			}
-			if !math.IsNaN(fix.SpeedKnots) {
-				last.SpeedKnots = fix.SpeedKnots
-				last.SpeedMPS = fix.SpeedMPS
+			if !math.IsNaN(s.SpeedKnots) {
+				last.SpeedKnots = s.SpeedKnots
+				last.SpeedMPS = s.SpeedKnots * nmea.KnotsToMPS
 				haveRMCSpeed = true
 			}
-			if !math.IsNaN(fix.CourseDeg) {
-				last.CourseDeg = fix.CourseDeg
+			if !math.IsNaN(s.CourseDeg) {
+				last.CourseDeg = s.CourseDeg
 				haveRMCCourse = true
 			}
-			if fix.Status != "" {
-				last.Status = fix.Status
+			if s.Status != "" {
+				last.Status = s.Status
 			}
-			if fix.Date != "" {
-				last.Date = fix.Date
+			if d := s.Date; d.Valid {
+				last.Date = fmt.Sprintf("%02d%02d%02d", d.Day, d.Month, d.Year%100)
+				date = d
 			}
+			setTime(&last, date, s.Time, s.Valid())
 
-			if haveFix {
-				g.emit(last)
+		case nmea.VTG:
+			if !math.IsNaN(s.SpeedKnots) && (!haveRMCSpeed || math.IsNaN(last.SpeedKnots)) {
+				last.SpeedKnots = s.SpeedKnots
+				last.SpeedMPS = s.SpeedKnots * nmea.KnotsToMPS
 			}
-			continue
-		}
-
-		if fix, ok := parseGPVTG(line); ok {
-			if !math.IsNaN(fix.SpeedKnots) && (!haveRMCSpeed || math.IsNaN(last.SpeedKnots)) {
-				last.SpeedKnots = fix.SpeedKnots
-				last.SpeedMPS = fix.SpeedMPS
-			}
-			if !math.IsNaN(fix.CourseDeg) && (!haveRMCCourse || math.IsNaN(last.CourseDeg)) {
-				last.CourseDeg = fix.CourseDeg
+			if !math.IsNaN(s.TrueCourse) && (!haveRMCCourse || math.IsNaN(last.CourseDeg)) {
+				last.CourseDeg = s.TrueCourse
 			}
 
-			if haveFix {
-				g.emit(last)
+		case nmea.ZDA:
+			if s.Date.Valid {
+				date = s.Date
 			}
-		}
-	}
+			setTime(&last, date, s.Time, s.Date.Valid && s.Time.Valid)
 
-	return sc.Err()
-}
-
-func (g *GTU7) emit(f GPSFix) {
-	select {
-	case g.out <- f:
-	default:
-	}
-}
-
-/* ---------- Parsing helpers ---------- */
-
-func parseGPGGA(line string) (GPSFix, bool) {
-	line = stripChecksum(line)
-	parts := strings.Split(line, ",")
-	if len(parts) < 10 || (parts[0] != "$GPGGA" && parts[0] != "$GNGGA") {
-		return GPSFix{}, false
-	}
-
-	lat, lon, err := parseLatLon(parts[2], parts[3], parts[4], parts[5])
-	if err != nil {
-		return GPSFix{}, false
-	}
-
-	q, _ := strconv.Atoi(parts[6])
-	sats, _ := strconv.Atoi(parts[7])
-	hdop, _ := strconv.ParseFloat(parts[8], 64)
-	alt, _ := strconv.ParseFloat(parts[9], 64)
-
-	return GPSFix{
-		Lat:        lat,
-		Lon:        lon,
-		AltMeters:  alt,
-		HDOP:       hdop,
-		Satellites: sats,
-		Quality:    q,
-	}, true
-}
-
-func parseGPRMC(line string) (GPSFix, bool) {
-	line = stripChecksum(line)
-	parts := strings.Split(line, ",")
-	if len(parts) < 10 || (parts[0] != "$GPRMC" && parts[0] != "$GNRMC") {
-		return GPSFix{}, false
-	}
-
-	fix := GPSFix{
-		Lat:        math.NaN(),
-		Lon:        math.NaN(),
-		SpeedKnots: math.NaN(),
-		SpeedMPS:   math.NaN(),
-		CourseDeg:  math.NaN(),
-		Status:     parts[2],
-		Date:       parts[9],
-	}
-
-	if parts[3] != "" {
-		if lat, lon, err := parseLatLon(parts[3], parts[4], parts[5], parts[6]); err == nil {
-			fix.Lat = lat
-			fix.Lon = lon
+		default:
+			continue
 		}
-	}
 
-	if parts[7] != "" {
-		if v, err := strconv.ParseFloat(parts[7], 64); err == nil {
-			fix.SpeedKnots = v
-			fix.SpeedMPS = v * 0.514444
+		// The first sentence of each second is the closest to it.
+		if g.cfg.Clock != nil && last.TimeValid && last.Time.After(observed) {
+			g.cfg.Clock.Observe(last.Time, at)
+			observed = last.Time
 		}
-	}
-
-	if parts[8] != "" {
-		if v, err := strconv.ParseFloat(parts[8], 64); err == nil {
-			fix.CourseDeg = v
+		if haveFix {
+			g.emit(last)
 		}
 	}
 
-	return fix, true
+	return sc.Err()
 }
 
-func parseGPVTG(line string) (GPSFix, bool) {
-	line = stripChecksum(line)
-	parts := strings.Split(line, ",")
-	if len(parts) < 9 || (parts[0] != "$GPVTG" && parts[0] != "$GNVTG") {
-		return GPSFix{}, false
-	}
-
-	fix := GPSFix{
-		SpeedKnots: math.NaN(),
-		SpeedMPS:   math.NaN(),
-		CourseDeg:  math.NaN(),
+// setTime sets f.Time from the time of day t on date, if both are known.
+// A time far behind f.Time is taken to be past midnight, before date
+// has caught up.
+func setTime(f *GPSFix, date nmea.Date, t nmea.Time, valid bool) {
+	tm, ok := nmea.DateTime(date, t)
+	if !ok {
+		return
 	}
-
-	if parts[1] != "" {
-		if v, err := strconv.ParseFloat(parts[1], 64); err == nil {
-			fix.CourseDeg = v
-		}
+	if tm.Before(f.Time.Add(-12 * time.Hour)) {
+		tm = tm.AddDate(0, 0, 1)
 	}
-
-	if parts[5] != "" {
-		if v, err := strconv.ParseFloat(parts[5], 64); err == nil {
-			fix.SpeedKnots = v
-			fix.SpeedMPS = v * 0.514444
-		}
-	}
-
-	return fix, true
+	f.Time, f.TimeValid = tm, valid
 }
 
-func stripChecksum(s string) string {
-	if i := strings.IndexByte(s, '*'); i >= 0 {
-		return s[:i]
+func (g *GTU7) emit(f GPSFix) {
+	select {
+	case g.out <- f:
+		g.SamplePublished()
+	default:
+		g.SampleDropped()
 	}
-	return s
 }
 
-func parseLatLon(lat, ns, lon, ew string) (float64, float64, error) {
-	if lat == "" || lon == "" {
-		return 0, 0, errors.New("empty")
-	}
-	la, _ := strconv.ParseFloat(lat, 64)
-	lo, _ := strconv.ParseFloat(lon, 64)
-
-	latDeg := math.Floor(la / 100)
-	latMin := la - latDeg*100
-	latVal := latDeg + latMin/60
-
-	lonDeg := math.Floor(lo / 100)
-	lonMin := lo - lonDeg*100
-	lonVal := lonDeg + lonMin/60
-
-	if ns == "S" {
-		latVal = -latVal
-	}
-	if ew == "W" {
-		lonVal = -lonVal
-	}
-	return latVal, lonVal, nil
-}
+// NMEAStats returns the counts of sentences read, and of those rejected
+// as malformed or failing their checksum.
+func (g *GTU7) NMEAStats() nmea.Stats { return g.parser.Stats() }
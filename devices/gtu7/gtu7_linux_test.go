@@ -0,0 +1,53 @@
+//go:build linux
+
+package gtu7
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices/drivers"
+	"github.com/rustyeddy/devices/drivers/serialtest"
+)
+
+func TestGTU7_ReadsPty(t *testing.T) {
+	t.Parallel()
+
+	pty := serialtest.New(t)
+	gps := NewGTU7(GTU7Config{
+		Name:   "gps",
+		Serial: drivers.SerialConfig{Port: pty.Path(), Baud: 9600, InterByteTimeout: 5 * time.Millisecond},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- gps.Run(context.Background()) }()
+
+	// A sentence split across writes, as a UART delivers it.
+	_, err := pty.WriteString("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,")
+	require.NoError(t, err)
+	_, err = pty.WriteString("545.4,M,46.9,M,,*47\r\n")
+	require.NoError(t, err)
+
+	select {
+	case fix := <-gps.Out():
+		require.InDelta(t, 48.1173, fix.Lat, 1e-4)
+		require.InDelta(t, 11.5167, fix.Lon, 1e-4)
+		require.Equal(t, 8, fix.Satellites)
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "timeout waiting for fix")
+	}
+
+	// Pulling the cable ends the read loop, and Run closes the port.
+	require.NoError(t, pty.Disconnect())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "run did not exit on disconnect")
+	}
+	_, ok := <-gps.Out()
+	require.False(t, ok)
+}
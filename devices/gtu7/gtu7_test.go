@@ -2,18 +2,28 @@ package gtu7
 
 import (
 	"context"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/drivers"
+	"github.com/rustyeddy/devices/nmea"
 )
 
+var _ devices.Device = (*GTU7)(nil)
+
+const testGGA = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"
+
 func TestGTU7_PrefersRMCOverVTG(t *testing.T) {
 	input := `
 $GPGGA,160446.00,3340.34121,N,11800.11332,W,2,08,1.20,11.8,M,-33.1,M,,0000*58
-$GPVTG,54.70,T,,M,5.50,N,10.19,K,A*00
-$GPRMC,160446.00,A,3340.34121,N,11800.11332,W,7.25,123.40,160126,,,A*00
+$GPVTG,54.70,T,,M,5.50,N,10.19,K,A*32
+$GPRMC,160446.00,A,3340.34121,N,11800.11332,W,7.25,123.40,160126,,,A*40
 `
 
 	gps := NewGTU7(GTU7Config{
@@ -55,3 +65,154 @@ $GPRMC,160446.00,A,3340.34121,N,11800.11332,W,7.25,123.40,160126,,,A*00
 		require.FailNow(t, "run did not exit")
 	}
 }
+
+func TestGTU7_BadSentencesAreEvents(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"GPGGA,garbage",
+		"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*48", // wrong checksum
+		"$GPGGA,123519,48x7.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*0F", // bad lat
+		"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,#47", // "*" corrupted
+		"$GPGSV,3,1,11,03,03,111,00*4A",                                     // parsed, not used
+		testGGA,
+		"$GLRMC,123520,A,4807.038,N,01131.000,E,7.25,123.40,160126,,,A*6F", // GLONASS talker
+	}, "\r\n")
+	var lines []string
+	gps := NewGTU7(GTU7Config{
+		Name:   "gps",
+		Reader: strings.NewReader(input),
+		OnLine: func(l string) { lines = append(lines, l) },
+	})
+	require.NoError(t, gps.Run(context.Background()))
+
+	fix, ok := <-gps.Out()
+	require.True(t, ok)
+	assert.InDelta(t, 48.1173, fix.Lat, 1e-4)
+	fix = <-gps.Out()
+	assert.Equal(t, "160126", fix.Date)
+	_, ok = <-gps.Out()
+	assert.False(t, ok, "out closed")
+	assert.Len(t, lines, 7)
+	assert.Equal(t, nmea.Stats{Sentences: 7, Parsed: 3, BadChecksum: 1, Malformed: 3}, gps.NMEAStats())
+
+	var kinds, msgs []string
+	for ev := range gps.Events() {
+		kinds = append(kinds, string(ev.Kind))
+		if ev.Kind == devices.EventError {
+			msgs = append(msgs, ev.Msg+": "+ev.Err.Error())
+		}
+	}
+	assert.Equal(t, []string{"open", "error", "error", "error", "error", "close"}, kinds)
+	assert.Equal(t, []string{
+		"bad sentence: nmea: malformed sentence: missing $",
+		"bad checksum: nmea: checksum mismatch: got 47, want 48",
+		`bad sentence: nmea: malformed sentence: GGA latitude "48x7.038"`,
+		"bad sentence: nmea: malformed sentence: missing checksum",
+	}, msgs)
+}
+
+func TestGTU7_OpenFailsWithoutPanic(t *testing.T) {
+	t.Parallel()
+
+	gps := NewGTU7(GTU7Config{Name: "gps", Factory: drivers.NewMockSerialFactory()})
+	err := gps.Run(context.Background())
+	require.ErrorContains(t, err, "Port is required")
+
+	_, ok := <-gps.Out()
+	assert.False(t, ok)
+	var last devices.Event
+	for ev := range gps.Events() {
+		last = ev
+	}
+	assert.Equal(t, devices.EventError, last.Kind)
+	assert.Equal(t, "open serial failed", last.Msg)
+}
+
+func TestGTU7_CancelClosesPort(t *testing.T) {
+	t.Parallel()
+
+	f := drivers.NewMockSerialFactory()
+	gps := NewGTU7(GTU7Config{
+		Name:    "gps",
+		Serial:  drivers.SerialConfig{Port: "ttyGPS", Baud: 9600},
+		Factory: f,
+	})
+	assert.Equal(t, "ttyGPS", gps.Descriptor().Attributes["port"])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- gps.Run(ctx) }()
+
+	var port *drivers.MockSerialPort
+	require.Eventually(t, func() bool {
+		var ok bool
+		port, ok = f.Port("ttyGPS")
+		return ok
+	}, time.Second, time.Millisecond)
+	_, err := io.WriteString(port, testGGA+"\r\n")
+	require.NoError(t, err)
+	select {
+	case <-gps.Out():
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for fix")
+	}
+
+	// Run is blocked reading; cancel must close the port to return.
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "run did not exit on cancel")
+	}
+	_, err = io.WriteString(port, testGGA)
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+	require.NoError(t, gps.Close())
+}
+
+func TestGTU7_TimeAcrossMidnight(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"$GPRMC,235959.00,A,4807.038,N,01131.000,E,0.0,0.0,311225,,,A*5B",
+		"$GPGGA,000000.00,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*64", // date not yet rolled
+	}, "\r\n")
+	gps := NewGTU7(GTU7Config{Reader: strings.NewReader(input)})
+	require.NoError(t, gps.Run(context.Background()))
+
+	fix := <-gps.Out()
+	assert.True(t, fix.TimeValid)
+	assert.Equal(t, time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), fix.Time)
+	fix = <-gps.Out()
+	assert.True(t, fix.TimeValid)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), fix.Time)
+}
+
+func TestGTU7_ZDAFeedsClock(t *testing.T) {
+	t.Parallel()
+
+	clock := NewClock(ClockConfig{Name: "clock"})
+	input := strings.Join([]string{
+		"$GPRMC,120000.00,V,,,,,,,171026,,,N*7D", // time, not vouched for
+		"$GPZDA,120001.00,17,10,2026,00,00*65",
+		"$GPZDA,120002.00,17,10,2026,00,00*66",
+		"$GPGGA,120003.00,,,,,0,00,99.99,,,,,,*66", // no fix: not vouched for
+		"$GPGGA,120004.00,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*63",
+	}, "\r\n")
+	gps := NewGTU7(GTU7Config{Reader: strings.NewReader(input), Clock: clock})
+
+	_, ok := clock.Offset()
+	require.False(t, ok, "not synced before any GPS time")
+	start := time.Now()
+	require.NoError(t, gps.Run(context.Background()))
+
+	fix := <-gps.Out()
+	assert.True(t, fix.TimeValid)
+	assert.Equal(t, time.Date(2026, 10, 17, 12, 0, 4, 0, time.UTC), fix.Time)
+
+	off, ok := clock.Offset()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Date(2026, 10, 17, 12, 0, 4, 0, time.UTC), start.Add(off), time.Second)
+	assert.Equal(t, ClockSourceNMEA, clock.Source())
+}
@@ -106,7 +106,9 @@ func (l *LED) Run(ctx context.Context) error {
 
 			select {
 			case l.out <- l.state:
+				l.SamplePublished()
 			default:
+				l.SampleDropped()
 			}
 			vstr := "false"
 			if v {
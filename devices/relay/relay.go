@@ -102,7 +102,9 @@ func (r *Relay) Run(ctx context.Context) error {
 
 			select {
 			case r.out <- r.state:
+				r.SamplePublished()
 			default:
+				r.SampleDropped()
 			}
 			r.Emit(devices.EventInfo, "set", nil, map[string]string{"value": boolToStr(v)})
 
@@ -14,22 +14,29 @@ import (
 // VH400Config configures a VH400 volumetric water content sensor.
 //
 // The VH400 itself outputs an analog voltage; this device expects that
-// voltage to be read via an ADC (e.g. ADS1115).
+// voltage to be read via an ADC (an ADS1x15 or MCP3008).
 type VH400Config struct {
 	Name string
 
 	// ADC is an already-open analog reader (optional).
-	// If nil, Factory will be used to open an ADS1115.
+	// If nil, Factory will be used to open Chip.
 	ADC drivers.ADC
 
 	// Factory opens ADC devices (optional).
 	Factory drivers.ADCFactory
 
-	// Bus and Addr are used when opening ADS1115 via Factory.
+	// Chip names the ADC opened via Factory: drivers.ChipADS1115
+	// (default), drivers.ChipADS1015 or drivers.ChipMCP3008.
+	Chip string
+
+	// Bus and Addr are used when opening the ADC via Factory. Bus is the
+	// I2C bus of an ADS1x15 or the SPI port of an MCP3008; Addr applies
+	// to the ADS1x15 only.
 	Bus  string
 	Addr uint16
 
-	// Channel is the ADS1115 single-ended input (0-3).
+	// Channel is the single-ended input: 0-3 on an ADS1x15, 0-7 on an
+	// MCP3008.
 	Channel int
 
 	// Interval is the polling cadence.
@@ -50,7 +57,8 @@ type VH400Config struct {
 // Output units are percent VWC.
 type VH400 struct {
 	devices.Base
-	out chan float64
+	out     chan float64
+	samples chan devices.Sample[float64]
 
 	cfg VH400Config
 	adc drivers.ADC
@@ -62,25 +70,31 @@ func NewVH400(cfg VH400Config) *VH400 {
 		cfg.Buf = 16
 	}
 	return &VH400{
-		Base: devices.NewBase(cfg.Name, cfg.Buf),
-		out:  make(chan float64, cfg.Buf),
-		cfg:  cfg,
+		Base:    devices.NewBase(cfg.Name, cfg.Buf),
+		out:     make(chan float64, cfg.Buf),
+		samples: make(chan devices.Sample[float64], cfg.Buf),
+		cfg:     cfg,
 	}
 }
 
 // Out returns the VWC sample stream.
 func (v *VH400) Out() <-chan float64 { return v.out }
 
+// OutSamples returns the VWC stream stamped with the time of each read.
+func (v *VH400) OutSamples() <-chan devices.Sample[float64] { return v.samples }
+
 // Descriptor returns sensor metadata.
 func (v *VH400) Descriptor() devices.Descriptor {
 	min := 0.0
 	max := 100.0
 	attrs := map[string]string{
-		"adc":     "ads1115",
+		"adc":     v.chip(),
 		"bus":     v.cfg.Bus,
-		"addr":    fmt.Sprintf("0x%02x", v.cfg.Addr),
 		"channel": strconv.Itoa(v.cfg.Channel),
 	}
+	if v.chip() != drivers.ChipMCP3008 {
+		attrs["addr"] = fmt.Sprintf("0x%02x", v.cfg.Addr)
+	}
 	return devices.Descriptor{
 		Name:       v.Name(),
 		Kind:       "vh400",
@@ -100,13 +114,24 @@ func (v *VH400) Run(ctx context.Context) error {
 		err := errors.New("vh400: interval must be > 0")
 		v.Emit(devices.EventError, "invalid interval", err, nil)
 		close(v.out)
+		close(v.samples)
+		v.Close()
+		return err
+	}
+	channels, err := drivers.ADCChannels(v.cfg.Chip)
+	if err != nil {
+		err = fmt.Errorf("vh400: %w", err)
+		v.Emit(devices.EventError, "invalid adc", err, nil)
+		close(v.out)
+		close(v.samples)
 		v.Close()
 		return err
 	}
-	if v.cfg.Channel < 0 || v.cfg.Channel > 3 {
-		err := fmt.Errorf("vh400: invalid channel %d", v.cfg.Channel)
+	if v.cfg.Channel < 0 || v.cfg.Channel >= channels {
+		err := fmt.Errorf("vh400: invalid channel %d for %s", v.cfg.Channel, v.chip())
 		v.Emit(devices.EventError, "invalid channel", err, nil)
 		close(v.out)
+		close(v.samples)
 		v.Close()
 		return err
 	}
@@ -117,13 +142,22 @@ func (v *VH400) Run(ctx context.Context) error {
 			err := errors.New("vh400: adc and factory are both nil")
 			v.Emit(devices.EventError, "factory missing", err, nil)
 			close(v.out)
+			close(v.samples)
 			v.Close()
 			return err
 		}
-		opened, err := v.cfg.Factory.OpenADS1115(v.cfg.Bus, v.cfg.Addr)
+		opened, err := v.cfg.Factory.OpenADC(drivers.ADCConfig{
+			Chip: v.cfg.Chip,
+			Bus:  v.cfg.Bus,
+			Addr: v.cfg.Addr,
+			// The VH400 outputs up to 3V, past the ADS1x15's default
+			// ±2.048V range.
+			Gain: drivers.Gain4096,
+		})
 		if err != nil {
 			v.Emit(devices.EventError, "open adc failed", err, nil)
 			close(v.out)
+			close(v.samples)
 			v.Close()
 			return err
 		}
@@ -153,6 +187,7 @@ func (v *VH400) Run(ctx context.Context) error {
 		DropOnFull:     true,
 		Read:           read,
 		NewTicker:      v.cfg.NewTicker,
+		Samples:        v.samples,
 		SampleEventMsg: "sample",
 		SampleMeta: func(vwc float64) map[string]string {
 			return map[string]string{"vwc": fmt.Sprintf("%.2f", vwc)}
@@ -160,6 +195,14 @@ func (v *VH400) Run(ctx context.Context) error {
 	})
 }
 
+// chip returns the configured ADC chip name.
+func (v *VH400) chip() string {
+	if v.cfg.Chip == "" {
+		return drivers.ChipADS1115
+	}
+	return v.cfg.Chip
+}
+
 // vwcFromVolts converts VH400 output voltage to volumetric water content (percent).
 //
 // The piecewise linear approximations are based on Vegetronix's published curve:
@@ -2,6 +2,7 @@ package vh400
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -83,3 +84,75 @@ func TestVH400_Run_EmitInitialAndTick(t *testing.T) {
 }
 
 var _ drivers.ADC = (*fakeADC)(nil)
+
+func TestVH400_ChipSelectsChannels(t *testing.T) {
+	t.Parallel()
+
+	f := drivers.NewSimADCFactory(drivers.ConstSource(1.0))
+
+	v := NewVH400(VH400Config{
+		Name:        "soil",
+		Factory:     f,
+		Chip:        drivers.ChipMCP3008,
+		Bus:         "SPI0.0",
+		Channel:     6,
+		Interval:    time.Second,
+		EmitInitial: true,
+	})
+	d := v.Descriptor()
+	require.Equal(t, drivers.ChipMCP3008, d.Attributes["adc"])
+	require.NotContains(t, d.Attributes, "addr")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- v.Run(ctx) }()
+	require.Equal(t, 9.0, <-v.Out())
+	cancel()
+	require.NoError(t, <-errCh)
+
+	// Channel 6 does not exist on the default ADS1115.
+	v = NewVH400(VH400Config{Name: "soil", Factory: f, Channel: 6, Interval: time.Second})
+	require.ErrorContains(t, v.Run(context.Background()), "invalid channel 6 for ads1115")
+
+	v = NewVH400(VH400Config{Name: "soil", Factory: f, Chip: "lm35", Interval: time.Second})
+	require.ErrorIs(t, v.Run(context.Background()), drivers.ErrUnknownADC)
+}
+
+func TestVH400_SimADCErrorPath(t *testing.T) {
+	t.Parallel()
+
+	f := drivers.NewSimADCFactory(drivers.SequenceSource(false, 1.0, 1.3))
+	sim := f.ADC("1", 0x48)
+	sim.FailNext(0, errors.New("i2c nack"))
+
+	ft := &devices.FakeTicker{Q: make(chan time.Time, 10)}
+	v := NewVH400(VH400Config{
+		Name:        "soil",
+		Factory:     f,
+		Bus:         "1",
+		Addr:        0x48,
+		Interval:    time.Second,
+		EmitInitial: true,
+		NewTicker:   func(time.Duration) devices.Ticker { return ft },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- v.Run(ctx) }()
+
+	// The initial read fails; the poller reports it and keeps going.
+	for ev := range v.Events() {
+		if ev.Kind == devices.EventError {
+			require.ErrorContains(t, ev.Err, "i2c nack")
+			break
+		}
+	}
+	ft.Q <- time.Now()
+	require.Equal(t, 9.0, <-v.Out())
+	ft.Q <- time.Now()
+	require.Equal(t, 15.0, <-v.Out())
+	require.Equal(t, 3, sim.Reads(0))
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
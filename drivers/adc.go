@@ -1,6 +1,10 @@
 package drivers
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // ADC reads analog voltages from one or more channels.
 //
@@ -13,10 +17,88 @@ type ADC interface {
 	Close() error
 }
 
+// DiffADC is an ADC that can also measure one input against another.
+type DiffADC interface {
+	ADC
+
+	// ReadDiffVolts returns the voltage of channel plus relative to
+	// channel minus. Chips only support some pairs.
+	ReadDiffVolts(ctx context.Context, plus, minus int) (float64, error)
+}
+
+// ADC chips known to ADCConfig.
+const (
+	ChipADS1015 = "ads1015" // 4 channels, 12-bit, I2C
+	ChipADS1115 = "ads1115" // 4 channels, 16-bit, I2C
+	ChipMCP3008 = "mcp3008" // 8 channels, 10-bit, SPI
+)
+
+// DefaultADCVRef is the reference voltage used when ADCConfig.VRef is 0.
+const DefaultADCVRef = 3.3
+
+// ErrUnknownADC is returned for an ADCConfig.Chip no driver knows.
+var ErrUnknownADC = errors.New("unknown adc chip")
+
+// ADCConfig selects an ADC chip and where it is attached.
+type ADCConfig struct {
+	// Chip is ChipADS1115 (default), ChipADS1015 or ChipMCP3008.
+	Chip string
+
+	// Bus is the I2C bus for I2C chips ("1" on a Pi), or the SPI port
+	// for SPI chips ("SPI0.0"). "" picks the first available.
+	Bus string
+
+	// Addr is the 7-bit I2C address. Default 0x48. Ignored for SPI chips.
+	Addr uint16
+
+	// VRef is the reference voltage in volts of chips that take one
+	// (MCP3008). Default DefaultADCVRef.
+	VRef float64
+
+	// Gain, DataRate and Continuous configure an ADS1x15; see
+	// ADS1x15Opts. Zero values select the chip defaults.
+	Gain       ADS1x15Gain
+	DataRate   int
+	Continuous bool
+}
+
 // ADCFactory opens ADC devices.
 //
 // This mirrors the GPIO Factory pattern used elsewhere in the repo.
-// For now, we only standardize ADS1115.
 type ADCFactory interface {
-	OpenADS1115(bus string, addr uint16) (ADC, error)
+	OpenADC(cfg ADCConfig) (ADC, error)
+}
+
+// ADCChannels returns the number of single-ended inputs of chip ("" is
+// ChipADS1115).
+func ADCChannels(chip string) (int, error) {
+	switch chip {
+	case "", ChipADS1115, ChipADS1015:
+		return 4, nil
+	case ChipMCP3008:
+		return 8, nil
+	}
+	return 0, fmt.Errorf("%w %q (want one of %v)", ErrUnknownADC, chip, ADCChips())
+}
+
+// ADCChips returns the chip names ADCConfig accepts.
+func ADCChips() []string {
+	return []string{ChipADS1015, ChipADS1115, ChipMCP3008}
+}
+
+// withDefaults fills in the defaults and checks the chip.
+func (c ADCConfig) withDefaults() (ADCConfig, error) {
+	if c.Chip == "" {
+		c.Chip = ChipADS1115
+	}
+	if _, err := ADCChannels(c.Chip); err != nil {
+		return c, err
+	}
+	if c.Chip != ChipMCP3008 && c.Addr == 0 {
+		c.Addr = 0x48
+	}
+	if c.VRef == 0 {
+		c.VRef = DefaultADCVRef
+	}
+	return c, nil
 }
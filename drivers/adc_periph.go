@@ -0,0 +1,45 @@
+package drivers
+
+import "fmt"
+
+// PeriphADCFactory opens ADC devices on periph-backed buses: an ADS1x15
+// on an I2C bus, or an MCP3008 on an SPI port. It builds everywhere; off
+// Linux, or without the bus devices, opening fails.
+type PeriphADCFactory struct{}
+
+func (PeriphADCFactory) OpenADC(cfg ADCConfig) (ADC, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Chip == ChipMCP3008 {
+		port, err := PeriphSPIFactory{}.OpenSPI(SPIConfig{Port: cfg.Bus, MaxHz: MCP3008MaxHz})
+		if err != nil {
+			return nil, fmt.Errorf("mcp3008: %w", err)
+		}
+		return NewMCP3008(port, MCP3008Opts{VRef: cfg.VRef}), nil
+	}
+	return openADS1x15(PeriphI2CFactory{}, cfg)
+}
+
+// openADS1x15 opens cfg.Bus with f and the ADS1x15 on it.
+func openADS1x15(f I2CFactory, cfg ADCConfig) (ADC, error) {
+	bus, err := f.OpenI2C(cfg.Bus)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Chip, err)
+	}
+	adc, err := NewADS1x15(bus, ADS1x15Opts{
+		Chip:       cfg.Chip,
+		Addr:       cfg.Addr,
+		Gain:       cfg.Gain,
+		DataRate:   cfg.DataRate,
+		Continuous: cfg.Continuous,
+	})
+	if err != nil {
+		_ = bus.Close()
+		return nil, err
+	}
+	return adc, nil
+}
+
+var _ ADCFactory = PeriphADCFactory{}
@@ -0,0 +1,261 @@
+package drivers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrSimADC is the error injected by SimADC.FailNext and FailEvery when
+// given a nil error.
+var ErrSimADC = errors.New("sim adc: injected read failure")
+
+// ADCSource drives one channel of a SimADC. Volts is called on every
+// read with the time since the SimADC was created.
+type ADCSource interface {
+	Volts(elapsed time.Duration) (float64, error)
+}
+
+// ADCSourceFunc adapts a callback to ADCSource.
+type ADCSourceFunc func(elapsed time.Duration) (float64, error)
+
+func (f ADCSourceFunc) Volts(elapsed time.Duration) (float64, error) { return f(elapsed) }
+
+// ConstSource always reads v.
+func ConstSource(v float64) ADCSource {
+	return ADCSourceFunc(func(time.Duration) (float64, error) { return v, nil })
+}
+
+// SequenceSource reads vs in turn, one value per read. At the end it
+// starts over if loop is set, else holds the last value.
+func SequenceSource(loop bool, vs ...float64) ADCSource {
+	if len(vs) == 0 {
+		vs = []float64{0}
+	}
+	var mu sync.Mutex
+	i := 0
+	return ADCSourceFunc(func(time.Duration) (float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		v := vs[i]
+		switch {
+		case i < len(vs)-1:
+			i++
+		case loop:
+			i = 0
+		}
+		return v, nil
+	})
+}
+
+// SineSource reads a sine wave around offset, peaking at offset ±
+// amplitude, that repeats every period.
+func SineSource(offset, amplitude float64, period time.Duration) ADCSource {
+	return ADCSourceFunc(func(elapsed time.Duration) (float64, error) {
+		return offset + amplitude*math.Sin(2*math.Pi*phase(elapsed, period)), nil
+	})
+}
+
+// RampSource reads a sawtooth that climbs from from to to over each
+// period, then jumps back.
+func RampSource(from, to float64, period time.Duration) ADCSource {
+	return ADCSourceFunc(func(elapsed time.Duration) (float64, error) {
+		return from + (to-from)*phase(elapsed, period), nil
+	})
+}
+
+// phase returns how far through period elapsed is, in [0, 1).
+func phase(elapsed, period time.Duration) float64 {
+	if period <= 0 {
+		return 0
+	}
+	return float64(elapsed%period) / float64(period)
+}
+
+// NoiseSource adds Gaussian noise with standard deviation stddev to
+// src. The noise is repeatable for a given seed.
+func NoiseSource(src ADCSource, stddev float64, seed int64) ADCSource {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(seed))
+	return ADCSourceFunc(func(elapsed time.Duration) (float64, error) {
+		v, err := src.Volts(elapsed)
+		if err != nil {
+			return 0, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return v + rng.NormFloat64()*stddev, nil
+	})
+}
+
+// SimADCFactory is a portable ADCFactory whose ADCs are SimADCs, created
+// on first use and shared by every OpenADC of the same bus and address,
+// so a test or demo can drive the channels a device reads.
+type SimADCFactory struct {
+	mu   sync.Mutex
+	adcs map[simADCKey]*SimADC
+	def  ADCSource
+
+	// Now is the clock sources are driven by. Default time.Now.
+	Now func() time.Time
+}
+
+type simADCKey struct {
+	bus  string
+	addr uint16
+}
+
+// NewSimADCFactory constructs a SimADCFactory whose channels read def
+// until given a source (nil reads 0V).
+func NewSimADCFactory(def ADCSource) *SimADCFactory {
+	if def == nil {
+		def = ConstSource(0)
+	}
+	return &SimADCFactory{adcs: map[simADCKey]*SimADC{}, def: def}
+}
+
+// ADC returns the ADC at bus and addr, creating it. Use addr 0 for SPI
+// chips.
+func (f *SimADCFactory) ADC(bus string, addr uint16) *SimADC {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := simADCKey{bus, addr}
+	a, ok := f.adcs[k]
+	if !ok {
+		now := f.Now
+		if now == nil {
+			now = time.Now
+		}
+		a = &SimADC{
+			name:    fmt.Sprintf("%s/0x%02x", bus, addr),
+			now:     now,
+			start:   now(),
+			def:     f.def,
+			sources: map[int]ADCSource{},
+			faults:  map[int][]error{},
+			reads:   map[int]int{},
+		}
+		f.adcs[k] = a
+	}
+	return a
+}
+
+// OpenADC returns the shared ADC at cfg.Bus and cfg.Addr, with as many
+// channels as cfg.Chip has.
+func (f *SimADCFactory) OpenADC(cfg ADCConfig) (ADC, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	n, _ := ADCChannels(cfg.Chip)
+	a := f.ADC(cfg.Bus, cfg.Addr)
+	a.mu.Lock()
+	a.channels = n
+	a.mu.Unlock()
+	return a, nil
+}
+
+// SimADC is a simulated ADC. Each channel reads its ADCSource; reads can
+// be slowed down, and made to fail, to exercise error paths.
+type SimADC struct {
+	name  string
+	now   func() time.Time
+	start time.Time
+
+	mu        sync.Mutex
+	channels  int // 0 until opened: any channel
+	def       ADCSource
+	sources   map[int]ADCSource
+	latency   time.Duration
+	faults    map[int][]error
+	failEvery int
+	failErr   error
+	reads     map[int]int
+	counted   int // reads since FailEvery
+}
+
+// Set makes channel ch read src.
+func (a *SimADC) Set(ch int, src ADCSource) *SimADC {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sources[ch] = src
+	return a
+}
+
+// SetLatency delays every read by d, or until the read's context ends.
+func (a *SimADC) SetLatency(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latency = d
+}
+
+// FailNext makes the next reads of channel ch return errs, one per read.
+func (a *SimADC) FailNext(ch int, errs ...error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, err := range errs {
+		a.faults[ch] = append(a.faults[ch], cmp.Or(err, ErrSimADC))
+	}
+}
+
+// FailEvery makes every nth read from now on, counted across channels,
+// return err. n <= 0 turns it off.
+func (a *SimADC) FailEvery(n int, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failEvery, a.failErr, a.counted = n, cmp.Or(err, ErrSimADC), 0
+}
+
+// Reads returns the number of reads of channel ch, failed ones included.
+func (a *SimADC) Reads(ch int) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.reads[ch]
+}
+
+func (a *SimADC) ReadVolts(ctx context.Context, channel int) (float64, error) {
+	a.mu.Lock()
+	if channel < 0 || (a.channels > 0 && channel >= a.channels) {
+		a.mu.Unlock()
+		return 0, fmt.Errorf("sim adc %s: invalid channel %d", a.name, channel)
+	}
+	a.reads[channel]++
+	a.counted++
+	latency := a.latency
+	var fault error
+	if q := a.faults[channel]; len(q) > 0 {
+		fault, a.faults[channel] = q[0], q[1:]
+	} else if a.failEvery > 0 && a.counted%a.failEvery == 0 {
+		fault = a.failErr
+	}
+	src, ok := a.sources[channel]
+	if !ok {
+		src = a.def
+	}
+	a.mu.Unlock()
+
+	if latency > 0 {
+		if err := sleepCtx(ctx, latency); err != nil {
+			return 0, err
+		}
+	} else if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if fault != nil {
+		return 0, fault
+	}
+	return src.Volts(a.now().Sub(a.start))
+}
+
+// Close is a no-op: the ADC outlives any one user, like a SimI2CBus.
+func (a *SimADC) Close() error { return nil }
+
+func (a *SimADC) String() string { return "sim-adc-" + a.name }
+
+var _ ADCFactory = (*SimADCFactory)(nil)
+var _ ADC = (*SimADC)(nil)
@@ -0,0 +1,163 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable clock for SimADCFactory.Now.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func read(t *testing.T, a ADC, ch int) float64 {
+	t.Helper()
+	v, err := a.ReadVolts(context.Background(), ch)
+	require.NoError(t, err)
+	return v
+}
+
+func TestSimADCSources(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	f := NewSimADCFactory(nil)
+	f.Now = clock.Now
+
+	var calls int
+	f.ADC("1", 0x48).
+		Set(0, ConstSource(1.5)).
+		Set(1, SineSource(1.0, 0.5, 4*time.Second)).
+		Set(2, RampSource(0, 3.0, 3*time.Second)).
+		Set(3, ADCSourceFunc(func(elapsed time.Duration) (float64, error) {
+			calls++
+			return elapsed.Seconds(), nil
+		}))
+
+	adc, err := f.OpenADC(ADCConfig{Bus: "1"})
+	require.NoError(t, err)
+	defer adc.Close()
+
+	assert.Equal(t, 1.5, read(t, adc, 0))
+	assert.InDelta(t, 1.0, read(t, adc, 1), 1e-9)
+	assert.Equal(t, 0.0, read(t, adc, 2))
+
+	clock.Advance(time.Second)
+	assert.InDelta(t, 1.5, read(t, adc, 1), 1e-9, "sine peak")
+	assert.InDelta(t, 1.0, read(t, adc, 2), 1e-9)
+	assert.Equal(t, 1.0, read(t, adc, 3))
+
+	clock.Advance(2 * time.Second)
+	assert.InDelta(t, 0.5, read(t, adc, 1), 1e-9, "sine trough")
+	assert.InDelta(t, 0.0, read(t, adc, 2), 1e-9, "ramp wraps")
+	assert.Equal(t, 1, calls)
+
+	// Opening the same bus and address shares the ADC.
+	again, err := f.OpenADC(ADCConfig{Bus: "1", Addr: 0x48})
+	require.NoError(t, err)
+	assert.Same(t, adc, again)
+}
+
+func TestSequenceAndNoiseSources(t *testing.T) {
+	t.Parallel()
+
+	held := SequenceSource(false, 1, 2, 3)
+	looped := SequenceSource(true, 1, 2)
+	var got, gotLoop []float64
+	for range 5 {
+		v, _ := held.Volts(0)
+		got = append(got, v)
+		v, _ = looped.Volts(0)
+		gotLoop = append(gotLoop, v)
+	}
+	assert.Equal(t, []float64{1, 2, 3, 3, 3}, got)
+	assert.Equal(t, []float64{1, 2, 1, 2, 1}, gotLoop)
+
+	// Same seed, same noise; the mean stays near the source.
+	a, b := NoiseSource(ConstSource(2.0), 0.1, 42), NoiseSource(ConstSource(2.0), 0.1, 42)
+	var sum float64
+	for range 1000 {
+		va, _ := a.Volts(0)
+		vb, _ := b.Volts(0)
+		require.Equal(t, va, vb)
+		sum += va
+	}
+	assert.InDelta(t, 2.0, sum/1000, 0.02)
+
+	boom := errors.New("boom")
+	_, err := NoiseSource(ADCSourceFunc(func(time.Duration) (float64, error) { return 0, boom }), 1, 1).Volts(0)
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestSimADCFaults(t *testing.T) {
+	t.Parallel()
+
+	f := NewSimADCFactory(ConstSource(1.0))
+	adc, err := f.OpenADC(ADCConfig{Bus: "1"})
+	require.NoError(t, err)
+	sim := f.ADC("1", 0x48)
+	ctx := context.Background()
+
+	boom := errors.New("boom")
+	sim.FailNext(0, boom, nil)
+	_, err = adc.ReadVolts(ctx, 0)
+	assert.ErrorIs(t, err, boom)
+	_, err = adc.ReadVolts(ctx, 0)
+	assert.ErrorIs(t, err, ErrSimADC)
+	assert.Equal(t, 1.0, read(t, adc, 0))
+	assert.Equal(t, 3, sim.Reads(0))
+
+	sim.FailEvery(2, nil)
+	assert.Equal(t, 1.0, read(t, adc, 1))
+	_, err = adc.ReadVolts(ctx, 1)
+	assert.ErrorIs(t, err, ErrSimADC)
+	sim.FailEvery(0, nil)
+	assert.Equal(t, 1.0, read(t, adc, 1))
+
+	_, err = adc.ReadVolts(ctx, 4)
+	assert.ErrorContains(t, err, "invalid channel 4")
+
+	mcp, err := f.OpenADC(ADCConfig{Chip: ChipMCP3008, Bus: "SPI0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, read(t, mcp, 7))
+
+	_, err = f.OpenADC(ADCConfig{Chip: "lm35"})
+	assert.ErrorIs(t, err, ErrUnknownADC)
+}
+
+func TestSimADCLatency(t *testing.T) {
+	t.Parallel()
+
+	f := NewSimADCFactory(ConstSource(1.0))
+	adc, err := f.OpenADC(ADCConfig{})
+	require.NoError(t, err)
+	f.ADC("", 0x48).SetLatency(20 * time.Millisecond)
+
+	start := time.Now()
+	assert.Equal(t, 1.0, read(t, adc, 0))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = adc.ReadVolts(ctx, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,275 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ADS1x15 registers and config fields (datasheet section 8.6).
+const (
+	adsRegConversion = 0x00
+	adsRegConfig     = 0x01
+
+	adsConfigOS         = 1 << 15 // write: start a conversion; read: idle
+	adsConfigMuxShift   = 12
+	adsConfigPGAShift   = 9
+	adsConfigSingleShot = 1 << 8
+	adsConfigDRShift    = 5
+	adsConfigCompOff    = 0x0003 // comparator disabled, ALERT/RDY high-Z
+)
+
+// ADS1x15Gain is the programmable gain amplifier setting, named by the
+// full-scale range in millivolts: inputs read from -range to +range.
+type ADS1x15Gain int
+
+const (
+	Gain6144 ADS1x15Gain = 6144
+	Gain4096 ADS1x15Gain = 4096
+	Gain2048 ADS1x15Gain = 2048 // power-on default
+	Gain1024 ADS1x15Gain = 1024
+	Gain512  ADS1x15Gain = 512
+	Gain256  ADS1x15Gain = 256
+)
+
+// adsGains lists the gains in PGA field order.
+var adsGains = []ADS1x15Gain{Gain6144, Gain4096, Gain2048, Gain1024, Gain512, Gain256}
+
+// adsRates lists the data rates in samples per second, in DR field order.
+var adsRates = map[string][]int{
+	ChipADS1015: {128, 250, 490, 920, 1600, 2400, 3300},
+	ChipADS1115: {8, 16, 32, 64, 128, 250, 475, 860},
+}
+
+// adsDefaultRate is the power-on data rate of each chip.
+var adsDefaultRate = map[string]int{ChipADS1015: 1600, ChipADS1115: 128}
+
+// ADS1x15Opts configures an ADS1015 or ADS1115.
+type ADS1x15Opts struct {
+	// Chip is ChipADS1115 (default, 16-bit) or ChipADS1015 (12-bit).
+	Chip string
+
+	// Addr is the 7-bit I2C address, set by the ADDR pin: 0x48 (GND,
+	// default), 0x49 (VDD), 0x4a (SDA) or 0x4b (SCL).
+	Addr uint16
+
+	// Gain sets the full-scale range. Default Gain2048. Inputs must stay
+	// within the supply rails whatever the range.
+	Gain ADS1x15Gain
+
+	// DataRate is samples per second: 8-860 on the ADS1115, 128-3300 on
+	// the ADS1015. Default is the chip's power-on rate, 128 or 1600.
+	DataRate int
+
+	// Continuous keeps the chip converting the last input read instead
+	// of starting a single conversion per read. Reads of the same input
+	// return at once; switching input waits for a fresh conversion.
+	Continuous bool
+}
+
+// ADS1x15 is a 4-channel ADS1015/ADS1115 ADC on an I2CBus.
+//
+// Channels 0-3 are read single-ended against GND. Differential reads
+// measure the pairs 0-1, 0-3, 1-3 and 2-3, and are signed.
+type ADS1x15 struct {
+	mu   sync.Mutex
+	dev  *I2CDevice
+	opts ADS1x15Opts
+	pga  uint16
+	dr   uint16
+	last uint16 // mux of the running continuous conversion, or adsNoMux
+}
+
+const adsNoMux = 0xffff
+
+// The input multiplexer settings for each single-ended channel and each
+// differential pair.
+var (
+	adsSingleMux = [4]uint16{0b100, 0b101, 0b110, 0b111}
+	adsDiffMux   = map[[2]int]uint16{{0, 1}: 0b000, {0, 3}: 0b001, {1, 3}: 0b010, {2, 3}: 0b011}
+)
+
+// NewADS1x15 returns the ADS1015 or ADS1115 at opts.Addr on bus, after
+// checking the options. It does not talk to the chip until the first
+// read. Close closes bus.
+func NewADS1x15(bus I2CBus, opts ADS1x15Opts) (*ADS1x15, error) {
+	if opts.Chip == "" {
+		opts.Chip = ChipADS1115
+	}
+	rates, ok := adsRates[opts.Chip]
+	if !ok {
+		return nil, fmt.Errorf("ads1x15: %w %q (want %s or %s)", ErrUnknownADC, opts.Chip, ChipADS1015, ChipADS1115)
+	}
+	if opts.Addr == 0 {
+		opts.Addr = 0x48
+	}
+	if opts.Addr < 0x48 || opts.Addr > 0x4b {
+		return nil, fmt.Errorf("%s: invalid address 0x%02x (want 0x48-0x4b)", opts.Chip, opts.Addr)
+	}
+	if opts.Gain == 0 {
+		opts.Gain = Gain2048
+	}
+	if opts.DataRate == 0 {
+		opts.DataRate = adsDefaultRate[opts.Chip]
+	}
+
+	pga := slices.Index(adsGains, opts.Gain)
+	if pga < 0 {
+		return nil, fmt.Errorf("%s: invalid gain %d (want %v mV)", opts.Chip, opts.Gain, adsGains)
+	}
+	dr := slices.Index(rates, opts.DataRate)
+	if dr < 0 {
+		return nil, fmt.Errorf("%s: invalid data rate %d (want %v SPS)", opts.Chip, opts.DataRate, rates)
+	}
+	return &ADS1x15{
+		dev:  NewI2CDevice(bus, opts.Addr),
+		opts: opts,
+		pga:  uint16(pga),
+		dr:   uint16(dr),
+		last: adsNoMux,
+	}, nil
+}
+
+// ReadRaw returns the conversion of single-ended channel 0-3: a signed
+// 16-bit code on the ADS1115, 12-bit on the ADS1015.
+func (a *ADS1x15) ReadRaw(ctx context.Context, channel int) (int16, error) {
+	if channel < 0 || channel > 3 {
+		return 0, fmt.Errorf("%s: invalid channel %d", a.opts.Chip, channel)
+	}
+	return a.convert(ctx, adsSingleMux[channel])
+}
+
+// ReadVolts reads single-ended channel 0-3.
+func (a *ADS1x15) ReadVolts(ctx context.Context, channel int) (float64, error) {
+	code, err := a.ReadRaw(ctx, channel)
+	return a.volts(code), err
+}
+
+// ReadDiffRaw returns the signed conversion of plus relative to minus.
+func (a *ADS1x15) ReadDiffRaw(ctx context.Context, plus, minus int) (int16, error) {
+	mux, ok := adsDiffMux[[2]int{plus, minus}]
+	if !ok {
+		return 0, fmt.Errorf("%s: invalid differential pair %d-%d (want 0-1, 0-3, 1-3 or 2-3)", a.opts.Chip, plus, minus)
+	}
+	return a.convert(ctx, mux)
+}
+
+// ReadDiffVolts reads plus relative to minus.
+func (a *ADS1x15) ReadDiffVolts(ctx context.Context, plus, minus int) (float64, error) {
+	code, err := a.ReadDiffRaw(ctx, plus, minus)
+	return a.volts(code), err
+}
+
+// Opts returns the options in effect, defaults filled in.
+func (a *ADS1x15) Opts() ADS1x15Opts { return a.opts }
+
+func (a *ADS1x15) convert(ctx context.Context, mux uint16) (int16, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.opts.Continuous {
+		if err := a.dev.WriteReg16(adsRegConfig, adsConfigOS|a.config(mux)|adsConfigSingleShot); err != nil {
+			return 0, fmt.Errorf("%s: start conversion: %w", a.opts.Chip, err)
+		}
+		if err := a.waitReady(ctx); err != nil {
+			return 0, err
+		}
+	} else if mux != a.last {
+		if err := a.dev.WriteReg16(adsRegConfig, a.config(mux)); err != nil {
+			a.last = adsNoMux
+			return 0, fmt.Errorf("%s: configure: %w", a.opts.Chip, err)
+		}
+		a.last = mux
+		// There is no ready flag in continuous mode; the conversion in
+		// flight when the mux changed may straddle it, so wait for two.
+		if err := sleepCtx(ctx, 2*a.period()); err != nil {
+			return 0, err
+		}
+	}
+
+	v, err := a.dev.ReadReg16(adsRegConversion)
+	if err != nil {
+		return 0, fmt.Errorf("%s: read conversion: %w", a.opts.Chip, err)
+	}
+	code := int16(v)
+	if a.opts.Chip == ChipADS1015 {
+		code >>= 4 // 12-bit result, left-justified
+	}
+	return code, nil
+}
+
+// config returns the config register for mux, less the mode and OS bits.
+func (a *ADS1x15) config(mux uint16) uint16 {
+	return mux<<adsConfigMuxShift | a.pga<<adsConfigPGAShift | a.dr<<adsConfigDRShift | adsConfigCompOff
+}
+
+// waitReady polls the OS bit until the single conversion completes.
+func (a *ADS1x15) waitReady(ctx context.Context) error {
+	period := a.period()
+	deadline := time.Now().Add(10*period + 10*time.Millisecond)
+	for {
+		cfg, err := a.dev.ReadReg16(adsRegConfig)
+		if err != nil {
+			return fmt.Errorf("%s: poll conversion: %w", a.opts.Chip, err)
+		}
+		if cfg&adsConfigOS != 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s: %w", a.opts.Chip, errADSTimeout)
+		}
+		if err := sleepCtx(ctx, max(period/4, 100*time.Microsecond)); err != nil {
+			return err
+		}
+	}
+}
+
+var errADSTimeout = errors.New("conversion timed out")
+
+// period is the time one conversion takes at the configured data rate.
+func (a *ADS1x15) period() time.Duration {
+	return time.Second / time.Duration(a.opts.DataRate)
+}
+
+func (a *ADS1x15) volts(code int16) float64 {
+	full := float64(int(1) << 15)
+	if a.opts.Chip == ChipADS1015 {
+		full = 1 << 11
+	}
+	return float64(code) * float64(a.opts.Gain) / 1000 / full
+}
+
+// Close powers the chip down to single-shot mode and closes the bus.
+func (a *ADS1x15) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var first error
+	if a.opts.Continuous && a.last != adsNoMux {
+		first = a.dev.WriteReg16(adsRegConfig, a.config(a.last)|adsConfigSingleShot)
+	}
+	if err := a.dev.Bus.Close(); err != nil && first == nil {
+		first = err
+	}
+	return first
+}
+
+// sleepCtx sleeps for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var _ DiffADC = (*ADS1x15)(nil)
@@ -0,0 +1,324 @@
+package drivers
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adsModel simulates an ADS1x15 on a RegisterMap16: writing the config
+// register with OS set starts a conversion of the inputs, and in
+// continuous mode the conversion register tracks them.
+type adsModel struct {
+	chip string
+	regs *RegisterMap
+
+	mu      sync.Mutex
+	in      [4]float64 // volts on AIN0-AIN3
+	configs []uint16   // every config written
+	busy    int        // polls that report a conversion in progress; -1 forever
+}
+
+func newADSModel(t *testing.T, chip string, bus *SimI2CBus, addr uint16) *adsModel {
+	t.Helper()
+	m := &adsModel{chip: chip, regs: NewRegisterMap16()}
+	m.regs.Set(adsRegConfig, 0x8583)
+	m.regs.OnWrite(adsRegConfig, func(_ uint8, v uint16) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.configs = append(m.configs, v)
+		if v&adsConfigOS != 0 {
+			m.regs.Set(adsRegConversion, m.convert(v))
+		}
+	})
+	m.regs.OnRead(adsRegConfig, func(uint8) uint16 {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		v := m.regs.Get(adsRegConfig)
+		if m.busy != 0 {
+			m.busy--
+			return v &^ adsConfigOS
+		}
+		return v | adsConfigOS
+	})
+	m.regs.OnRead(adsRegConversion, func(uint8) uint16 {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if cfg := m.regs.Get(adsRegConfig); cfg&adsConfigSingleShot == 0 {
+			return m.convert(cfg)
+		}
+		return m.regs.Get(adsRegConversion)
+	})
+	require.NoError(t, bus.Attach(addr, m.regs))
+	return m
+}
+
+// convert returns the conversion register for config cfg. Caller holds mu.
+func (m *adsModel) convert(cfg uint16) uint16 {
+	var plus, minus float64
+	switch mux := cfg >> adsConfigMuxShift & 7; mux {
+	case 0b000:
+		plus, minus = m.in[0], m.in[1]
+	case 0b001:
+		plus, minus = m.in[0], m.in[3]
+	case 0b010:
+		plus, minus = m.in[1], m.in[3]
+	case 0b011:
+		plus, minus = m.in[2], m.in[3]
+	default:
+		plus = m.in[mux-0b100]
+	}
+	pga := min(int(cfg>>adsConfigPGAShift&7), len(adsGains)-1)
+	full := float64(adsGains[pga]) / 1000
+
+	bits := 16
+	if m.chip == ChipADS1015 {
+		bits = 12
+	}
+	top := float64(int(1) << (bits - 1))
+	code := math.Round((plus - minus) / full * top)
+	code = max(-top, min(top-1, code))
+	return uint16(int16(code) << (16 - bits))
+}
+
+func (m *adsModel) set(ch int, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.in[ch] = v
+}
+
+func (m *adsModel) written() []uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]uint16(nil), m.configs...)
+}
+
+func TestADS1115SingleEnded(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1115, bus, 0x48)
+	model.in = [4]float64{1.0, 0.5, 2.0, 0}
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{})
+	require.NoError(t, err)
+	assert.Equal(t, ADS1x15Opts{Chip: ChipADS1115, Addr: 0x48, Gain: Gain2048, DataRate: 128}, a.Opts())
+	ctx := context.Background()
+
+	code, err := a.ReadRaw(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int16(16000), code)
+	assert.Equal(t, []uint16{0xc583}, model.written(), "OS, AIN0/GND, ±2.048V, single-shot, 128SPS, comparator off")
+
+	for ch, want := range []float64{1.0, 0.5, 2.0, 0} {
+		v, err := a.ReadVolts(ctx, ch)
+		require.NoError(t, err)
+		assert.InDelta(t, want, v, 1e-4, "channel %d", ch)
+	}
+
+	_, err = a.ReadVolts(ctx, 4)
+	assert.ErrorContains(t, err, "invalid channel 4")
+}
+
+func TestADS1115Gain(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1115, bus, 0x48)
+	model.in[0] = 3.0
+	ctx := context.Background()
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{Gain: Gain4096})
+	require.NoError(t, err)
+	v, err := a.ReadVolts(ctx, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, v, 1e-3)
+	assert.Equal(t, uint16(0b001), model.written()[0]>>adsConfigPGAShift&7)
+
+	// Beyond the range the reading saturates.
+	a, err = NewADS1x15(bus, ADS1x15Opts{Gain: Gain256})
+	require.NoError(t, err)
+	code, err := a.ReadRaw(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int16(math.MaxInt16), code)
+
+	for _, g := range adsGains {
+		_, err := NewADS1x15(bus, ADS1x15Opts{Gain: g})
+		assert.NoError(t, err, g)
+	}
+	_, err = NewADS1x15(bus, ADS1x15Opts{Gain: 5000})
+	assert.ErrorContains(t, err, "invalid gain 5000")
+}
+
+func TestADS1115Differential(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1115, bus, 0x48)
+	model.in = [4]float64{1.0, 1.5, 0.25, 0.75}
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		plus, minus int
+		mux         uint16
+		want        float64
+	}{
+		{0, 1, 0b000, -0.5},
+		{0, 3, 0b001, 0.25},
+		{1, 3, 0b010, 0.75},
+		{2, 3, 0b011, -0.5},
+	} {
+		v, err := a.ReadDiffVolts(ctx, c.plus, c.minus)
+		require.NoError(t, err)
+		assert.InDelta(t, c.want, v, 1e-4, "%d-%d", c.plus, c.minus)
+		cfg := model.written()
+		assert.Equal(t, c.mux, cfg[len(cfg)-1]>>adsConfigMuxShift&7)
+	}
+
+	for _, pair := range [][2]int{{1, 0}, {0, 2}, {1, 2}, {3, 3}} {
+		_, err := a.ReadDiffVolts(ctx, pair[0], pair[1])
+		assert.ErrorContains(t, err, "invalid differential pair", pair)
+	}
+}
+
+func TestADS1015(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1015, bus, 0x4a)
+	model.in = [4]float64{1.0, 0, 0, 2.0}
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{Chip: ChipADS1015, Addr: 0x4a, DataRate: 3300})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	code, err := a.ReadRaw(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int16(1000), code, "12-bit")
+	assert.Equal(t, uint16(6), model.written()[0]>>adsConfigDRShift&7, "3300 SPS")
+
+	v, err := a.ReadDiffVolts(ctx, 0, 3)
+	require.NoError(t, err)
+	assert.InDelta(t, -1.0, v, 2e-3)
+
+	a, err = NewADS1x15(bus, ADS1x15Opts{Chip: ChipADS1015})
+	require.NoError(t, err)
+	assert.Equal(t, 1600, a.Opts().DataRate)
+}
+
+func TestADS1x15Options(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	for _, addr := range []uint16{0x48, 0x49, 0x4a, 0x4b} {
+		newADSModel(t, ChipADS1115, bus, addr).set(0, float64(addr-0x47)/10)
+		a, err := NewADS1x15(bus, ADS1x15Opts{Addr: addr})
+		require.NoError(t, err)
+		v, err := a.ReadVolts(context.Background(), 0)
+		require.NoError(t, err)
+		assert.InDelta(t, float64(addr-0x47)/10, v, 1e-4, "0x%02x", addr)
+	}
+
+	for _, c := range []struct {
+		opts ADS1x15Opts
+		want string
+	}{
+		{ADS1x15Opts{Addr: 0x47}, "invalid address 0x47"},
+		{ADS1x15Opts{Addr: 0x4c}, "invalid address 0x4c"},
+		{ADS1x15Opts{DataRate: 1600}, "invalid data rate 1600"},
+		{ADS1x15Opts{Chip: ChipADS1015, DataRate: 860}, "invalid data rate 860"},
+		{ADS1x15Opts{Chip: ChipMCP3008}, "unknown adc chip"},
+	} {
+		_, err := NewADS1x15(bus, c.opts)
+		assert.ErrorContains(t, err, c.want)
+	}
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{Addr: 0x48})
+	require.NoError(t, err)
+	bus.Detach(0x48)
+	_, err = a.ReadVolts(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrI2CNoDevice)
+}
+
+func TestADS1115Continuous(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1115, bus, 0x48)
+	model.in = [4]float64{1.0, 0.5, 0, 0}
+
+	a, err := NewADS1x15(bus, ADS1x15Opts{DataRate: 860, Continuous: true})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	v, err := a.ReadVolts(ctx, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, v, 1e-4)
+	model.set(0, 1.25)
+	v, err = a.ReadVolts(ctx, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.25, v, 1e-4, "tracks the input")
+	require.Len(t, model.written(), 1, "same input: no reconfiguration")
+	assert.Zero(t, model.written()[0]&(adsConfigOS|adsConfigSingleShot), "continuous mode")
+
+	v, err = a.ReadVolts(ctx, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, v, 1e-4)
+	require.Len(t, model.written(), 2)
+
+	require.NoError(t, a.Close())
+	cfg := model.written()
+	assert.NotZero(t, cfg[len(cfg)-1]&adsConfigSingleShot, "Close stops converting")
+}
+
+func TestADS1115ConversionWait(t *testing.T) {
+	t.Parallel()
+
+	bus := NewSimI2CBus("1")
+	model := newADSModel(t, ChipADS1115, bus, 0x48)
+	model.in[0] = 1.0
+	a, err := NewADS1x15(bus, ADS1x15Opts{DataRate: 860})
+	require.NoError(t, err)
+
+	model.busy = 3
+	v, err := a.ReadVolts(context.Background(), 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, v, 1e-4)
+
+	model.busy = -1
+	start := time.Now()
+	_, err = a.ReadVolts(context.Background(), 0)
+	assert.ErrorIs(t, err, errADSTimeout)
+	assert.Less(t, time.Since(start), time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = a.ReadVolts(ctx, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestOpenADS1x15(t *testing.T) {
+	t.Parallel()
+
+	f := NewSimI2CFactory()
+	newADSModel(t, ChipADS1115, f.Bus("1"), 0x49).set(2, 0.8)
+
+	adc, err := openADS1x15(f, ADCConfig{Chip: ChipADS1115, Bus: "1", Addr: 0x49, Gain: Gain1024, DataRate: 250})
+	require.NoError(t, err)
+	defer adc.Close()
+	v, err := adc.ReadVolts(context.Background(), 2)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, v, 1e-4)
+	assert.Implements(t, (*DiffADC)(nil), adc)
+
+	_, err = openADS1x15(f, ADCConfig{Chip: ChipADS1115, Bus: "1", Addr: 0x50})
+	assert.ErrorContains(t, err, "invalid address 0x50")
+}
@@ -0,0 +1,284 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Backend names registered by this package.
+const (
+	BackendGPIOCDev = "gpiocdev"
+	BackendVPIO     = "vpio"
+	BackendPeriph   = "periph"
+	BackendSerial   = "serial"
+	BackendMock     = "mock"
+
+	// BackendAuto (or "") selects the default backend, see Resolve.
+	BackendAuto = "auto"
+)
+
+// EnvBackend is the environment variable that selects the default
+// backend, e.g. DEVICES_BACKEND=mock.
+const EnvBackend = "DEVICES_BACKEND"
+
+// Class is a kind of driver a Backend can provide.
+type Class string
+
+const (
+	ClassGPIO   Class = "gpio"
+	ClassI2C    Class = "i2c"
+	ClassSPI    Class = "spi"
+	ClassADC    Class = "adc"
+	ClassOLED   Class = "oled"
+	ClassSerial Class = "serial"
+)
+
+var (
+	ErrUnknownBackend = errors.New("unknown driver backend")
+	ErrUnsupported    = errors.New("driver backend does not support device class")
+)
+
+// Backend is a named set of factories. A backend provides only the
+// classes it supports; the other factories are nil.
+type Backend struct {
+	Name string
+
+	// Mock is true for in-memory backends that need no hardware.
+	Mock bool
+
+	GPIO   Factory
+	I2C    I2CFactory
+	SPI    SPIFactory
+	ADC    ADCFactory
+	OLED   OLEDFactory
+	Serial SerialFactory
+}
+
+// Supports reports whether b provides a factory for class c.
+func (b Backend) Supports(c Class) bool {
+	switch c {
+	case ClassGPIO:
+		return b.GPIO != nil
+	case ClassI2C:
+		return b.I2C != nil
+	case ClassSPI:
+		return b.SPI != nil
+	case ClassADC:
+		return b.ADC != nil
+	case ClassOLED:
+		return b.OLED != nil
+	case ClassSerial:
+		return b.Serial != nil
+	}
+	return false
+}
+
+var registry = struct {
+	sync.RWMutex
+	backends map[string]Backend
+	def      string // set by SetDefault
+}{backends: map[string]Backend{}}
+
+// Register makes a backend available by name. It panics if the name is
+// empty, reserved or already registered.
+func Register(b Backend) {
+	registry.Lock()
+	defer registry.Unlock()
+	if b.Name == "" || b.Name == BackendAuto {
+		panic(fmt.Sprintf("drivers: invalid backend name %q", b.Name))
+	}
+	if _, dup := registry.backends[b.Name]; dup {
+		panic("drivers: Register called twice for backend " + b.Name)
+	}
+	registry.backends[b.Name] = b
+}
+
+// Lookup returns the backend registered under name.
+func Lookup(name string) (Backend, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	b, ok := registry.backends[name]
+	if !ok {
+		return Backend{}, fmt.Errorf("%w %q (have %s)", ErrUnknownBackend, name, strings.Join(backendNames(), ", "))
+	}
+	return b, nil
+}
+
+// Backends returns the registered backend names, sorted.
+func Backends() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return backendNames()
+}
+
+// BackendsFor returns the names of the backends that support class c, sorted.
+func BackendsFor(c Class) []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var names []string
+	for _, name := range backendNames() {
+		if registry.backends[name].Supports(c) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// backendNames lists registry.backends. Caller holds registry.
+func backendNames() []string {
+	names := make([]string, 0, len(registry.backends))
+	for name := range registry.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefault overrides $DEVICES_BACKEND as the default backend, e.g. from
+// a config file or flag. "" restores the environment variable.
+func SetDefault(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.def = name
+}
+
+// Default returns the default backend name: the one set with SetDefault,
+// else $DEVICES_BACKEND, else "auto".
+func Default() string {
+	registry.RLock()
+	def := registry.def
+	registry.RUnlock()
+	if def == "" {
+		def = strings.TrimSpace(os.Getenv(EnvBackend))
+	}
+	if def == "" {
+		def = BackendAuto
+	}
+	return def
+}
+
+// Resolve returns the backend that provides class c for the requested
+// name:
+//
+//   - a registered name is used as is, and must support c;
+//   - "" or "auto" uses Default() if that backend supports c, so
+//     DEVICES_BACKEND=vpio switches GPIO devices while I2C devices keep
+//     their default;
+//   - otherwise, on Linux ARM hosts (Raspberry Pi and friends) the
+//     hardware backend for c (gpiocdev, periph or serial), and
+//     everywhere else the mock backend, so the same binary runs on a
+//     laptop or in CI.
+func Resolve(name string, c Class) (Backend, error) {
+	return resolve(name, c, Default(), hardwareHost())
+}
+
+func resolve(name string, c Class, def string, hardware bool) (Backend, error) {
+	if name == "" || name == BackendAuto {
+		if def != BackendAuto {
+			b, err := Lookup(def)
+			if err != nil {
+				return Backend{}, fmt.Errorf("default backend: %w", err)
+			}
+			if b.Supports(c) {
+				return b, nil
+			}
+		}
+		name = BackendMock
+		if hardware {
+			name = hardwareDefault[c]
+		}
+	}
+
+	b, err := Lookup(name)
+	if err != nil {
+		return Backend{}, err
+	}
+	if !b.Supports(c) {
+		return Backend{}, fmt.Errorf("%w: %s has no %s drivers", ErrUnsupported, name, c)
+	}
+	return b, nil
+}
+
+// hardwareDefault is the backend used for each class on a hardware host.
+var hardwareDefault = map[Class]string{
+	ClassGPIO:   BackendGPIOCDev,
+	ClassI2C:    BackendPeriph,
+	ClassSPI:    BackendPeriph,
+	ClassADC:    BackendPeriph,
+	ClassOLED:   BackendPeriph,
+	ClassSerial: BackendSerial,
+}
+
+// hardwareHost reports whether this binary runs where the hardware
+// backends work.
+func hardwareHost() bool {
+	return runtime.GOOS == "linux" && (runtime.GOARCH == "arm" || runtime.GOARCH == "arm64")
+}
+
+// IsMock reports whether devices that do not name a backend get
+// in-memory drivers: DEVICES_BACKEND is a mock backend, or this is not a
+// hardware host.
+func IsMock() bool {
+	b, err := Resolve(BackendAuto, ClassGPIO)
+	return err == nil && b.Mock
+}
+
+// GPIOFor returns the GPIO factory of the named backend ("" for the default).
+func GPIOFor(name string) (Factory, error) {
+	b, err := Resolve(name, ClassGPIO)
+	return b.GPIO, err
+}
+
+// I2CFor returns the I2C factory of the named backend ("" for the default).
+func I2CFor(name string) (I2CFactory, error) {
+	b, err := Resolve(name, ClassI2C)
+	return b.I2C, err
+}
+
+// SPIFor returns the SPI factory of the named backend ("" for the default).
+func SPIFor(name string) (SPIFactory, error) {
+	b, err := Resolve(name, ClassSPI)
+	return b.SPI, err
+}
+
+// ADCFor returns the ADC factory of the named backend ("" for the default).
+func ADCFor(name string) (ADCFactory, error) {
+	b, err := Resolve(name, ClassADC)
+	return b.ADC, err
+}
+
+// OLEDFor returns the OLED factory of the named backend ("" for the default).
+func OLEDFor(name string) (OLEDFactory, error) {
+	b, err := Resolve(name, ClassOLED)
+	return b.OLED, err
+}
+
+// SerialFor returns the serial factory of the named backend ("" for the default).
+func SerialFor(name string) (SerialFactory, error) {
+	b, err := Resolve(name, ClassSerial)
+	return b.Serial, err
+}
+
+// The built-in backends are registered on every platform; where the
+// hardware is unavailable their factories fail on open.
+func init() {
+	Register(Backend{Name: BackendGPIOCDev, GPIO: NewGPIOCDevFactory()})
+	Register(Backend{Name: BackendVPIO, Mock: true, GPIO: NewVPIOFactory()})
+	Register(Backend{Name: BackendPeriph, I2C: PeriphI2CFactory{}, SPI: PeriphSPIFactory{}, ADC: PeriphADCFactory{}, OLED: PeriphOLEDFactory{}})
+	Register(Backend{Name: BackendSerial, Serial: LinuxSerialFactory{}})
+	Register(Backend{
+		Name:   BackendMock,
+		Mock:   true,
+		GPIO:   NewVPIOFactory(),
+		I2C:    NewSimI2CFactory(),
+		SPI:    NewMockSPIFactory(),
+		ADC:    NewSimADCFactory(ConstSource(1.0)),
+		OLED:   MockOLEDFactory{},
+		Serial: NewMockSerialFactory(),
+	})
+}
@@ -0,0 +1,125 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinBackends(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"gpiocdev", "mock", "periph", "serial", "vpio"}, Backends())
+	assert.Equal(t, []string{"gpiocdev", "mock", "vpio"}, BackendsFor(ClassGPIO))
+	assert.Equal(t, []string{"mock", "periph"}, BackendsFor(ClassADC))
+	assert.Equal(t, []string{"mock", "periph"}, BackendsFor(ClassI2C))
+	assert.Equal(t, []string{"mock", "periph"}, BackendsFor(ClassSPI))
+
+	mock, err := Lookup(BackendMock)
+	require.NoError(t, err)
+	assert.True(t, mock.Mock)
+	for _, c := range []Class{ClassGPIO, ClassI2C, ClassSPI, ClassADC, ClassOLED, ClassSerial} {
+		assert.True(t, mock.Supports(c), c)
+	}
+
+	_, err = Lookup("nope")
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+}
+
+func TestRegisterPanics(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { Register(Backend{}) })
+	assert.Panics(t, func() { Register(Backend{Name: BackendAuto}) })
+	assert.Panics(t, func() { Register(Backend{Name: BackendMock}) })
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		class    Class
+		def      string
+		hardware bool
+		want     string
+	}{
+		{"", ClassGPIO, BackendAuto, true, BackendGPIOCDev},
+		{"", ClassADC, BackendAuto, true, BackendPeriph},
+		{"", ClassI2C, BackendAuto, true, BackendPeriph},
+		{"", ClassOLED, BackendAuto, true, BackendPeriph},
+		{"", ClassSerial, BackendAuto, true, BackendSerial},
+		{"", ClassGPIO, BackendAuto, false, BackendMock},
+		{BackendAuto, ClassSerial, BackendAuto, false, BackendMock},
+
+		// the default applies only to classes it supports
+		{"", ClassGPIO, BackendVPIO, true, BackendVPIO},
+		{"", ClassADC, BackendVPIO, true, BackendPeriph},
+		{"", ClassADC, BackendMock, true, BackendMock},
+
+		// explicit names win
+		{BackendPeriph, ClassADC, BackendMock, false, BackendPeriph},
+	}
+	for _, c := range cases {
+		b, err := resolve(c.name, c.class, c.def, c.hardware)
+		require.NoError(t, err, "%+v", c)
+		assert.Equal(t, c.want, b.Name, "%+v", c)
+	}
+
+	_, err := resolve(BackendVPIO, ClassOLED, BackendAuto, true)
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, err = resolve("", ClassGPIO, "nope", true)
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+}
+
+func TestResolveFromEnv(t *testing.T) {
+	t.Setenv(EnvBackend, BackendVPIO)
+
+	assert.Equal(t, BackendVPIO, Default())
+	assert.True(t, IsMock())
+	f, err := GPIOFor("")
+	require.NoError(t, err)
+	assert.IsType(t, &VPIOFactory{}, f)
+
+	SetDefault(BackendGPIOCDev)
+	t.Cleanup(func() { SetDefault("") })
+	assert.Equal(t, BackendGPIOCDev, Default(), "SetDefault overrides the environment")
+	assert.False(t, IsMock())
+
+	SetDefault("")
+	t.Setenv(EnvBackend, "")
+	assert.Equal(t, BackendAuto, Default())
+	assert.Equal(t, !hardwareHost(), IsMock())
+}
+
+func TestFactoryHelpers(t *testing.T) {
+	t.Parallel()
+
+	a, err := ADCFor(BackendMock)
+	require.NoError(t, err)
+	adc, err := a.OpenADC(ADCConfig{Bus: "1"})
+	require.NoError(t, err)
+	defer adc.Close()
+	_, err = a.OpenADC(ADCConfig{Chip: "mcp9999"})
+	assert.ErrorIs(t, err, ErrUnknownADC)
+
+	sp, err := SPIFor(BackendMock)
+	require.NoError(t, err)
+	assert.IsType(t, &MockSPIFactory{}, sp)
+
+	i, err := I2CFor(BackendMock)
+	require.NoError(t, err)
+	assert.IsType(t, &SimI2CFactory{}, i)
+
+	o, err := OLEDFor(BackendMock)
+	require.NoError(t, err)
+	assert.IsType(t, MockOLEDFactory{}, o)
+
+	s, err := SerialFor(BackendSerial)
+	require.NoError(t, err)
+	assert.IsType(t, LinuxSerialFactory{}, s)
+
+	_, err = SerialFor(BackendPeriph)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -1,11 +1,53 @@
 /*
-The drivers include GPIO (digital), analog (via ads1115), serial,
-I2C at this point.
+The drivers include GPIO (digital), analog (via ads1x15 or mcp3008),
+serial, I2C and SPI at this point.
 
 I have to admit these drivers are not the cleanest of interfaces
 that they could perhaps be.
 
 Each of the said drivers can be put into mock mode for testing
 and development on a non-raspberry pi.
+
+Drivers are grouped into named backends (gpiocdev, vpio, periph,
+serial, mock) held in a registry. GPIOFor, I2CFor, SPIFor, ADCFor,
+OLEDFor and SerialFor pick one by name, or by default from $DEVICES_BACKEND; when
+neither is set a Pi gets the hardware backends and anything else gets
+mock, so the same binary runs everywhere:
+
+	f, err := drivers.GPIOFor("") // gpiocdev on a Pi, mock elsewhere
+
+IsMock reports which way the default went.
+
+I2CBus is the bus abstraction for I2C chips. The periph backend opens
+real buses; the mock backend opens a SimI2CBus, where fake chips are
+attached as RegisterMaps with read and write hooks, so a driver for a
+new chip can be tested without hardware:
+
+	chip := drivers.NewRegisterMap()
+	chip.SetBytes(0xd0, 0x60)
+	bus := drivers.NewSimI2CBus("1")
+	_ = bus.Attach(0x76, chip)
+
+SPIPort is the SPI equivalent. The mock backend's ports are
+MockSPIPorts, which answer transfers from a script or a handler that
+models the chip.
+
+An ADCFactory opens the ADC chip named in ADCConfig: an ADS1015 or
+ADS1115 on I2C, or an MCP3008 on SPI. The ADS1x15 driver talks to any
+I2CBus, so it is tested against a simulated chip.
+
+The mock backend's ADCs are SimADCs. Each channel reads an ADCSource
+(a constant, a sequence, a sine, ramp or noise generator, or a
+callback), and reads can be delayed or made to fail:
+
+	f := drivers.NewSimADCFactory(drivers.ConstSource(1.2))
+	soil := f.ADC("1", 0x48)
+	soil.Set(0, drivers.RampSource(0, 3, time.Minute))
+	soil.FailEvery(10, nil)
+
+Serial ports are opened by a SerialFactory. The mock backend's ports
+are in-memory loopbacks; to test LinuxSerialFactory and the devices on
+top of it end to end, package serialtest opens a pty whose slave is the
+port and whose master plays the device.
 */
 package drivers
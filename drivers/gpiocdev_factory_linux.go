@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/warthog618/go-gpiocdev"
+	"golang.org/x/sys/unix"
 )
 
 // GPIOCDevFactory opens GPIO lines using go-gpiocdev.
@@ -18,6 +19,9 @@ func NewGPIOCDevFactory() Factory { return &GPIOCDevFactory{} }
 
 // OpenInput requests a GPIO line for edge events.
 func (f *GPIOCDevFactory) OpenInput(chip string, offset int, edge Edge, bias Bias, debounce time.Duration) (InputLine, error) {
+	if err := checkInput(edge, bias); err != nil {
+		return nil, err
+	}
 	if chip == "" {
 		chip = "gpiochip0"
 	}
@@ -37,19 +41,16 @@ func (f *GPIOCDevFactory) OpenInput(chip string, offset int, edge Edge, bias Bia
 	// edges + handler
 	evtQ := make(chan gpiocdev.LineEvent, 32)
 
-	// configure edges
+	// configure edges; the kernel only reports the requested edges so its
+	// per-line sequence numbers stay contiguous.
 	switch edge {
-	case EdgeBoth:
-		opts = append(opts, gpiocdev.WithBothEdges)
 	case EdgeRising:
-		// go-gpiocdev does rising via event handler + rising config (no separate option),
-		// but WithBothEdges is simplest. For “rising only”, we filter later.
-		opts = append(opts, gpiocdev.WithBothEdges)
+		opts = append(opts, gpiocdev.WithRisingEdge)
 	case EdgeFalling:
-		opts = append(opts, gpiocdev.WithBothEdges)
+		opts = append(opts, gpiocdev.WithFallingEdge)
 	case EdgeNone:
 		// no edge options
-	default:
+	default: // EdgeBoth or ""
 		opts = append(opts, gpiocdev.WithBothEdges)
 	}
 
@@ -59,12 +60,20 @@ func (f *GPIOCDevFactory) OpenInput(chip string, offset int, edge Edge, bias Bia
 	}
 
 	// per-line handler pushes into evtQ
+	var localSeq uint32
 	opts = append(opts, gpiocdev.WithEventHandler(func(evt gpiocdev.LineEvent) {
+		// uAPI v1 kernels don't number events; count them here so drops
+		// from evtQ still show up as gaps.
+		localSeq++
+		if evt.LineSeqno == 0 {
+			evt.LineSeqno = localSeq
+		}
+
 		// non-blocking to avoid wedging kernel event delivery
 		select {
 		case evtQ <- evt:
 		default:
-			// drop if overwhelmed
+			// drop if overwhelmed; visible to consumers as a Seq gap
 		}
 	}))
 
@@ -126,32 +135,23 @@ func (l *gpiocdevInputLine) Events(ctx context.Context) (<-chan LineEvent, error
 					return
 				}
 
-				edge := EdgeNone
-				switch evt.Type {
-				case gpiocdev.LineEventRisingEdge:
-					edge = EdgeRising
-				case gpiocdev.LineEventFallingEdge:
-					edge = EdgeFalling
-				}
+				ev := toLineEvent(evt)
 
 				// filter if caller asked for only rising/falling
-				if l.edge == EdgeRising && edge != EdgeRising {
+				if l.edge == EdgeRising && ev.Edge != EdgeRising {
 					continue
 				}
-				if l.edge == EdgeFalling && edge != EdgeFalling {
+				if l.edge == EdgeFalling && ev.Edge != EdgeFalling {
 					continue
 				}
 				if l.edge == EdgeNone {
 					continue
 				}
 
-				val, _ := l.Read() // best effort; some kernels include state in event, but simplest is re-read
 				select {
-				// TODO extract the time.Time value from the duration provided below
-				// case out <- LineEvent{Time: evt.Timestamp, Edge: edge, Value: val}:
-				case out <- LineEvent{Time: time.Now(), Edge: edge, Value: val}:
+				case out <- ev:
 				default:
-					// drop if consumer slow
+					// drop if consumer slow; visible to consumers as a Seq gap
 				}
 
 			case <-ctx.Done():
@@ -176,6 +176,55 @@ func (l *gpiocdevInputLine) Close() error {
 	return nil
 }
 
+// toLineEvent converts a kernel event, deriving the value from the edge:
+// the line is high after a rising edge and low after a falling edge.
+func toLineEvent(evt gpiocdev.LineEvent) LineEvent {
+	ev := LineEvent{
+		Time: eventTime(evt.Timestamp),
+		Edge: EdgeNone,
+		Seq:  uint64(evt.LineSeqno),
+	}
+	switch evt.Type {
+	case gpiocdev.LineEventRisingEdge:
+		ev.Edge = EdgeRising
+		ev.Value = true
+	case gpiocdev.LineEventFallingEdge:
+		ev.Edge = EdgeFalling
+		ev.Value = false
+	}
+	return ev
+}
+
+// eventTime maps a kernel line event timestamp onto wall-clock time.
+func eventTime(ts time.Duration) time.Time {
+	var mono unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &mono); err != nil {
+		return time.Now()
+	}
+	return kernelToWall(ts, time.Duration(mono.Nano()), time.Now())
+}
+
+// kernelToWall converts a kernel timestamp given the current monotonic
+// clock reading and wall time.
+//
+// Since Linux 5.7 event timestamps are CLOCK_MONOTONIC; earlier kernels
+// used CLOCK_REALTIME. A realtime stamp is much closer to the wall clock
+// than to the monotonic clock, which tells the two apart.
+func kernelToWall(ts, monoNow time.Duration, now time.Time) time.Time {
+	realNow := time.Duration(now.UnixNano())
+	if absDuration(realNow-ts) < absDuration(monoNow-ts) {
+		return time.Unix(0, int64(ts))
+	}
+	return now.Add(ts - monoNow)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
 // gpiocdevOutputLine wraps a gpiocdev Line for output writes.
 type gpiocdevOutputLine struct {
 	line *gpiocdev.Line
@@ -4,9 +4,11 @@ package drivers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/warthog618/go-gpiocdev"
 )
 
 func TestNewGPIOCDevFactory(t *testing.T) {
@@ -15,4 +17,39 @@ func TestNewGPIOCDevFactory(t *testing.T) {
 	f := NewGPIOCDevFactory()
 	require.NotNil(t, f)
 	assert.IsType(t, &GPIOCDevFactory{}, f)
+
+	// Checked before any chip is touched.
+	_, err := f.OpenInput("gpiochip0", 0, Edge("fallng"), BiasDefault, 0)
+	assert.ErrorContains(t, err, `invalid edge "fallng"`)
+}
+
+func TestKernelToWall(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	monoNow := 5 * time.Hour
+
+	// CLOCK_MONOTONIC stamp 20ms in the past
+	got := kernelToWall(monoNow-20*time.Millisecond, monoNow, now)
+	assert.Equal(t, now.Add(-20*time.Millisecond), got)
+
+	// CLOCK_REALTIME stamp from an older kernel
+	rt := now.Add(-3 * time.Millisecond)
+	got = kernelToWall(time.Duration(rt.UnixNano()), monoNow, now)
+	assert.True(t, rt.Equal(got))
+}
+
+func TestToLineEvent(t *testing.T) {
+	t.Parallel()
+
+	ev := toLineEvent(gpiocdev.LineEvent{Type: gpiocdev.LineEventRisingEdge, LineSeqno: 7})
+	assert.Equal(t, EdgeRising, ev.Edge)
+	assert.True(t, ev.Value)
+	assert.Equal(t, uint64(7), ev.Seq)
+	assert.False(t, ev.Time.IsZero())
+
+	ev = toLineEvent(gpiocdev.LineEvent{Type: gpiocdev.LineEventFallingEdge, LineSeqno: 8})
+	assert.Equal(t, EdgeFalling, ev.Edge)
+	assert.False(t, ev.Value)
+	assert.Equal(t, uint64(8), ev.Seq)
 }
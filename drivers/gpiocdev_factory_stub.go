@@ -0,0 +1,26 @@
+//go:build !linux
+
+package drivers
+
+import (
+	"errors"
+	"time"
+)
+
+// GPIOCDevFactory is a stub on non-Linux builds.
+//
+// This keeps the public API building on developer machines and in CI.
+type GPIOCDevFactory struct{}
+
+// NewGPIOCDevFactory returns a Factory whose lines fail to open.
+func NewGPIOCDevFactory() Factory { return &GPIOCDevFactory{} }
+
+var errGPIOCDevUnsupported = errors.New("gpiocdev: supported only on linux")
+
+func (*GPIOCDevFactory) OpenInput(chip string, offset int, edge Edge, bias Bias, debounce time.Duration) (InputLine, error) {
+	return nil, errGPIOCDevUnsupported
+}
+
+func (*GPIOCDevFactory) OpenOutput(chip string, offset int, initial bool) (OutputLine, error) {
+	return nil, errGPIOCDevUnsupported
+}
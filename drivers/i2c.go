@@ -0,0 +1,134 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+)
+
+// ErrI2CNoDevice is returned when no device acknowledges an address.
+var ErrI2CNoDevice = errors.New("i2c: no device at address")
+
+// I2CBus is an I2C bus master shared by the devices on it.
+//
+// Addresses are 7-bit. ReadReg and WriteReg cover the common register
+// protocol: the first byte written selects a register, and reads continue
+// from it.
+type I2CBus interface {
+	// Tx writes w to the device at addr and then reads len(r) bytes into r,
+	// with a repeated start in between. Either may be empty, but not both:
+	// Linux skips the bus for an empty transfer. See I2CQuickWriter.
+	Tx(addr uint16, w, r []byte) error
+
+	// ReadReg reads len(r) bytes starting at register reg.
+	ReadReg(addr uint16, reg uint8, r []byte) error
+
+	// WriteReg writes data starting at register reg.
+	WriteReg(addr uint16, reg uint8, data ...byte) error
+
+	Close() error
+	String() string
+}
+
+// I2CFactory opens I2C buses by name ("1" for /dev/i2c-1 on a Pi, "" for
+// the first available).
+type I2CFactory interface {
+	OpenI2C(bus string) (I2CBus, error)
+}
+
+// I2CQuickWriter is implemented by buses that can issue an SMBus quick
+// write: a start, the address with the write bit, and a stop. It returns
+// nil if a device acknowledged the address.
+type I2CQuickWriter interface {
+	QuickWrite(addr uint16) error
+}
+
+// I2CDevice is one address on an I2CBus.
+//
+// It implements periph's conn.Conn, so periph device drivers can talk to
+// a device through any I2CBus, including the simulated one.
+type I2CDevice struct {
+	Bus  I2CBus
+	Addr uint16
+}
+
+// NewI2CDevice returns the device at addr on bus.
+func NewI2CDevice(bus I2CBus, addr uint16) *I2CDevice {
+	return &I2CDevice{Bus: bus, Addr: addr}
+}
+
+// Tx writes w and then reads into r.
+func (d *I2CDevice) Tx(w, r []byte) error { return d.Bus.Tx(d.Addr, w, r) }
+
+// ReadReg reads len(r) bytes starting at register reg.
+func (d *I2CDevice) ReadReg(reg uint8, r []byte) error { return d.Bus.ReadReg(d.Addr, reg, r) }
+
+// WriteReg writes data starting at register reg.
+func (d *I2CDevice) WriteReg(reg uint8, data ...byte) error {
+	return d.Bus.WriteReg(d.Addr, reg, data...)
+}
+
+// ReadReg8 reads the single byte register reg.
+func (d *I2CDevice) ReadReg8(reg uint8) (byte, error) {
+	var b [1]byte
+	err := d.ReadReg(reg, b[:])
+	return b[0], err
+}
+
+// ReadReg16 reads the big-endian 16-bit register reg.
+func (d *I2CDevice) ReadReg16(reg uint8) (uint16, error) {
+	var b [2]byte
+	err := d.ReadReg(reg, b[:])
+	return uint16(b[0])<<8 | uint16(b[1]), err
+}
+
+// WriteReg16 writes v big-endian to the 16-bit register reg.
+func (d *I2CDevice) WriteReg16(reg uint8, v uint16) error {
+	return d.WriteReg(reg, byte(v>>8), byte(v))
+}
+
+func (d *I2CDevice) String() string { return fmt.Sprintf("%s/0x%02x", d.Bus, d.Addr) }
+
+// Duplex implements conn.Conn; I2C is half duplex.
+func (d *I2CDevice) Duplex() conn.Duplex { return conn.Half }
+
+// PeriphBus adapts b to periph's i2c.BusCloser so periph device drivers
+// (bmxx80, ssd1306, ...) can run on it. SetSpeed is passed through when b
+// supports it and ignored otherwise.
+func PeriphBus(b I2CBus) i2c.BusCloser { return periphAdapter{b} }
+
+type periphAdapter struct{ I2CBus }
+
+func (a periphAdapter) SetSpeed(f physic.Frequency) error {
+	if s, ok := a.I2CBus.(interface{ SetSpeed(physic.Frequency) error }); ok {
+		return s.SetSpeed(f)
+	}
+	return nil
+}
+
+// readReg and writeReg implement the register protocol on top of Tx.
+func readReg(tx func(addr uint16, w, r []byte) error, addr uint16, reg uint8, r []byte) error {
+	return tx(addr, []byte{reg}, r)
+}
+
+func writeReg(tx func(addr uint16, w, r []byte) error, addr uint16, reg uint8, data []byte) error {
+	w := make([]byte, 0, 1+len(data))
+	w = append(w, reg)
+	w = append(w, data...)
+	return tx(addr, w, nil)
+}
+
+func checkI2CAddr(addr uint16) error {
+	if addr > 0x7f {
+		return fmt.Errorf("i2c: invalid 7-bit address 0x%x", addr)
+	}
+	return nil
+}
+
+var (
+	_ conn.Conn     = (*I2CDevice)(nil)
+	_ i2c.BusCloser = periphAdapter{}
+)
@@ -0,0 +1,74 @@
+package drivers
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/host/v3"
+)
+
+// PeriphI2CFactory opens I2C buses using periph.io. It builds everywhere;
+// off Linux, or without /dev/i2c-*, opening fails.
+type PeriphI2CFactory struct{}
+
+func (PeriphI2CFactory) OpenI2C(bus string) (I2CBus, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("i2c: host init: %w", err)
+	}
+	b, err := i2creg.Open(bus)
+	if err != nil {
+		return nil, fmt.Errorf("i2c: open bus %q: %w", bus, err)
+	}
+	p := &periphI2CBus{bus: b, number: -1}
+	// The sysfs driver names /dev/i2c-N "I2CN".
+	if _, err := fmt.Sscanf(b.String(), "I2C%d", &p.number); err != nil {
+		p.number = -1
+	}
+	return p, nil
+}
+
+type periphI2CBus struct {
+	bus    i2c.BusCloser
+	number int // N of /dev/i2c-N, or -1
+}
+
+func (p *periphI2CBus) Tx(addr uint16, w, r []byte) error {
+	if err := checkI2CAddr(addr); err != nil {
+		return err
+	}
+	return p.bus.Tx(addr, w, r)
+}
+
+// QuickWrite issues an SMBus quick write through /dev/i2c-N; periph has
+// no way to send an address without data.
+func (p *periphI2CBus) QuickWrite(addr uint16) error {
+	if err := checkI2CAddr(addr); err != nil {
+		return err
+	}
+	if p.number < 0 {
+		return fmt.Errorf("i2c: %s: quick write needs a /dev/i2c bus", p)
+	}
+	return smbusQuickWrite(p.number, addr)
+}
+
+func (p *periphI2CBus) ReadReg(addr uint16, reg uint8, r []byte) error {
+	return readReg(p.Tx, addr, reg, r)
+}
+
+func (p *periphI2CBus) WriteReg(addr uint16, reg uint8, data ...byte) error {
+	return writeReg(p.Tx, addr, reg, data)
+}
+
+func (p *periphI2CBus) SetSpeed(f physic.Frequency) error { return p.bus.SetSpeed(f) }
+
+func (p *periphI2CBus) Close() error { return p.bus.Close() }
+
+func (p *periphI2CBus) String() string { return p.bus.String() }
+
+var (
+	_ I2CFactory     = PeriphI2CFactory{}
+	_ I2CQuickWriter = (*periphI2CBus)(nil)
+	_ I2CQuickWriter = (*SimI2CBus)(nil)
+)
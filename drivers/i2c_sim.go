@@ -0,0 +1,311 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// I2CChip is a simulated device on a SimI2CBus. Tx receives each
+// transaction addressed to the chip.
+type I2CChip interface {
+	Tx(w, r []byte) error
+}
+
+// I2CChipFunc adapts a function to I2CChip, for chips that do not fit a
+// RegisterMap (e.g. the SSD1306 control-byte protocol).
+type I2CChipFunc func(w, r []byte) error
+
+func (f I2CChipFunc) Tx(w, r []byte) error { return f(w, r) }
+
+// SimI2CFactory is a portable I2CFactory whose buses are SimI2CBus,
+// created on first use and shared by every OpenI2C of the same name.
+type SimI2CFactory struct {
+	mu    sync.Mutex
+	buses map[string]*SimI2CBus
+}
+
+// NewSimI2CFactory constructs an empty SimI2CFactory.
+func NewSimI2CFactory() *SimI2CFactory {
+	return &SimI2CFactory{buses: map[string]*SimI2CBus{}}
+}
+
+// Bus returns the bus named name, creating it.
+func (f *SimI2CFactory) Bus(name string) *SimI2CBus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.buses[name]
+	if !ok {
+		b = NewSimI2CBus(name)
+		f.buses[name] = b
+	}
+	return b
+}
+
+// OpenI2C returns the bus named bus.
+func (f *SimI2CFactory) OpenI2C(bus string) (I2CBus, error) { return f.Bus(bus), nil }
+
+// SimI2CBus is an in-memory I2CBus hosting simulated chips. Transactions
+// to an address without a chip fail with ErrI2CNoDevice, as a NACK would.
+type SimI2CBus struct {
+	name string
+
+	mu    sync.Mutex
+	chips map[uint16]I2CChip
+}
+
+// NewSimI2CBus constructs an empty simulated bus.
+func NewSimI2CBus(name string) *SimI2CBus {
+	return &SimI2CBus{name: name, chips: map[uint16]I2CChip{}}
+}
+
+// Attach places chip at addr.
+func (b *SimI2CBus) Attach(addr uint16, chip I2CChip) error {
+	if err := checkI2CAddr(addr); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.chips[addr]; ok {
+		return fmt.Errorf("i2c: address 0x%02x already in use on %s", addr, b)
+	}
+	b.chips[addr] = chip
+	return nil
+}
+
+// Detach removes the chip at addr, if any.
+func (b *SimI2CBus) Detach(addr uint16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.chips, addr)
+}
+
+// Addrs returns the addresses with a chip attached, sorted.
+func (b *SimI2CBus) Addrs() []uint16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	addrs := make([]uint16, 0, len(b.chips))
+	for a := range b.chips {
+		addrs = append(addrs, a)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+func (b *SimI2CBus) Tx(addr uint16, w, r []byte) error {
+	if err := checkI2CAddr(addr); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	chip, ok := b.chips[addr]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w 0x%02x on %s", ErrI2CNoDevice, addr, b)
+	}
+	return chip.Tx(w, r)
+}
+
+// QuickWrite acknowledges addr if a chip is attached there.
+func (b *SimI2CBus) QuickWrite(addr uint16) error {
+	if err := checkI2CAddr(addr); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	_, ok := b.chips[addr]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w 0x%02x on %s", ErrI2CNoDevice, addr, b)
+	}
+	return nil
+}
+
+func (b *SimI2CBus) ReadReg(addr uint16, reg uint8, r []byte) error {
+	return readReg(b.Tx, addr, reg, r)
+}
+
+func (b *SimI2CBus) WriteReg(addr uint16, reg uint8, data ...byte) error {
+	return writeReg(b.Tx, addr, reg, data)
+}
+
+// Close is a no-op: the bus and its chips outlive any one user.
+func (b *SimI2CBus) Close() error { return nil }
+
+func (b *SimI2CBus) String() string { return "sim-i2c-" + b.name }
+
+// ErrReadOnlyReg is returned when writing a register marked read-only.
+var ErrReadOnlyReg = errors.New("i2c: register is read-only")
+
+// RegisterMap is a simulated chip made of registers. The first byte of a
+// write selects a register; following bytes are written from there, and
+// reads continue from the selected register.
+//
+// With 8-bit registers (NewRegisterMap) the register pointer advances
+// after every byte, as on the BME280 and most sensors. With 16-bit
+// registers (NewRegisterMap16) values are big-endian and the pointer
+// stays put, as on the ADS1x15.
+//
+// Hooks model behavior: OnRead computes a register's value when it is
+// read, OnWrite reacts after a register is written. Hooks run without the
+// map locked, so they may call Get and Set.
+type RegisterMap struct {
+	wide bool
+
+	txMu sync.Mutex // serializes transactions; guards ptr
+	ptr  uint8
+
+	mu       sync.Mutex
+	regs     map[uint8]uint16
+	readOnly map[uint8]bool
+	onRead   map[uint8]func(reg uint8) uint16
+	onWrite  map[uint8]func(reg uint8, v uint16)
+}
+
+// NewRegisterMap returns a map of 8-bit registers, all zero.
+func NewRegisterMap() *RegisterMap { return newRegisterMap(false) }
+
+// NewRegisterMap16 returns a map of 16-bit registers, all zero.
+func NewRegisterMap16() *RegisterMap { return newRegisterMap(true) }
+
+func newRegisterMap(wide bool) *RegisterMap {
+	return &RegisterMap{
+		wide:     wide,
+		regs:     map[uint8]uint16{},
+		readOnly: map[uint8]bool{},
+		onRead:   map[uint8]func(uint8) uint16{},
+		onWrite:  map[uint8]func(uint8, uint16){},
+	}
+}
+
+// Set stores v in reg without running hooks; with 8-bit registers only
+// the low byte is kept.
+func (m *RegisterMap) Set(reg uint8, v uint16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.regs[reg] = m.trunc(v)
+}
+
+// SetBytes stores b in consecutive registers starting at reg.
+func (m *RegisterMap) SetBytes(reg uint8, b ...byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, v := range b {
+		m.regs[reg+uint8(i)] = uint16(v)
+	}
+}
+
+// Get returns the stored value of reg without running hooks.
+func (m *RegisterMap) Get(reg uint8) uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.regs[reg]
+}
+
+// ReadOnly makes writes to regs fail with ErrReadOnlyReg.
+func (m *RegisterMap) ReadOnly(regs ...uint8) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range regs {
+		m.readOnly[r] = true
+	}
+}
+
+// OnRead makes reads of reg return fn(reg) instead of the stored value.
+func (m *RegisterMap) OnRead(reg uint8, fn func(reg uint8) uint16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRead[reg] = fn
+}
+
+// OnWrite calls fn with the new value after reg is written.
+func (m *RegisterMap) OnWrite(reg uint8, fn func(reg uint8, v uint16)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onWrite[reg] = fn
+}
+
+// Tx implements I2CChip.
+func (m *RegisterMap) Tx(w, r []byte) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	if len(w) > 0 {
+		m.ptr = w[0]
+		data := w[1:]
+		if m.wide {
+			if len(data)%2 != 0 {
+				return fmt.Errorf("i2c: odd write of %d bytes to 16-bit register 0x%02x", len(data), m.ptr)
+			}
+			for i := 0; i < len(data); i += 2 {
+				if err := m.write(m.ptr, uint16(data[i])<<8|uint16(data[i+1])); err != nil {
+					return err
+				}
+			}
+		} else {
+			for _, b := range data {
+				if err := m.write(m.ptr, uint16(b)); err != nil {
+					return err
+				}
+				m.ptr++
+			}
+		}
+	}
+
+	if m.wide {
+		if len(r) > 0 {
+			v := m.read(m.ptr)
+			for i := range r {
+				if i%2 == 0 {
+					r[i] = byte(v >> 8)
+				} else {
+					r[i] = byte(v)
+				}
+			}
+		}
+		return nil
+	}
+	for i := range r {
+		r[i] = byte(m.read(m.ptr))
+		m.ptr++
+	}
+	return nil
+}
+
+func (m *RegisterMap) read(reg uint8) uint16 {
+	m.mu.Lock()
+	fn, v := m.onRead[reg], m.regs[reg]
+	m.mu.Unlock()
+	if fn != nil {
+		v = m.trunc(fn(reg))
+	}
+	return v
+}
+
+func (m *RegisterMap) write(reg uint8, v uint16) error {
+	m.mu.Lock()
+	if m.readOnly[reg] {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: 0x%02x", ErrReadOnlyReg, reg)
+	}
+	m.regs[reg] = v
+	fn := m.onWrite[reg]
+	m.mu.Unlock()
+	if fn != nil {
+		fn(reg, v)
+	}
+	return nil
+}
+
+func (m *RegisterMap) trunc(v uint16) uint16 {
+	if m.wide {
+		return v
+	}
+	return v & 0xff
+}
+
+var (
+	_ I2CFactory = (*SimI2CFactory)(nil)
+	_ I2CBus     = (*SimI2CBus)(nil)
+	_ I2CChip    = (*RegisterMap)(nil)
+	_ I2CChip    = I2CChipFunc(nil)
+)
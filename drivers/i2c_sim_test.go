@@ -0,0 +1,137 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimI2CBusAttach(t *testing.T) {
+	t.Parallel()
+
+	f := NewSimI2CFactory()
+	b, err := f.OpenI2C("1")
+	require.NoError(t, err)
+	assert.Same(t, f.Bus("1"), b, "buses are shared by name")
+	assert.Equal(t, "sim-i2c-1", b.String())
+
+	bus := f.Bus("1")
+	require.NoError(t, bus.Attach(0x76, NewRegisterMap()))
+	require.NoError(t, bus.Attach(0x48, NewRegisterMap16()))
+	assert.Error(t, bus.Attach(0x76, NewRegisterMap()), "address in use")
+	assert.Error(t, bus.Attach(0x80, NewRegisterMap()), "not a 7-bit address")
+	assert.Equal(t, []uint16{0x48, 0x76}, bus.Addrs())
+
+	assert.NoError(t, bus.Tx(0x76, nil, make([]byte, 1)))
+	assert.ErrorIs(t, bus.Tx(0x77, nil, make([]byte, 1)), ErrI2CNoDevice)
+	assert.NoError(t, bus.QuickWrite(0x48))
+	assert.ErrorIs(t, bus.QuickWrite(0x77), ErrI2CNoDevice)
+
+	bus.Detach(0x76)
+	assert.ErrorIs(t, bus.Tx(0x76, nil, nil), ErrI2CNoDevice)
+	assert.ErrorIs(t, bus.QuickWrite(0x76), ErrI2CNoDevice)
+}
+
+func TestRegisterMap8(t *testing.T) {
+	t.Parallel()
+
+	m := NewRegisterMap()
+	m.SetBytes(0xd0, 0x60)
+	m.SetBytes(0x88, 1, 2, 3, 4)
+	m.ReadOnly(0xd0)
+
+	bus := NewSimI2CBus("0")
+	require.NoError(t, bus.Attach(0x76, m))
+
+	r := make([]byte, 4)
+	require.NoError(t, bus.ReadReg(0x76, 0x88, r))
+	assert.Equal(t, []byte{1, 2, 3, 4}, r, "reads auto-increment")
+
+	// the pointer continues from where the last transaction stopped
+	r1 := make([]byte, 1)
+	m.SetBytes(0x8c, 5)
+	require.NoError(t, bus.Tx(0x76, nil, r1))
+	assert.Equal(t, byte(5), r1[0])
+
+	require.NoError(t, bus.WriteReg(0x76, 0xf4, 0x27, 0xa0))
+	assert.Equal(t, uint16(0x27), m.Get(0xf4))
+	assert.Equal(t, uint16(0xa0), m.Get(0xf5), "writes auto-increment")
+
+	assert.ErrorIs(t, bus.WriteReg(0x76, 0xd0, 0), ErrReadOnlyReg)
+	assert.Equal(t, uint16(0x60), m.Get(0xd0))
+
+	m.Set(0x10, 0x1234)
+	assert.Equal(t, uint16(0x34), m.Get(0x10), "8-bit registers keep the low byte")
+}
+
+func TestRegisterMap16(t *testing.T) {
+	t.Parallel()
+
+	m := NewRegisterMap16()
+	m.Set(0x01, 0x8583)
+
+	bus := NewSimI2CBus("0")
+	require.NoError(t, bus.Attach(0x48, m))
+	dev := NewI2CDevice(bus, 0x48)
+
+	v, err := dev.ReadReg16(0x01)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x8583), v)
+
+	require.NoError(t, dev.WriteReg16(0x02, 0x1234))
+	assert.Equal(t, uint16(0x1234), m.Get(0x02))
+	assert.Zero(t, m.Get(0x03), "the pointer does not advance")
+
+	assert.Error(t, dev.WriteReg(0x02, 0x12), "partial 16-bit write")
+}
+
+func TestRegisterMapHooks(t *testing.T) {
+	t.Parallel()
+
+	// A chip where writing 1 to ctrl (0x00) starts a conversion whose
+	// result appears in data (0x01), and status (0x02) counts reads.
+	m := NewRegisterMap()
+	m.OnWrite(0x00, func(reg uint8, v uint16) {
+		if v == 1 {
+			m.Set(0x01, 42)
+			m.Set(0x00, 0)
+		}
+	})
+	reads := 0
+	m.OnRead(0x02, func(uint8) uint16 {
+		reads++
+		return uint16(reads)
+	})
+
+	bus := NewSimI2CBus("0")
+	require.NoError(t, bus.Attach(0x20, m))
+	dev := NewI2CDevice(bus, 0x20)
+
+	require.NoError(t, dev.WriteReg(0x00, 1))
+	v, err := dev.ReadReg8(0x01)
+	require.NoError(t, err)
+	assert.Equal(t, byte(42), v)
+	assert.Zero(t, m.Get(0x00), "hook cleared the start bit")
+
+	for want := byte(1); want <= 3; want++ {
+		v, err := dev.ReadReg8(0x02)
+		require.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+}
+
+func TestI2CChipFunc(t *testing.T) {
+	t.Parallel()
+
+	var got [][]byte
+	bus := NewSimI2CBus("0")
+	require.NoError(t, bus.Attach(0x3c, I2CChipFunc(func(w, r []byte) error {
+		got = append(got, append([]byte(nil), w...))
+		return nil
+	})))
+
+	require.NoError(t, bus.Tx(0x3c, []byte{0x00, 0xaf}, nil))
+	require.NoError(t, bus.WriteReg(0x3c, 0x40, 1, 2, 3))
+	assert.Equal(t, [][]byte{{0x00, 0xaf}, {0x40, 1, 2, 3}}, got)
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// i2c-dev ioctls and SMBus constants, from linux/i2c-dev.h and linux/i2c.h.
+const (
+	i2cSlave          = 0x0703
+	i2cFuncs          = 0x0705
+	i2cSMBus          = 0x0720
+	i2cSMBusWrite     = 0
+	i2cSMBusQuick     = 0
+	i2cFuncSMBusQuick = 0x00010000
+)
+
+// i2cSMBusData is struct i2c_smbus_ioctl_data.
+type i2cSMBusData struct {
+	readWrite uint8
+	command   uint8
+	size      uint32
+	data      uintptr
+}
+
+// smbusQuickWrite sends an SMBus quick write to addr on /dev/i2c-bus.
+func smbusQuickWrite(bus int, addr uint16) error {
+	path := fmt.Sprintf("/dev/i2c-%d", bus)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("i2c: open %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var funcs uint // unsigned long
+	if err := ioctlPtr(fd, i2cFuncs, unsafe.Pointer(&funcs)); err != nil {
+		return fmt.Errorf("i2c: %s: get functionality: %w", path, err)
+	}
+	if funcs&i2cFuncSMBusQuick == 0 {
+		return fmt.Errorf("i2c: %s: adapter does not support SMBus quick", path)
+	}
+	if err := unix.IoctlSetInt(fd, i2cSlave, int(addr)); err != nil {
+		if errors.Is(err, unix.EBUSY) {
+			// A kernel driver owns the address, so a device is there.
+			return nil
+		}
+		return fmt.Errorf("i2c: %s: set address 0x%02x: %w", path, addr, err)
+	}
+	d := i2cSMBusData{readWrite: i2cSMBusWrite, size: i2cSMBusQuick}
+	if err := ioctlPtr(fd, i2cSMBus, unsafe.Pointer(&d)); err != nil {
+		return fmt.Errorf("%w 0x%02x on %s: %w", ErrI2CNoDevice, addr, path, err)
+	}
+	return nil
+}
+
+func ioctlPtr(fd int, req uint, arg unsafe.Pointer) error {
+	if _, _, e := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg)); e != 0 {
+		return e
+	}
+	return nil
+}
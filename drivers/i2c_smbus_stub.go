@@ -0,0 +1,9 @@
+//go:build !linux
+
+package drivers
+
+import "fmt"
+
+func smbusQuickWrite(bus int, addr uint16) error {
+	return fmt.Errorf("i2c: quick write: unsupported platform")
+}
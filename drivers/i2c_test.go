@@ -0,0 +1,66 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/devices/v3/bmxx80"
+)
+
+func TestI2CDevice(t *testing.T) {
+	t.Parallel()
+
+	m := NewRegisterMap()
+	m.SetBytes(0x10, 0xab, 0xcd)
+	bus := NewSimI2CBus("1")
+	require.NoError(t, bus.Attach(0x29, m))
+
+	d := NewI2CDevice(bus, 0x29)
+	assert.Equal(t, "sim-i2c-1/0x29", d.String())
+
+	v, err := d.ReadReg16(0x10)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0xabcd), v, "two 8-bit registers read big-endian")
+
+	require.NoError(t, d.WriteReg(0x20, 7))
+	b, err := d.ReadReg8(0x20)
+	require.NoError(t, err)
+	assert.Equal(t, byte(7), b)
+
+	r := make([]byte, 1)
+	require.NoError(t, d.Tx([]byte{0x10}, r))
+	assert.Equal(t, byte(0xab), r[0])
+}
+
+// TestPeriphBusRunsPeriphDrivers detects a simulated BME280 with periph's
+// bmxx80 driver, through PeriphBus.
+func TestPeriphBusRunsPeriphDrivers(t *testing.T) {
+	t.Parallel()
+
+	m := NewRegisterMap()
+	m.SetBytes(0xd0, 0x60) // chip id: BME280
+	m.ReadOnly(0xd0)
+	bus := NewSimI2CBus("1")
+	require.NoError(t, bus.Attach(0x76, m))
+
+	pb := PeriphBus(bus)
+	assert.NoError(t, pb.SetSpeed(0), "ignored by the simulated bus")
+
+	dev, err := bmxx80.NewI2C(pb, 0x76, &bmxx80.DefaultOpts)
+	require.NoError(t, err)
+	assert.Contains(t, dev.String(), "BME280")
+
+	m2 := NewRegisterMap()
+	m2.SetBytes(0xd0, 0x58) // chip id: BMP280
+	require.NoError(t, bus.Attach(0x77, m2))
+	dev, err = bmxx80.NewI2C(pb, 0x77, &bmxx80.DefaultOpts)
+	require.NoError(t, err)
+	assert.Contains(t, dev.String(), "BMP280")
+
+	// periph's own i2c.Dev works too
+	id := make([]byte, 1)
+	require.NoError(t, (&i2c.Dev{Bus: pb, Addr: 0x76}).Tx([]byte{0xd0}, id))
+	assert.Equal(t, byte(0x60), id[0])
+}
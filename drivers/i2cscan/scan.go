@@ -0,0 +1,158 @@
+// Package i2cscan finds and identifies the chips on an I2C bus.
+//
+// Scan probes every address in 0x03-0x77 and reports the ones that answer.
+// Known chips are then identified from their ID registers or power-on
+// defaults:
+//
+//	0x76, 0x77   BME280 (chip id 0x60) or BMP280 (chip id 0x56-0x58)
+//	0x48-0x4b    ADS1115 (config register reset value 0x8583)
+//	0x3c, 0x3d   SSD1306 OLED (by address)
+//
+// Results are devices.Descriptors named after the chip and address, e.g.
+// "bme280_77", with the bus, addr and chip attributes set; config.Seed
+// turns them into a station.
+package i2cscan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// Address range probed by default; 0x00-0x02 and 0x78-0x7f are reserved.
+const (
+	FirstAddr uint16 = 0x03
+	LastAddr  uint16 = 0x77
+)
+
+// Chip names set in the "chip" attribute.
+const (
+	ChipBME280  = "bme280"
+	ChipBMP280  = "bmp280"
+	ChipADS1115 = "ads1115"
+	ChipSSD1306 = "ssd1306"
+)
+
+// Registers read to identify chips.
+const (
+	bmxChipIDReg   = 0xd0
+	adsConfigReg   = 0x01
+	adsConfigReset = 0x8583
+)
+
+// Options configures a Scan.
+type Options struct {
+	// Bus names the bus in the Descriptor attributes.
+	Bus string
+
+	// First and Last bound the probed addresses. Default 0x03-0x77.
+	First, Last uint16
+
+	// Quick probes with an SMBus quick write instead of a one-byte read.
+	// The quick write is the usual probe and the one to use for
+	// write-only chips such as the SSD1306, which may not answer a read.
+	// The read is gentler on EEPROMs (0x50-0x5f), some of which a quick
+	// write can corrupt; i2cdetect reads only there. The bus must
+	// implement drivers.I2CQuickWriter.
+	Quick bool
+}
+
+// Scan probes the addresses of bus in order and returns a Descriptor for
+// every device that answers, identified where possible. It stops early,
+// returning what it found so far, when ctx is canceled.
+func Scan(ctx context.Context, bus drivers.I2CBus, opts Options) ([]devices.Descriptor, error) {
+	if opts.First == 0 {
+		opts.First = FirstAddr
+	}
+	if opts.Last == 0 {
+		opts.Last = LastAddr
+	}
+	if opts.First > opts.Last || opts.Last > 0x7f {
+		return nil, fmt.Errorf("i2cscan: invalid address range 0x%02x-0x%02x", opts.First, opts.Last)
+	}
+	var quick drivers.I2CQuickWriter
+	if opts.Quick {
+		// A zero-length Tx is no substitute: Linux returns success
+		// without touching the bus, and every address would answer.
+		var ok bool
+		if quick, ok = bus.(drivers.I2CQuickWriter); !ok {
+			return nil, fmt.Errorf("i2cscan: %s does not support quick writes", bus)
+		}
+	}
+
+	found := []devices.Descriptor{}
+	for addr := opts.First; addr <= opts.Last; addr++ {
+		if err := ctx.Err(); err != nil {
+			return found, err
+		}
+		if !probe(bus, quick, addr) {
+			continue
+		}
+		found = append(found, Identify(bus, opts.Bus, addr))
+	}
+	return found, nil
+}
+
+// probe reports whether a device acknowledges addr, with a quick write
+// if quick is set.
+func probe(bus drivers.I2CBus, quick drivers.I2CQuickWriter, addr uint16) bool {
+	if quick != nil {
+		return quick.QuickWrite(addr) == nil
+	}
+	var b [1]byte
+	return bus.Tx(addr, nil, b[:]) == nil
+}
+
+// Identify describes the device at addr, reading ID registers where the
+// address belongs to a known chip. Unknown devices get kind "i2c".
+func Identify(bus drivers.I2CBus, busName string, addr uint16) devices.Descriptor {
+	dev := drivers.NewI2CDevice(bus, addr)
+	attrs := map[string]string{
+		"bus":  busName,
+		"addr": fmt.Sprintf("0x%02x", addr),
+	}
+	desc := devices.Descriptor{
+		Name:       fmt.Sprintf("i2c_%02x", addr),
+		Kind:       "i2c",
+		Tags:       []string{"i2c", "scanned"},
+		Attributes: attrs,
+	}
+	chip := func(kind, name string) {
+		desc.Name = fmt.Sprintf("%s_%02x", name, addr)
+		desc.Kind = kind
+		desc.Tags = append(desc.Tags, name)
+		attrs["chip"] = name
+	}
+
+	switch addr {
+	case 0x76, 0x77:
+		id, err := dev.ReadReg8(bmxChipIDReg)
+		if err != nil {
+			break
+		}
+		attrs["chip_id"] = fmt.Sprintf("0x%02x", id)
+		switch id {
+		case 0x60:
+			chip("bme280", ChipBME280)
+		case 0x56, 0x57, 0x58:
+			// The bme280 device reads the BMP280 too (no humidity).
+			chip("bme280", ChipBMP280)
+		}
+
+	case 0x48, 0x49, 0x4a, 0x4b:
+		cfg, err := dev.ReadReg16(adsConfigReg)
+		if err != nil {
+			break
+		}
+		attrs["config"] = fmt.Sprintf("0x%04x", cfg)
+		if cfg == adsConfigReset {
+			chip("ads1115", ChipADS1115)
+		}
+
+	case 0x3c, 0x3d:
+		chip("oled", ChipSSD1306)
+	}
+	return desc
+}
@@ -0,0 +1,118 @@
+package i2cscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices/drivers"
+)
+
+// newBench returns a bus with one of each known chip and an unknown one.
+func newBench(t *testing.T) *drivers.SimI2CBus {
+	t.Helper()
+	bus := drivers.NewSimI2CBus("1")
+
+	bme := drivers.NewRegisterMap()
+	bme.SetBytes(bmxChipIDReg, 0x60)
+	bmp := drivers.NewRegisterMap()
+	bmp.SetBytes(bmxChipIDReg, 0x58)
+
+	ads := drivers.NewRegisterMap16()
+	ads.Set(adsConfigReg, adsConfigReset)
+	busyADS := drivers.NewRegisterMap16()
+	busyADS.Set(adsConfigReg, 0xc383) // reconfigured since reset
+
+	oled := drivers.I2CChipFunc(func(w, r []byte) error { return nil })
+
+	require.NoError(t, bus.Attach(0x77, bme))
+	require.NoError(t, bus.Attach(0x76, bmp))
+	require.NoError(t, bus.Attach(0x48, ads))
+	require.NoError(t, bus.Attach(0x49, busyADS))
+	require.NoError(t, bus.Attach(0x3c, oled))
+	require.NoError(t, bus.Attach(0x20, drivers.NewRegisterMap()))
+	return bus
+}
+
+func TestScanIdentifiesChips(t *testing.T) {
+	t.Parallel()
+
+	found, err := Scan(context.Background(), newBench(t), Options{Bus: "1"})
+	require.NoError(t, err)
+
+	type row struct{ name, kind, chip string }
+	var got []row
+	for _, d := range found {
+		got = append(got, row{d.Name, d.Kind, d.Attributes["chip"]})
+		assert.Equal(t, "1", d.Attributes["bus"])
+	}
+	assert.Equal(t, []row{
+		{"i2c_20", "i2c", ""},
+		{"ssd1306_3c", "oled", ChipSSD1306},
+		{"ads1115_48", "ads1115", ChipADS1115},
+		{"i2c_49", "i2c", ""},
+		{"bmp280_76", "bme280", ChipBMP280},
+		{"bme280_77", "bme280", ChipBME280},
+	}, got)
+
+	assert.Equal(t, "0x77", found[5].Attributes["addr"])
+	assert.Equal(t, "0x60", found[5].Attributes["chip_id"])
+	assert.Equal(t, "0xc383", found[3].Attributes["config"])
+	assert.Contains(t, found[5].Tags, "scanned")
+}
+
+func TestScanOptions(t *testing.T) {
+	t.Parallel()
+
+	bus := newBench(t)
+
+	found, err := Scan(context.Background(), bus, Options{First: 0x70, Quick: true})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "bmp280_76", found[0].Name)
+
+	_, err = Scan(context.Background(), bus, Options{First: 0x50, Last: 0x40})
+	assert.Error(t, err)
+	_, err = Scan(context.Background(), bus, Options{Last: 0x80})
+	assert.Error(t, err)
+
+	found, err = Scan(context.Background(), drivers.NewSimI2CBus("empty"), Options{})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Scan(ctx, bus, Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// emptyTxBus acks an empty Tx at every address, as Linux's i2c-dev does
+// without touching the bus, and reads nothing.
+type emptyTxBus struct{ *drivers.SimI2CBus }
+
+func (emptyTxBus) Tx(addr uint16, w, r []byte) error {
+	if len(w) == 0 && len(r) == 0 {
+		return nil
+	}
+	return drivers.ErrI2CNoDevice
+}
+
+func TestScanQuickIsNotEmptyTx(t *testing.T) {
+	t.Parallel()
+
+	// No QuickWrite: quick mode refuses rather than find 117 devices.
+	var bus drivers.I2CBus = struct{ drivers.I2CBus }{emptyTxBus{drivers.NewSimI2CBus("1")}}
+	found, err := Scan(context.Background(), bus, Options{Quick: true})
+	require.Error(t, err)
+	assert.Empty(t, found)
+
+	// With QuickWrite, only the attached chip answers.
+	sim := drivers.NewSimI2CBus("1")
+	require.NoError(t, sim.Attach(0x3c, drivers.I2CChipFunc(func(w, r []byte) error { return nil })))
+	found, err = Scan(context.Background(), emptyTxBus{sim}, Options{Quick: true})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "ssd1306_3c", found[0].Name)
+}
@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -30,11 +31,47 @@ const (
 	EdgeBoth Edge = "both"
 )
 
+// Valid reports whether b is one of the Bias constants or "", which
+// means BiasDefault.
+func (b Bias) Valid() bool {
+	switch b {
+	case "", BiasDefault, BiasPullUp, BiasPullDown:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether e is one of the Edge constants or "", which
+// means EdgeBoth.
+func (e Edge) Valid() bool {
+	switch e {
+	case "", EdgeNone, EdgeRising, EdgeFalling, EdgeBoth:
+		return true
+	}
+	return false
+}
+
+// checkInput validates the edge and bias passed to Factory.OpenInput.
+func checkInput(edge Edge, bias Bias) error {
+	if !edge.Valid() {
+		return fmt.Errorf("gpio: invalid edge %q (want none, rising, falling or both)", edge)
+	}
+	if !bias.Valid() {
+		return fmt.Errorf("gpio: invalid bias %q (want default, pullup or pulldown)", bias)
+	}
+	return nil
+}
+
 // LineEvent describes a GPIO edge notification.
 type LineEvent struct {
-	Time  time.Time
-	Edge  Edge
+	// Time is when the edge happened (the kernel timestamp where available).
+	Time time.Time
+	Edge Edge
+	// Value is the line level after the edge.
 	Value bool
+	// Seq numbers the events of a line, starting at 1. A gap between
+	// consecutive events means events were dropped on the way.
+	Seq uint64
 }
 
 // InputLine reads values and emits edge events.
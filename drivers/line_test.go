@@ -17,4 +17,10 @@ func TestLineConstants(t *testing.T) {
 	assert.Equal(t, Edge("rising"), EdgeRising)
 	assert.Equal(t, Edge("falling"), EdgeFalling)
 	assert.Equal(t, Edge("both"), EdgeBoth)
+
+	assert.True(t, Edge("").Valid())
+	assert.True(t, EdgeBoth.Valid())
+	assert.False(t, Edge("fallng").Valid())
+	assert.True(t, Bias("").Valid())
+	assert.False(t, Bias("up").Valid())
 }
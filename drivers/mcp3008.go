@@ -0,0 +1,102 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MCP3008MaxHz is the fastest clock the MCP3008 supports at 2.7V; it
+// runs faster at 5V, but this is safe on a 3.3V Pi.
+const MCP3008MaxHz = 1_350_000
+
+// MCP3008 is a 10-bit, 8-channel SPI ADC.
+//
+// Channels 0-7 are read single-ended. Differential reads measure
+// adjacent pairs (0/1, 2/3, 4/5, 6/7) in either direction; the chip is
+// unipolar, so a negative difference reads as 0.
+type MCP3008 struct {
+	mu   sync.Mutex
+	port SPIPort
+	vref float64
+}
+
+// MCP3008Opts configures an MCP3008.
+type MCP3008Opts struct {
+	// VRef is the voltage on the VREF pin. Default DefaultADCVRef.
+	VRef float64
+}
+
+// NewMCP3008 returns an MCP3008 on port, which should run in mode 0 at
+// no more than MCP3008MaxHz. Close closes port.
+func NewMCP3008(port SPIPort, opts MCP3008Opts) *MCP3008 {
+	if opts.VRef == 0 {
+		opts.VRef = DefaultADCVRef
+	}
+	return &MCP3008{port: port, vref: opts.VRef}
+}
+
+// ReadRaw returns the 10-bit conversion of a single-ended channel.
+func (m *MCP3008) ReadRaw(ctx context.Context, channel int) (uint16, error) {
+	if channel < 0 || channel > 7 {
+		return 0, fmt.Errorf("mcp3008: invalid channel %d", channel)
+	}
+	return m.convert(ctx, true, channel)
+}
+
+// ReadVolts reads a single-ended channel.
+func (m *MCP3008) ReadVolts(ctx context.Context, channel int) (float64, error) {
+	code, err := m.ReadRaw(ctx, channel)
+	return m.volts(code), err
+}
+
+// ReadDiffRaw returns the 10-bit conversion of plus relative to minus.
+func (m *MCP3008) ReadDiffRaw(ctx context.Context, plus, minus int) (uint16, error) {
+	if plus < 0 || plus > 7 || minus != plus^1 {
+		return 0, fmt.Errorf("mcp3008: invalid differential pair %d-%d", plus, minus)
+	}
+	// In differential mode the select bits name the positive input.
+	return m.convert(ctx, false, plus)
+}
+
+// ReadDiffVolts reads plus relative to minus.
+func (m *MCP3008) ReadDiffVolts(ctx context.Context, plus, minus int) (float64, error) {
+	code, err := m.ReadDiffRaw(ctx, plus, minus)
+	return m.volts(code), err
+}
+
+// convert runs one conversion: a start bit, the mode and channel bits,
+// then the 10-bit result clocked back in the last 10 bits.
+func (m *MCP3008) convert(ctx context.Context, single bool, sel int) (uint16, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	cmd := byte(sel) << 4
+	if single {
+		cmd |= 0x80
+	}
+	w := []byte{0x01, cmd, 0x00}
+	r := make([]byte, len(w))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.port.Tx(w, r); err != nil {
+		return 0, fmt.Errorf("mcp3008: %w", err)
+	}
+	return uint16(r[1]&0x03)<<8 | uint16(r[2]), nil
+}
+
+func (m *MCP3008) volts(code uint16) float64 {
+	return float64(code) * m.vref / 1024
+}
+
+func (m *MCP3008) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.port.Close()
+}
+
+var _ DiffADC = (*MCP3008)(nil)
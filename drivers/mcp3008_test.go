@@ -0,0 +1,122 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mcp3008Model answers conversions like the chip, with each input held at
+// the code in in.
+func mcp3008Model(in [8]uint16) func(w, r []byte) error {
+	return func(w, r []byte) error {
+		if len(w) != 3 || w[0] != 0x01 {
+			return errors.New("mcp3008 model: bad command")
+		}
+		sel := int(w[1]>>4) & 0x07
+		var code uint16
+		if w[1]&0x80 != 0 {
+			code = in[sel]
+		} else if plus, minus := in[sel], in[sel^1]; plus > minus {
+			code = plus - minus
+		}
+		r[0], r[1], r[2] = 0xff, byte(code>>8)&0x03, byte(code)
+		return nil
+	}
+}
+
+func TestMCP3008SingleEnded(t *testing.T) {
+	t.Parallel()
+
+	port := NewMockSPIPort("SPI0.0")
+	port.Handle(mcp3008Model([8]uint16{0, 100, 200, 300, 400, 500, 512, 1023}))
+	m := NewMCP3008(port, MCP3008Opts{})
+	ctx := context.Background()
+
+	for ch, want := range []uint16{0, 100, 200, 300, 400, 500, 512, 1023} {
+		code, err := m.ReadRaw(ctx, ch)
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "channel %d", ch)
+	}
+	assert.Equal(t, []byte{0x01, 0xf0, 0x00}, port.Writes()[7], "start, single-ended, channel 7")
+
+	v, err := m.ReadVolts(ctx, 6)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.65, v, 1e-9)
+
+	_, err = m.ReadVolts(ctx, 8)
+	assert.ErrorContains(t, err, "invalid channel 8")
+	_, err = m.ReadVolts(ctx, -1)
+	assert.Error(t, err)
+}
+
+func TestMCP3008Differential(t *testing.T) {
+	t.Parallel()
+
+	port := NewMockSPIPort("SPI0.0")
+	port.Handle(mcp3008Model([8]uint16{600, 100, 0, 0, 0, 0, 0, 0}))
+	m := NewMCP3008(port, MCP3008Opts{VRef: 5.0})
+	ctx := context.Background()
+
+	code, err := m.ReadDiffRaw(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(500), code)
+	code, err = m.ReadDiffRaw(ctx, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0), code, "unipolar: negative reads as 0")
+	assert.Equal(t, []byte{0x01, 0x10, 0x00}, port.Writes()[1], "differential, CH1+ CH0-")
+
+	v, err := m.ReadDiffVolts(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 500*5.0/1024, v, 1e-9)
+
+	for _, pair := range [][2]int{{0, 2}, {1, 2}, {7, 7}, {8, 9}} {
+		_, err := m.ReadDiffVolts(ctx, pair[0], pair[1])
+		assert.ErrorContains(t, err, "invalid differential pair", pair)
+	}
+}
+
+func TestMCP3008Errors(t *testing.T) {
+	t.Parallel()
+
+	port := NewMockSPIPort("SPI0.0")
+	port.Expect([]byte{0x01, 0x80, 0x00}, []byte{0, 0x02, 0x01}).Fail(errors.New("bus fault"))
+	m := NewMCP3008(port, MCP3008Opts{})
+
+	code, err := m.ReadRaw(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x201), code)
+
+	_, err = m.ReadRaw(context.Background(), 0)
+	assert.ErrorContains(t, err, "mcp3008: bus fault")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.ReadVolts(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, m.Close())
+	_, err = m.ReadVolts(context.Background(), 0)
+	assert.Error(t, err, "port closed")
+}
+
+func TestADCConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ADCConfig{}.withDefaults()
+	require.NoError(t, err)
+	assert.Equal(t, ADCConfig{Chip: ChipADS1115, Addr: 0x48, VRef: DefaultADCVRef}, cfg)
+
+	cfg, err = ADCConfig{Chip: ChipMCP3008, Bus: "SPI0.1"}.withDefaults()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0), cfg.Addr)
+
+	n, err := ADCChannels(ChipMCP3008)
+	require.NoError(t, err)
+	assert.Equal(t, 8, n)
+	_, err = ADCChannels("lm35")
+	assert.ErrorIs(t, err, ErrUnknownADC)
+}
@@ -3,12 +3,64 @@ package drivers
 import (
 	"fmt"
 	"io"
+	"os"
+	"time"
 )
 
+// Parity is the parity bit setting of a serial port.
+type Parity string
+
+const (
+	ParityNone  Parity = "none"
+	ParityOdd   Parity = "odd"
+	ParityEven  Parity = "even"
+	ParityMark  Parity = "mark"  // parity bit always 1
+	ParitySpace Parity = "space" // parity bit always 0
+)
+
+// ErrSerialTimeout is returned by Read when SerialConfig.ReadTimeout
+// passes without a byte arriving. It matches os.ErrDeadlineExceeded.
+var ErrSerialTimeout = fmt.Errorf("serial: read timeout: %w", os.ErrDeadlineExceeded)
+
 // SerialConfig describes how to open a serial port.
+//
+// The zero value of every field but Port and Baud gives 8N1 with no flow
+// control and reads that block until data arrives.
 type SerialConfig struct {
 	Port string
+
+	// Baud is the line rate in bits per second. Any rate the UART can
+	// generate works, e.g. 230400, 460800 or 921600, not only the
+	// standard ones.
 	Baud int
+
+	// DataBits is 5-8. Default 8.
+	DataBits int
+
+	// Parity defaults to ParityNone.
+	Parity Parity
+
+	// StopBits is 1 or 2. Default 1.
+	StopBits int
+
+	// RTSCTS enables hardware flow control.
+	RTSCTS bool
+
+	// XONXOFF enables software flow control, in both directions.
+	XONXOFF bool
+
+	// ReadTimeout bounds how long a Read waits for the first byte; it
+	// then fails with ErrSerialTimeout. 0 waits forever.
+	ReadTimeout time.Duration
+
+	// InterByteTimeout makes a Read that has received data keep filling
+	// its buffer until the line is idle this long, so a burst such as an
+	// NMEA sentence arrives in one Read. 0 returns what is available.
+	InterByteTimeout time.Duration
+
+	// Exclusive locks the port (TIOCEXCL) so no one else can open it
+	// while it is open here.
+	Exclusive bool
 }
 
 // SerialPort is an opened serial port.
@@ -24,6 +76,28 @@ type SerialFactory interface {
 	OpenSerial(cfg SerialConfig) (SerialPort, error)
 }
 
+func (c SerialConfig) withDefaults() SerialConfig {
+	if c.DataBits == 0 {
+		c.DataBits = 8
+	}
+	if c.Parity == "" {
+		c.Parity = ParityNone
+	}
+	if c.StopBits == 0 {
+		c.StopBits = 1
+	}
+	return c
+}
+
+// frame returns the framing in the usual short form, e.g. "8N1".
+func (c SerialConfig) frame() string {
+	p := "N"
+	if c.Parity != "" {
+		p = string(c.Parity[0] - 'a' + 'A')
+	}
+	return fmt.Sprintf("%d%s%d", c.DataBits, p, c.StopBits)
+}
+
 func validateSerialConfig(cfg SerialConfig) error {
 	if cfg.Port == "" {
 		return fmt.Errorf("serial: Port is required")
@@ -31,5 +105,19 @@ func validateSerialConfig(cfg SerialConfig) error {
 	if cfg.Baud <= 0 {
 		return fmt.Errorf("serial: Baud must be > 0")
 	}
+	if cfg.DataBits != 0 && (cfg.DataBits < 5 || cfg.DataBits > 8) {
+		return fmt.Errorf("serial: DataBits must be 5-8, got %d", cfg.DataBits)
+	}
+	switch cfg.Parity {
+	case "", ParityNone, ParityOdd, ParityEven, ParityMark, ParitySpace:
+	default:
+		return fmt.Errorf("serial: invalid Parity %q (want none, odd, even, mark or space)", cfg.Parity)
+	}
+	if cfg.StopBits != 0 && cfg.StopBits != 1 && cfg.StopBits != 2 {
+		return fmt.Errorf("serial: StopBits must be 1 or 2, got %d", cfg.StopBits)
+	}
+	if cfg.ReadTimeout < 0 || cfg.InterByteTimeout < 0 {
+		return fmt.Errorf("serial: timeouts must be >= 0")
+	}
 	return nil
 }
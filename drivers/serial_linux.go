@@ -3,6 +3,7 @@
 package drivers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -12,16 +13,20 @@ import (
 
 // LinuxSerialFactory opens a configured serial port on Linux.
 //
-// It uses termios to set 8N1 and the requested baud rate.
+// It uses termios2 to set the framing, flow control and baud rate; any
+// rate is set exactly with BOTHER rather than picked from the B* table.
 type LinuxSerialFactory struct{}
 
 func (LinuxSerialFactory) OpenSerial(cfg SerialConfig) (SerialPort, error) {
 	if err := validateSerialConfig(cfg); err != nil {
 		return nil, err
 	}
+	cfg = cfg.withDefaults()
 
-	// Open non-blocking first, configure termios, then clear O_NONBLOCK.
-	fd, err := unix.Open(cfg.Port, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	// The fd stays non-blocking: os.NewFile then hands it to the runtime
+	// poller, which gives us read deadlines and lets Close interrupt a
+	// pending Read.
+	fd, err := unix.Open(cfg.Port, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
 	if err != nil {
 		return nil, fmt.Errorf("serial: open %s: %w", cfg.Port, err)
 	}
@@ -34,13 +39,13 @@ func (LinuxSerialFactory) OpenSerial(cfg SerialConfig) (SerialPort, error) {
 		}
 	}()
 
-	if err := configureTermios(fd, cfg.Baud); err != nil {
-		return nil, err
+	if cfg.Exclusive {
+		if err := unix.IoctlSetInt(fd, unix.TIOCEXCL, 0); err != nil {
+			return nil, fmt.Errorf("serial: lock %s: %w", cfg.Port, err)
+		}
 	}
-
-	// Clear O_NONBLOCK so reads behave as expected.
-	if err := unix.SetNonblock(fd, false); err != nil {
-		return nil, fmt.Errorf("serial: set blocking %s: %w", cfg.Port, err)
+	if err := configureTermios(fd, cfg); err != nil {
+		return nil, err
 	}
 
 	f := os.NewFile(uintptr(fd), cfg.Port)
@@ -49,72 +54,134 @@ func (LinuxSerialFactory) OpenSerial(cfg SerialConfig) (SerialPort, error) {
 	}
 
 	ok = true
-	return &linuxSerialPort{file: f, port: cfg.Port, baud: cfg.Baud}, nil
+	return &linuxSerialPort{file: f, cfg: cfg}, nil
 }
 
 type linuxSerialPort struct {
 	file *os.File
-	port string
-	baud int
+	cfg  SerialConfig
+}
+
+// Read waits up to ReadTimeout for data, then with an InterByteTimeout
+// keeps reading until b is full or the line goes quiet.
+func (p *linuxSerialPort) Read(b []byte) (int, error) {
+	var deadline time.Time
+	if p.cfg.ReadTimeout > 0 {
+		deadline = time.Now().Add(p.cfg.ReadTimeout)
+	}
+	if err := p.file.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	n, err := p.file.Read(b)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return n, ErrSerialTimeout
+	}
+	if err != nil || p.cfg.InterByteTimeout <= 0 {
+		return n, err
+	}
+
+	for n < len(b) {
+		if err := p.file.SetReadDeadline(time.Now().Add(p.cfg.InterByteTimeout)); err != nil {
+			return n, err
+		}
+		m, err := p.file.Read(b[n:])
+		n += m
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
 }
 
-func (p *linuxSerialPort) Read(b []byte) (int, error)  { return p.file.Read(b) }
 func (p *linuxSerialPort) Write(b []byte) (int, error) { return p.file.Write(b) }
-func (p *linuxSerialPort) Close() error                { return p.file.Close() }
-func (p *linuxSerialPort) String() string              { return fmt.Sprintf("%s@%d", p.port, p.baud) }
 
-func configureTermios(fd int, baud int) error {
-	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
-	if err != nil {
-		return fmt.Errorf("serial: ioctl TCGETS: %w", err)
+func (p *linuxSerialPort) Close() error {
+	if p.cfg.Exclusive {
+		if c, err := p.file.SyscallConn(); err == nil {
+			_ = c.Control(func(fd uintptr) { _ = unix.IoctlSetInt(int(fd), unix.TIOCNXCL, 0) })
+		}
 	}
+	return p.file.Close()
+}
+
+// String returns the port and rate, plus the framing unless it is 8N1.
+func (p *linuxSerialPort) String() string {
+	s := fmt.Sprintf("%s@%d", p.cfg.Port, p.cfg.Baud)
+	if f := p.cfg.frame(); f != "8N1" {
+		s += " " + f
+	}
+	return s
+}
 
-	speed, err := baudToUnix(baud)
+// configureTermios puts fd in raw mode with the framing, flow control
+// and rate of cfg, which has its defaults filled in.
+func configureTermios(fd int, cfg SerialConfig) error {
+	t, err := unix.IoctlGetTermios(fd, tcgets2)
 	if err != nil {
-		return err
+		return fmt.Errorf("serial: ioctl TCGETS2: %w", err)
 	}
 
-	// Raw-ish 8N1.
+	// Raw mode: no line editing, echo, signals or output processing.
 	t.Iflag = 0
 	t.Oflag = 0
 	t.Lflag = 0
-	t.Cflag = unix.CS8 | unix.CREAD | unix.CLOCAL
+	t.Cflag = unix.CREAD | unix.CLOCAL
+
+	switch cfg.DataBits {
+	case 5:
+		t.Cflag |= unix.CS5
+	case 6:
+		t.Cflag |= unix.CS6
+	case 7:
+		t.Cflag |= unix.CS7
+	default:
+		t.Cflag |= unix.CS8
+	}
+
+	switch cfg.Parity {
+	case ParityOdd:
+		t.Cflag |= unix.PARENB | unix.PARODD
+	case ParityEven:
+		t.Cflag |= unix.PARENB
+	case ParityMark:
+		t.Cflag |= unix.PARENB | unix.PARODD | unix.CMSPAR
+	case ParitySpace:
+		t.Cflag |= unix.PARENB | unix.CMSPAR
+	}
+	if cfg.Parity != ParityNone {
+		t.Iflag |= unix.INPCK
+	}
 
-	// Disable flow control.
-	t.Cflag &^= unix.CRTSCTS
+	if cfg.StopBits == 2 {
+		t.Cflag |= unix.CSTOPB
+	}
+	if cfg.RTSCTS {
+		t.Cflag |= unix.CRTSCTS
+	}
+	if cfg.XONXOFF {
+		t.Iflag |= unix.IXON | unix.IXOFF
+		t.Cc[unix.VSTART] = 0x11 // DC1
+		t.Cc[unix.VSTOP] = 0x13  // DC3
+	}
 
-	// VMIN/VTIME: block until at least 1 byte.
+	// Reads are bounded by the poller, not VMIN/VTIME.
 	t.Cc[unix.VMIN] = 1
 	t.Cc[unix.VTIME] = 0
 
-	// Set baud.
-	t.Ispeed = uint32(speed)
-	t.Ospeed = uint32(speed)
+	// Set baud, input and output alike.
+	t.Cflag &^= unix.CBAUD | unix.CBAUD<<unix.IBSHIFT
+	t.Cflag |= unix.BOTHER | unix.BOTHER<<unix.IBSHIFT
+	t.Ispeed = uint32(cfg.Baud)
+	t.Ospeed = uint32(cfg.Baud)
 
-	if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
-		return fmt.Errorf("serial: ioctl TCSETS: %w", err)
+	if err := unix.IoctlSetTermios(fd, tcsets2, t); err != nil {
+		return fmt.Errorf("serial: ioctl TCSETS2 (%d baud): %w", cfg.Baud, err)
 	}
 
 	// Give the line a moment to settle.
 	time.Sleep(10 * time.Millisecond)
 	return nil
 }
-
-func baudToUnix(baud int) (uint32, error) {
-	switch baud {
-	case 4800:
-		return unix.B4800, nil
-	case 9600:
-		return unix.B9600, nil
-	case 19200:
-		return unix.B19200, nil
-	case 38400:
-		return unix.B38400, nil
-	case 57600:
-		return unix.B57600, nil
-	case 115200:
-		return unix.B115200, nil
-	default:
-		return 0, fmt.Errorf("serial: unsupported baud %d (supported: 4800,9600,19200,38400,57600,115200)", baud)
-	}
-}
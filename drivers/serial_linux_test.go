@@ -0,0 +1,142 @@
+//go:build linux
+
+package drivers
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/rustyeddy/devices/drivers/serialtest"
+)
+
+func openPty(t *testing.T, cfg SerialConfig) (*serialtest.Pty, SerialPort) {
+	t.Helper()
+	pty := serialtest.New(t)
+	cfg.Port = pty.Path()
+	port, err := LinuxSerialFactory{}.OpenSerial(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = port.Close() })
+	return pty, port
+}
+
+func TestLinuxSerialTermios(t *testing.T) {
+	t.Parallel()
+
+	pty, port := openPty(t, SerialConfig{Baud: 921600, DataBits: 7, Parity: ParityEven, StopBits: 2, RTSCTS: true, XONXOFF: true})
+	assert.Equal(t, pty.Path()+"@921600 7E2", port.String())
+
+	// A pty pins CS8 and clears PARENB; the rest of the framing sticks.
+	tio, err := pty.Termios()
+	require.NoError(t, err)
+	const frame = unix.PARODD | unix.CMSPAR | unix.CSTOPB | unix.CRTSCTS
+	assert.Equal(t, uint32(unix.CSTOPB|unix.CRTSCTS), tio.Cflag&frame)
+	assert.Equal(t, uint32(unix.INPCK|unix.IXON|unix.IXOFF), tio.Iflag)
+	assert.Equal(t, uint8(0x11), tio.Cc[unix.VSTART])
+	assert.Equal(t, uint8(0x13), tio.Cc[unix.VSTOP])
+	assert.Zero(t, tio.Lflag)
+	assert.Zero(t, tio.Oflag)
+	assert.Equal(t, uint32(921600), tio.Ispeed)
+	assert.Equal(t, uint32(921600), tio.Ospeed)
+
+	pty, port = openPty(t, SerialConfig{Baud: 9600, Parity: ParityMark})
+	assert.Equal(t, pty.Path()+"@9600 8M1", port.String())
+	tio, err = pty.Termios()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(unix.PARODD|unix.CMSPAR), tio.Cflag&frame)
+	assert.Equal(t, uint32(unix.INPCK), tio.Iflag)
+	assert.Equal(t, uint32(9600), tio.Ospeed)
+
+	_, port = openPty(t, SerialConfig{Baud: 115200})
+	assert.NotContains(t, port.String(), " ")
+}
+
+func TestLinuxSerialReadWrite(t *testing.T) {
+	t.Parallel()
+
+	pty, port := openPty(t, SerialConfig{Baud: 9600})
+
+	_, err := port.Write([]byte("$PMTK220,1000*1F\r\n"))
+	require.NoError(t, err)
+	pty.Expect(t, "$PMTK220,1000*1F\r\n")
+
+	_, err = pty.WriteString("line\r\n")
+	require.NoError(t, err)
+	b := make([]byte, 16)
+	n, err := port.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "line\r\n", string(b[:n]))
+}
+
+func TestLinuxSerialTimeouts(t *testing.T) {
+	t.Parallel()
+
+	pty, port := openPty(t, SerialConfig{Baud: 9600, ReadTimeout: 30 * time.Millisecond, InterByteTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := port.Read(make([]byte, 8))
+	require.ErrorIs(t, err, ErrSerialTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// Two writes 10ms apart arrive in one Read.
+	go func() {
+		_, _ = pty.WriteString("$GP")
+		time.Sleep(10 * time.Millisecond)
+		_, _ = pty.WriteString("GGA")
+	}()
+	b := make([]byte, 32)
+	n, err := port.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "$GPGGA", string(b[:n]))
+}
+
+func TestLinuxSerialCloseAndDisconnect(t *testing.T) {
+	t.Parallel()
+
+	_, port := openPty(t, SerialConfig{Baud: 9600})
+	done := make(chan error, 1)
+	go func() {
+		_, err := port.Read(make([]byte, 1))
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, port.Close())
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, os.ErrClosed)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Close did not interrupt Read")
+	}
+
+	pty, port := openPty(t, SerialConfig{Baud: 9600})
+	go func() {
+		_, err := port.Read(make([]byte, 1))
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, pty.Disconnect())
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		require.FailNow(t, "disconnect did not end Read")
+	}
+	_, err := port.Write([]byte("x"))
+	assert.Error(t, err)
+}
+
+func TestLinuxSerialExclusive(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() == 0 {
+		t.Skip("root may open a TIOCEXCL port anyway")
+	}
+
+	pty, _ := openPty(t, SerialConfig{Baud: 9600, Exclusive: true})
+	_, err := LinuxSerialFactory{}.OpenSerial(SerialConfig{Port: pty.Path(), Baud: 9600})
+	assert.ErrorIs(t, err, unix.EBUSY)
+}
@@ -0,0 +1,108 @@
+package drivers
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// MockSerialFactory is a portable SerialFactory for dev/CI/examples where
+// no hardware exists. Its ports are loopbacks: whatever is written can be
+// read back, so a test or simulator can feed a device by writing to it.
+type MockSerialFactory struct {
+	mu    sync.Mutex
+	ports map[string]*MockSerialPort
+}
+
+// NewMockSerialFactory constructs an empty MockSerialFactory.
+func NewMockSerialFactory() *MockSerialFactory {
+	return &MockSerialFactory{ports: map[string]*MockSerialPort{}}
+}
+
+// OpenSerial returns a new loopback port.
+func (f *MockSerialFactory) OpenSerial(cfg SerialConfig) (SerialPort, error) {
+	if err := validateSerialConfig(cfg); err != nil {
+		return nil, err
+	}
+	p := &MockSerialPort{name: cfg.Port, timeout: cfg.ReadTimeout}
+	p.cond = sync.NewCond(&p.mu)
+
+	f.mu.Lock()
+	f.ports[cfg.Port] = p
+	f.mu.Unlock()
+	return p, nil
+}
+
+// Port returns the port most recently opened under name.
+func (f *MockSerialFactory) Port(name string) (*MockSerialPort, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.ports[name]
+	return p, ok
+}
+
+// MockSerialPort is an in-memory loopback serial port.
+type MockSerialPort struct {
+	name    string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	closed bool
+}
+
+// Read blocks until data is available, or fails with ErrSerialTimeout
+// after the configured ReadTimeout. After Close it drains what is
+// buffered, then returns io.EOF.
+func (p *MockSerialPort) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var expired bool
+	if p.timeout > 0 && p.buf.Len() == 0 && !p.closed {
+		t := time.AfterFunc(p.timeout, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			expired = true
+			p.cond.Broadcast()
+		})
+		defer t.Stop()
+	}
+	for p.buf.Len() == 0 && !p.closed {
+		if expired {
+			return 0, ErrSerialTimeout
+		}
+		p.cond.Wait()
+	}
+	if p.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return p.buf.Read(b)
+}
+
+// Write queues b for Read.
+func (p *MockSerialPort) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, _ := p.buf.Write(b)
+	p.cond.Broadcast()
+	return n, nil
+}
+
+// Close unblocks pending reads.
+func (p *MockSerialPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.cond.Broadcast()
+	return nil
+}
+
+func (p *MockSerialPort) String() string { return "mock:" + p.name }
+
+var _ SerialFactory = (*MockSerialFactory)(nil)
+var _ SerialPort = (*MockSerialPort)(nil)
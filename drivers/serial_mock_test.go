@@ -0,0 +1,77 @@
+package drivers
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockSerialLoopback(t *testing.T) {
+	t.Parallel()
+
+	f := NewMockSerialFactory()
+	_, err := f.OpenSerial(SerialConfig{Port: "gps"})
+	require.Error(t, err, "baud is required")
+
+	p, err := f.OpenSerial(SerialConfig{Port: "gps", Baud: 9600})
+	require.NoError(t, err)
+	assert.Equal(t, "mock:gps", p.String())
+
+	got, ok := f.Port("gps")
+	require.True(t, ok)
+	assert.Same(t, p, got)
+
+	lines := make(chan string, 2)
+	go func() {
+		sc := bufio.NewScanner(p)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	_, err = io.WriteString(p, "$GPGGA,1\n$GPRMC,2\n")
+	require.NoError(t, err)
+	for _, want := range []string{"$GPGGA,1", "$GPRMC,2"} {
+		select {
+		case l := <-lines:
+			assert.Equal(t, want, l)
+		case <-time.After(time.Second):
+			t.Fatal("no line read")
+		}
+	}
+
+	require.NoError(t, p.Close())
+	select {
+	case _, ok := <-lines:
+		assert.False(t, ok, "Close ends reads with EOF")
+	case <-time.After(time.Second):
+		t.Fatal("read not unblocked by Close")
+	}
+	_, err = p.Write([]byte("x"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+func TestMockSerialReadTimeout(t *testing.T) {
+	t.Parallel()
+
+	f := NewMockSerialFactory()
+	p, err := f.OpenSerial(SerialConfig{Port: "gps", Baud: 9600, ReadTimeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = p.Read(make([]byte, 8))
+	assert.ErrorIs(t, err, ErrSerialTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	_, err = io.WriteString(p, "ok")
+	require.NoError(t, err)
+	b := make([]byte, 8)
+	n, err := p.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(b[:n]))
+}
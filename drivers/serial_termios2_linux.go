@@ -0,0 +1,11 @@
+//go:build linux && !ppc && !ppc64 && !ppc64le
+
+package drivers
+
+import "golang.org/x/sys/unix"
+
+// The termios2 requests, which carry the baud rate as a number.
+const (
+	tcgets2 = unix.TCGETS2
+	tcsets2 = unix.TCSETS2
+)
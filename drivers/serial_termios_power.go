@@ -0,0 +1,12 @@
+//go:build linux && (ppc || ppc64 || ppc64le)
+
+package drivers
+
+import "golang.org/x/sys/unix"
+
+// On PowerPC the plain termios already carries the baud rate as a
+// number, and there is no termios2.
+const (
+	tcgets2 = unix.TCGETS
+	tcsets2 = unix.TCSETS
+)
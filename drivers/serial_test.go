@@ -0,0 +1,42 @@
+package drivers
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSerialConfig(t *testing.T) {
+	t.Parallel()
+
+	ok := SerialConfig{Port: "/dev/ttyS0", Baud: 921600, DataBits: 7, Parity: ParityEven, StopBits: 2,
+		RTSCTS: true, XONXOFF: true, ReadTimeout: time.Second, InterByteTimeout: time.Millisecond, Exclusive: true}
+	require.NoError(t, validateSerialConfig(ok))
+
+	for _, c := range []struct {
+		cfg  SerialConfig
+		want string
+	}{
+		{SerialConfig{Baud: 9600}, "Port is required"},
+		{SerialConfig{Port: "p"}, "Baud must be > 0"},
+		{SerialConfig{Port: "p", Baud: 9600, DataBits: 9}, "DataBits must be 5-8"},
+		{SerialConfig{Port: "p", Baud: 9600, Parity: "high"}, `invalid Parity "high"`},
+		{SerialConfig{Port: "p", Baud: 9600, StopBits: 3}, "StopBits must be 1 or 2"},
+		{SerialConfig{Port: "p", Baud: 9600, ReadTimeout: -1}, "timeouts must be >= 0"},
+	} {
+		assert.ErrorContains(t, validateSerialConfig(c.cfg), c.want)
+	}
+}
+
+func TestSerialConfigFrame(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "8N1", SerialConfig{}.withDefaults().frame())
+	assert.Equal(t, "7E2", SerialConfig{DataBits: 7, Parity: ParityEven, StopBits: 2}.frame())
+	assert.Equal(t, "8M1", SerialConfig{Parity: ParityMark}.withDefaults().frame())
+	assert.True(t, errors.Is(ErrSerialTimeout, os.ErrDeadlineExceeded))
+}
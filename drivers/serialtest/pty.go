@@ -0,0 +1,117 @@
+// Package serialtest stands a pseudo-terminal in for a UART, so serial
+// ports and the devices reading them can be tested end to end without
+// hardware.
+//
+// The slave side of the pty is opened like any serial port, through
+// drivers.SerialConfig.Port; the test holds the master side and plays
+// the device at the other end of the wire:
+//
+//	pty := serialtest.New(t)
+//	port, err := drivers.LinuxSerialFactory{}.OpenSerial(drivers.SerialConfig{Port: pty.Path(), Baud: 9600})
+//	...
+//	pty.WriteString("$GPGGA,...\r\n") // the port reads this
+//	pty.Expect(t, "PMTK")            // the port wrote this
+//	pty.Disconnect()                 // the cable is pulled
+//
+// Termios reports the settings the port made, baud rate included, with
+// one caveat: the kernel pins a pty to CS8 and clears PARENB, so data
+// bits and parity show only through PARODD, CMSPAR and INPCK.
+//
+// Ptys are Linux only; elsewhere Open returns ErrUnsupported and New
+// skips the test.
+package serialtest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// ErrUnsupported is returned by Open where there are no ptys.
+var ErrUnsupported = errors.New("serialtest: ptys are not supported on this platform")
+
+// DefaultTimeout bounds how long Expect waits for the port's writes.
+const DefaultTimeout = 2 * time.Second
+
+// Pty is an open pseudo-terminal pair.
+type Pty struct {
+	master *os.File
+	path   string
+}
+
+// New opens a Pty that is closed when the test ends. It skips the test
+// where ptys are not supported and fails it on any other error.
+func New(t testing.TB) *Pty {
+	t.Helper()
+	p, err := Open()
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+// Path returns the slave device, e.g. "/dev/pts/3", to use as
+// SerialConfig.Port.
+func (p *Pty) Path() string { return p.path }
+
+// Master returns the master side, for tests that need more than the
+// helpers below. Read deadlines work on it.
+func (p *Pty) Master() *os.File { return p.master }
+
+// Write sends b down the line; the port reads it.
+func (p *Pty) Write(b []byte) (int, error) { return p.master.Write(b) }
+
+// WriteString sends s down the line; the port reads it.
+func (p *Pty) WriteString(s string) (int, error) { return p.master.WriteString(s) }
+
+// Next returns the next n bytes the port wrote, or what had arrived with
+// an error if they do not all arrive within timeout.
+func (p *Pty) Next(n int, timeout time.Duration) ([]byte, error) {
+	if err := p.master.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	defer p.master.SetReadDeadline(time.Time{})
+
+	b := make([]byte, n)
+	got := 0
+	for got < n {
+		m, err := p.master.Read(b[got:])
+		got += m
+		if err != nil {
+			return b[:got], fmt.Errorf("serialtest: read %d of %d bytes: %w", got, n, err)
+		}
+	}
+	return b, nil
+}
+
+// Expect fails the test unless the port's next writes are want,
+// arriving within DefaultTimeout.
+func (p *Pty) Expect(t testing.TB, want string) {
+	t.Helper()
+	got, err := p.Next(len(want), DefaultTimeout)
+	if err != nil {
+		t.Fatalf("serialtest: want %q, got %q: %v", want, got, err)
+	}
+	if string(got) != want {
+		t.Fatalf("serialtest: want %q, got %q", want, got)
+	}
+}
+
+// Disconnect closes the master side, as if the cable were pulled: the
+// port's pending and later reads return io.EOF and its writes fail with
+// EIO.
+func (p *Pty) Disconnect() error { return p.Close() }
+
+// Close closes the master side. Closing twice is not an error.
+func (p *Pty) Close() error {
+	if err := p.master.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package serialtest
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Open opens a pty pair through /dev/ptmx. The slave starts out raw, so
+// bytes pass through untouched even before a port configures it.
+func Open() (*Pty, error) {
+	m, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("serialtest: open /dev/ptmx: %w", err)
+	}
+
+	// ioctl through the raw conn: m.Fd() would make m blocking and lose
+	// read deadlines.
+	var n uint32
+	err = control(m, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return fmt.Errorf("serialtest: unlock pty: %w", err)
+		}
+		if n, err = unix.IoctlGetUint32(fd, unix.TIOCGPTN); err != nil {
+			return fmt.Errorf("serialtest: pty number: %w", err)
+		}
+		return makeRaw(fd)
+	})
+	if err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	return &Pty{master: m, path: fmt.Sprintf("/dev/pts/%d", n)}, nil
+}
+
+// Termios returns the slave's terminal settings, as the port under test
+// left them, baud rate included.
+func (p *Pty) Termios() (*unix.Termios, error) {
+	var t *unix.Termios
+	err := control(p.master, func(fd int) error {
+		var err error
+		t, err = unix.IoctlGetTermios(fd, tcgets2)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serialtest: get termios: %w", err)
+	}
+	return t, nil
+}
+
+// makeRaw turns off line editing, echo and output processing on the
+// slave (termios requests on the master act on the slave).
+func makeRaw(fd int) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return fmt.Errorf("serialtest: get termios: %w", err)
+	}
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag = t.Cflag&^(unix.CSIZE|unix.PARENB) | unix.CS8 | unix.CREAD | unix.CLOCAL
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
+		return fmt.Errorf("serialtest: set termios: %w", err)
+	}
+	return nil
+}
+
+func control(f *os.File, fn func(fd int) error) error {
+	c, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := c.Control(func(fd uintptr) { ferr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return ferr
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package serialtest
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// openSlave opens the far end of p the way a port would.
+func openSlave(t *testing.T, p *Pty) *os.File {
+	t.Helper()
+	f, err := os.OpenFile(p.Path(), os.O_RDWR|syscall.O_NOCTTY, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestPtyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	p := New(t)
+	require.Regexp(t, `^/dev/pts/\d+$`, p.Path())
+	s := openSlave(t, p)
+
+	// Raw from the start: no CR/LF translation and no echo.
+	_, err := p.WriteString("hi\r\n")
+	require.NoError(t, err)
+	b := make([]byte, 8)
+	n, err := s.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "hi\r\n", string(b[:n]))
+
+	_, err = s.WriteString("ok\n")
+	require.NoError(t, err)
+	p.Expect(t, "ok\n")
+
+	tio, err := p.Termios()
+	require.NoError(t, err)
+	assert.Zero(t, tio.Lflag&(unix.ICANON|unix.ECHO))
+	assert.Zero(t, tio.Oflag&unix.OPOST)
+}
+
+func TestPtyNextTimeout(t *testing.T) {
+	t.Parallel()
+
+	p := New(t)
+	s := openSlave(t, p)
+	_, err := s.WriteString("a")
+	require.NoError(t, err)
+
+	got, err := p.Next(2, 20*time.Millisecond)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Equal(t, "a", string(got))
+}
+
+func TestPtyDisconnect(t *testing.T) {
+	t.Parallel()
+
+	p := New(t)
+	s := openSlave(t, p)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Read(make([]byte, 1))
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, p.Disconnect())
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		require.FailNow(t, "read not interrupted by disconnect")
+	}
+	_, err := s.WriteString("x")
+	assert.ErrorIs(t, err, syscall.EIO)
+	assert.NoError(t, p.Close())
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package serialtest
+
+// Open returns ErrUnsupported: ptys are only implemented on Linux.
+func Open() (*Pty, error) { return nil, ErrUnsupported }
@@ -0,0 +1,8 @@
+//go:build linux && !ppc && !ppc64 && !ppc64le
+
+package serialtest
+
+import "golang.org/x/sys/unix"
+
+// tcgets2 reads the termios2, which carries the baud rate as a number.
+const tcgets2 = unix.TCGETS2
@@ -0,0 +1,9 @@
+//go:build linux && (ppc || ppc64 || ppc64le)
+
+package serialtest
+
+import "golang.org/x/sys/unix"
+
+// On PowerPC the plain termios already carries the baud rate, and there
+// is no termios2.
+const tcgets2 = unix.TCGETS
@@ -0,0 +1,63 @@
+package drivers
+
+import (
+	"fmt"
+)
+
+// DefaultSPIHz is the clock used when SPIConfig.MaxHz is 0.
+const DefaultSPIHz = 1_000_000
+
+// SPIPort is one device on an SPI bus: a controller and a chip select.
+type SPIPort interface {
+	// Tx clocks out w while clocking len(r) bytes into r. SPI is full
+	// duplex, so w and r are usually the same length; either may be
+	// empty.
+	Tx(w, r []byte) error
+
+	Close() error
+	String() string
+}
+
+// SPIConfig selects and configures an SPI port.
+type SPIConfig struct {
+	// Port names the controller and chip select: "SPI0.0" or
+	// "/dev/spidev0.0" on a Pi, "" for the first available.
+	Port string
+
+	// Mode is the SPI mode, 0-3 (clock polarity and phase).
+	Mode int
+
+	// MaxHz caps the clock. Default DefaultSPIHz.
+	MaxHz int64
+
+	// Bits is the word size. Default 8.
+	Bits int
+}
+
+// SPIFactory opens SPI ports.
+type SPIFactory interface {
+	OpenSPI(cfg SPIConfig) (SPIPort, error)
+}
+
+func (c SPIConfig) withDefaults() SPIConfig {
+	if c.MaxHz == 0 {
+		c.MaxHz = DefaultSPIHz
+	}
+	if c.Bits == 0 {
+		c.Bits = 8
+	}
+	return c
+}
+
+func validateSPIConfig(cfg SPIConfig) error {
+	if cfg.Mode < 0 || cfg.Mode > 3 {
+		return fmt.Errorf("spi: invalid mode %d", cfg.Mode)
+	}
+	if cfg.MaxHz < 0 {
+		return fmt.Errorf("spi: invalid speed %d Hz", cfg.MaxHz)
+	}
+	if cfg.Bits < 0 || cfg.Bits > 32 {
+		return fmt.Errorf("spi: invalid word size %d", cfg.Bits)
+	}
+	return nil
+}
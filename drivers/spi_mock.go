@@ -0,0 +1,157 @@
+package drivers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrSPIScript is returned by a MockSPIPort when a transfer does not
+// match its script.
+var ErrSPIScript = errors.New("spi: transfer does not match script")
+
+// MockSPIFactory is a portable SPIFactory for dev/CI/examples where no
+// hardware exists. Its ports are MockSPIPorts, kept by name so a test
+// can script a port before or after the code under test opens it.
+type MockSPIFactory struct {
+	mu    sync.Mutex
+	ports map[string]*MockSPIPort
+}
+
+// NewMockSPIFactory constructs an empty MockSPIFactory.
+func NewMockSPIFactory() *MockSPIFactory {
+	return &MockSPIFactory{ports: map[string]*MockSPIPort{}}
+}
+
+// OpenSPI returns the port named cfg.Port, reopening it if it was closed.
+func (f *MockSPIFactory) OpenSPI(cfg SPIConfig) (SPIPort, error) {
+	if err := validateSPIConfig(cfg); err != nil {
+		return nil, err
+	}
+	p := f.Port(cfg.Port)
+	p.mu.Lock()
+	p.closed = false
+	p.mu.Unlock()
+	return p, nil
+}
+
+// Port returns the port named name, creating it if needed.
+func (f *MockSPIFactory) Port(name string) *MockSPIPort {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.ports[name]
+	if !ok {
+		p = NewMockSPIPort(name)
+		f.ports[name] = p
+	}
+	return p
+}
+
+// MockSPIPort is a scripted SPIPort. Each Tx consumes the next exchange
+// queued with Expect; once the script runs out, Tx is passed to the
+// handler set with Handle, and without one it fails with ErrSPIScript.
+type MockSPIPort struct {
+	name string
+
+	mu      sync.Mutex
+	script  []spiExchange
+	handler func(w, r []byte) error
+	writes  [][]byte
+	closed  bool
+}
+
+type spiExchange struct {
+	w, r []byte
+	err  error
+}
+
+// NewMockSPIPort returns an open port with an empty script.
+func NewMockSPIPort(name string) *MockSPIPort {
+	return &MockSPIPort{name: name}
+}
+
+// Expect queues an exchange: the next Tx must write w (nil matches
+// anything) and reads back r.
+func (p *MockSPIPort) Expect(w, r []byte) *MockSPIPort {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.script = append(p.script, spiExchange{w: w, r: r})
+	return p
+}
+
+// Fail queues a transfer that returns err.
+func (p *MockSPIPort) Fail(err error) *MockSPIPort {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.script = append(p.script, spiExchange{err: err})
+	return p
+}
+
+// Handle sets the function that answers transfers once the script is
+// used up, typically a model of the chip. It runs with the port locked.
+func (p *MockSPIPort) Handle(fn func(w, r []byte) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handler = fn
+}
+
+// Pending returns the number of scripted exchanges not yet consumed.
+func (p *MockSPIPort) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.script)
+}
+
+// Writes returns a copy of everything written, one slice per Tx.
+func (p *MockSPIPort) Writes() [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([][]byte, len(p.writes))
+	for i, w := range p.writes {
+		out[i] = bytes.Clone(w)
+	}
+	return out
+}
+
+func (p *MockSPIPort) Tx(w, r []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return io.ErrClosedPipe
+	}
+	p.writes = append(p.writes, bytes.Clone(w))
+
+	if len(p.script) == 0 {
+		if p.handler == nil {
+			return fmt.Errorf("%w: unexpected write % x", ErrSPIScript, w)
+		}
+		return p.handler(w, r)
+	}
+	x := p.script[0]
+	p.script = p.script[1:]
+	if x.err != nil {
+		return x.err
+	}
+	if x.w != nil && !bytes.Equal(x.w, w) {
+		return fmt.Errorf("%w: wrote % x, want % x", ErrSPIScript, w, x.w)
+	}
+	if len(x.r) != len(r) {
+		return fmt.Errorf("%w: read %d bytes, script has %d", ErrSPIScript, len(r), len(x.r))
+	}
+	copy(r, x.r)
+	return nil
+}
+
+func (p *MockSPIPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *MockSPIPort) String() string { return "mock:" + p.name }
+
+var _ SPIFactory = (*MockSPIFactory)(nil)
+var _ SPIPort = (*MockSPIPort)(nil)
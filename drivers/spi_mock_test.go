@@ -0,0 +1,81 @@
+package drivers
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockSPIPortScript(t *testing.T) {
+	t.Parallel()
+
+	p := NewMockSPIPort("SPI0.0")
+	boom := errors.New("boom")
+	p.Expect([]byte{1, 2}, []byte{3, 4}).Expect(nil, []byte{5}).Fail(boom)
+	assert.Equal(t, 3, p.Pending())
+
+	r := make([]byte, 2)
+	require.NoError(t, p.Tx([]byte{1, 2}, r))
+	assert.Equal(t, []byte{3, 4}, r)
+
+	r = make([]byte, 1)
+	require.NoError(t, p.Tx([]byte{9}, r), "nil write matches anything")
+	assert.Equal(t, []byte{5}, r)
+
+	assert.ErrorIs(t, p.Tx(nil, nil), boom)
+	assert.ErrorIs(t, p.Tx([]byte{1}, nil), ErrSPIScript, "script used up")
+
+	p.Expect([]byte{1}, nil)
+	assert.ErrorIs(t, p.Tx([]byte{2}, nil), ErrSPIScript, "wrong write")
+	p.Expect(nil, []byte{1, 2})
+	assert.ErrorIs(t, p.Tx(nil, make([]byte, 1)), ErrSPIScript, "wrong read length")
+
+	assert.Equal(t, [][]byte{{1, 2}, {9}, nil, {1}, {2}, nil}, p.Writes())
+	assert.Equal(t, "mock:SPI0.0", p.String())
+}
+
+func TestMockSPIPortHandler(t *testing.T) {
+	t.Parallel()
+
+	p := NewMockSPIPort("SPI0.1")
+	p.Handle(func(w, r []byte) error {
+		for i := range r {
+			r[i] = ^w[i]
+		}
+		return nil
+	})
+	r := make([]byte, 2)
+	require.NoError(t, p.Tx([]byte{0x0f, 0xff}, r))
+	assert.Equal(t, []byte{0xf0, 0x00}, r)
+
+	require.NoError(t, p.Close())
+	assert.ErrorIs(t, p.Tx(nil, nil), io.ErrClosedPipe)
+}
+
+func TestMockSPIFactory(t *testing.T) {
+	t.Parallel()
+
+	f := NewMockSPIFactory()
+	f.Port("SPI0.0").Expect(nil, []byte{7})
+
+	p, err := f.OpenSPI(SPIConfig{Port: "SPI0.0"})
+	require.NoError(t, err)
+	r := make([]byte, 1)
+	require.NoError(t, p.Tx([]byte{0}, r))
+	assert.Equal(t, byte(7), r[0])
+
+	// Reopening after Close gives the same, scriptable port.
+	require.NoError(t, p.Close())
+	p, err = f.OpenSPI(SPIConfig{Port: "SPI0.0"})
+	require.NoError(t, err)
+	assert.Same(t, f.Port("SPI0.0"), p)
+	assert.ErrorIs(t, p.Tx(nil, nil), ErrSPIScript)
+
+	_, err = f.OpenSPI(SPIConfig{Mode: 4})
+	assert.Error(t, err)
+	_, err = f.OpenSPI(SPIConfig{Bits: 33})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package drivers
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+// PeriphSPIFactory opens SPI ports using periph.io. It builds everywhere;
+// off Linux, or without /dev/spidev*, opening fails.
+type PeriphSPIFactory struct{}
+
+func (PeriphSPIFactory) OpenSPI(cfg SPIConfig) (SPIPort, error) {
+	cfg = cfg.withDefaults()
+	if err := validateSPIConfig(cfg); err != nil {
+		return nil, err
+	}
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("spi: host init: %w", err)
+	}
+	p, err := spireg.Open(cfg.Port)
+	if err != nil {
+		return nil, fmt.Errorf("spi: open port %q: %w", cfg.Port, err)
+	}
+	c, err := p.Connect(physic.Frequency(cfg.MaxHz)*physic.Hertz, spi.Mode(cfg.Mode), cfg.Bits)
+	if err != nil {
+		_ = p.Close()
+		return nil, fmt.Errorf("spi: connect %s: %w", p, err)
+	}
+	return &periphSPIPort{port: p, conn: c}, nil
+}
+
+type periphSPIPort struct {
+	port spi.PortCloser
+	conn spi.Conn
+}
+
+func (p *periphSPIPort) Tx(w, r []byte) error { return p.conn.Tx(w, r) }
+
+func (p *periphSPIPort) Close() error { return p.port.Close() }
+
+func (p *periphSPIPort) String() string { return p.port.String() }
+
+var _ SPIFactory = PeriphSPIFactory{}
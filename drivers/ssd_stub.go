@@ -0,0 +1,14 @@
+//go:build !linux || (!arm && !arm64)
+
+package drivers
+
+import "errors"
+
+// PeriphOLEDFactory is a stub on non-Linux ARM builds.
+//
+// This keeps the public API building on developer machines and in CI.
+type PeriphOLEDFactory struct{}
+
+func (PeriphOLEDFactory) OpenSSD1306(bus string, addr uint16, width, height int) (OLED, error) {
+	return nil, errors.New("ssd1306: supported only on linux/arm or linux/arm64")
+}
@@ -21,6 +21,9 @@ func (f *VPIOFactory) key(chip string, offset int) string { return chip + ":" +
 
 // OpenInput returns a virtual input line backed by memory.
 func (f *VPIOFactory) OpenInput(chip string, offset int, edge Edge, bias Bias, debounce time.Duration) (InputLine, error) {
+	if err := checkInput(edge, bias); err != nil {
+		return nil, err
+	}
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -67,10 +70,17 @@ func (f *VPIOFactory) InjectEdge(chip string, offset int, edge Edge, value bool)
 	}
 	l.mu.Lock()
 	l.value = value
+	if !l.wants(edge) {
+		// Like the kernel, number only the edges the line reports.
+		l.mu.Unlock()
+		return
+	}
+	l.seq++
+	seq := l.seq
 	l.mu.Unlock()
 
 	select {
-	case l.evtQ <- LineEvent{Time: time.Now(), Edge: edge, Value: value}:
+	case l.evtQ <- LineEvent{Time: time.Now(), Edge: edge, Value: value, Seq: seq}:
 	default:
 	}
 }
@@ -79,9 +89,21 @@ type vpioLine struct {
 	mu    sync.Mutex
 	edge  Edge
 	value bool
+	seq   uint64
 	evtQ  chan LineEvent
 }
 
+// wants reports whether the line was opened for edge. l.mu is held.
+func (l *vpioLine) wants(edge Edge) bool {
+	switch l.edge {
+	case EdgeBoth, "":
+		return edge == EdgeRising || edge == EdgeFalling
+	case EdgeRising, EdgeFalling:
+		return edge == l.edge
+	}
+	return false
+}
+
 func (l *vpioLine) Read() (bool, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -102,16 +124,7 @@ func (l *vpioLine) Events(ctx context.Context) (<-chan LineEvent, error) {
 		for {
 			select {
 			case ev := <-l.evtQ:
-				// filter by configured edge
-				if l.edge == EdgeRising && ev.Edge != EdgeRising {
-					continue
-				}
-				if l.edge == EdgeFalling && ev.Edge != EdgeFalling {
-					continue
-				}
-				if l.edge == EdgeNone {
-					continue
-				}
+				// InjectEdge queues only the edges the line wants.
 				out <- ev
 			case <-ctx.Done():
 				return
@@ -43,6 +43,7 @@ func TestVPIOFactoryEvents(t *testing.T) {
 	assert.Equal(t, EdgeRising, ev.Edge)
 	assert.True(t, ev.Value)
 	assert.False(t, ev.Time.IsZero())
+	assert.Equal(t, uint64(1), ev.Seq, "filtered falling edge is not numbered")
 
 	cancel()
 	for range events {
@@ -72,3 +73,39 @@ func TestVPIOFactoryEdgeNone(t *testing.T) {
 	for range events {
 	}
 }
+
+func TestVPIOFactoryEmptyEdgeIsBoth(t *testing.T) {
+	t.Parallel()
+
+	f := NewVPIOFactory()
+	in, err := f.OpenInput("chip0", 12, "", BiasDefault, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := in.Events(ctx)
+	require.NoError(t, err)
+
+	f.InjectEdge("chip0", 12, EdgeRising, true)
+	f.InjectEdge("chip0", 12, EdgeFalling, false)
+
+	ev := <-events
+	assert.Equal(t, EdgeRising, ev.Edge)
+	assert.Equal(t, uint64(1), ev.Seq)
+	ev = <-events
+	assert.Equal(t, EdgeFalling, ev.Edge)
+	assert.Equal(t, uint64(2), ev.Seq)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestVPIOFactoryRejectsUnknownEdgeAndBias(t *testing.T) {
+	t.Parallel()
+
+	f := NewVPIOFactory()
+	_, err := f.OpenInput("chip0", 13, Edge("fallng"), BiasDefault, 0)
+	assert.ErrorContains(t, err, `invalid edge "fallng"`)
+	_, err = f.OpenInput("chip0", 13, EdgeBoth, Bias("up"), 0)
+	assert.ErrorContains(t, err, `invalid bias "up"`)
+}
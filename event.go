@@ -1,6 +1,11 @@
 package devices
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
 
 // EventKind describes the type of device event.
 // EventKind is a string to allow custom event types and easy debugging.
@@ -75,3 +80,61 @@ type Event struct {
 	Err  error             // Actual error (use with EventError)
 	Meta map[string]string // Additional context (nil is valid)
 }
+
+// eventJSON is the wire form of Event.
+type eventJSON struct {
+	Device string            `json:"device"`
+	Kind   EventKind         `json:"kind"`
+	Time   string            `json:"time,omitempty"`
+	Msg    string            `json:"msg,omitempty"`
+	Err    string            `json:"error,omitempty"`
+	Meta   map[string]string `json:"meta,omitempty"`
+}
+
+// MarshalJSON encodes the event with Time as RFC3339Nano and Err as its
+// message string.
+func (e Event) MarshalJSON() ([]byte, error) {
+	j := eventJSON{
+		Device: e.Device,
+		Kind:   e.Kind,
+		Msg:    e.Msg,
+		Meta:   e.Meta,
+	}
+	if !e.Time.IsZero() {
+		j.Time = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.Err != nil {
+		j.Err = e.Err.Error()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes an event produced by MarshalJSON.
+// The error is restored as a plain error carrying the original message;
+// its type and wrapped errors are not preserved.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var j eventJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	var t time.Time
+	if j.Time != "" {
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, j.Time); err != nil {
+			return fmt.Errorf("event time: %w", err)
+		}
+	}
+
+	*e = Event{
+		Device: j.Device,
+		Kind:   j.Kind,
+		Time:   t,
+		Msg:    j.Msg,
+		Meta:   j.Meta,
+	}
+	if j.Err != "" {
+		e.Err = errors.New(j.Err)
+	}
+	return nil
+}
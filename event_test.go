@@ -1,10 +1,13 @@
 package devices
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEventKindConstants(t *testing.T) {
@@ -279,3 +282,53 @@ func TestEventKindAsString(t *testing.T) {
 		assert.Equal(t, expected, string(kind))
 	}
 }
+
+func TestEventJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	when := time.Date(2026, 3, 4, 5, 6, 7, 890123456, time.UTC)
+	ev := Event{
+		Device: "relay1",
+		Kind:   EventError,
+		Time:   when,
+		Msg:    "write failed",
+		Err:    errors.New("gpio busy"),
+		Meta:   map[string]string{"value": "true"},
+	}
+
+	data, err := json.Marshal(ev)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"device": "relay1",
+		"kind": "error",
+		"time": "2026-03-04T05:06:07.890123456Z",
+		"msg": "write failed",
+		"error": "gpio busy",
+		"meta": {"value": "true"}
+	}`, string(data))
+
+	var got Event
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, ev.Device, got.Device)
+	assert.Equal(t, ev.Kind, got.Kind)
+	assert.True(t, ev.Time.Equal(got.Time))
+	assert.Equal(t, ev.Msg, got.Msg)
+	require.Error(t, got.Err)
+	assert.Equal(t, "gpio busy", got.Err.Error())
+	assert.Equal(t, ev.Meta, got.Meta)
+}
+
+func TestEventJSONOmitsEmpty(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(Event{Device: "d", Kind: EventOpen})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"device":"d","kind":"open"}`, string(data))
+
+	var got Event
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Nil(t, got.Err)
+	assert.True(t, got.Time.IsZero())
+
+	require.Error(t, json.Unmarshal([]byte(`{"time":"yesterday"}`), &got))
+}
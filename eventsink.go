@@ -0,0 +1,139 @@
+package devices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// EventSink consumes device events, e.g. for logging or storage.
+//
+// Implementations must be safe for concurrent use.
+type EventSink interface {
+	WriteEvent(ev Event) error
+	Close() error
+}
+
+// PipeEvents copies events into every sink until events is closed or ctx
+// is canceled. A failing sink does not stop delivery to the others; the
+// first error of each sink is returned joined.
+//
+// PipeEvents does not close the sinks.
+//
+// Example:
+//
+//	go devices.PipeEvents(ctx, dev.Events(), devices.NewSlogSink(slog.Default()))
+func PipeEvents(ctx context.Context, events <-chan Event, sinks ...EventSink) error {
+	errs := make([]error, len(sinks))
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return errors.Join(errs...)
+			}
+			for i, s := range sinks {
+				if err := s.WriteEvent(ev); err != nil && errs[i] == nil {
+					errs[i] = err
+				}
+			}
+		case <-ctx.Done():
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// JSONLSink writes each event as one JSON object per line.
+type JSONLSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLSink writes JSON lines to w. If w is an io.Closer, Close closes it.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w, enc: json.NewEncoder(w)}
+}
+
+// WriteEvent encodes ev followed by a newline in a single Write.
+func (s *JSONLSink) WriteEvent(ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(ev)
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// SlogSink bridges events into log/slog.
+//
+// The record message is Event.Msg; device, kind, error and meta are added
+// as attributes. EventError is logged at slog.LevelError, everything else
+// at slog.LevelInfo.
+type SlogSink struct {
+	l *slog.Logger
+}
+
+// NewSlogSink logs to l (slog.Default() if nil).
+func NewSlogSink(l *slog.Logger) *SlogSink {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogSink{l: l}
+}
+
+// WriteEvent logs ev as one slog record stamped with ev.Time.
+func (s *SlogSink) WriteEvent(ev Event) error {
+	level := slog.LevelInfo
+	if ev.Kind == EventError {
+		level = slog.LevelError
+	}
+
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.String("device", ev.Device),
+		slog.String("kind", string(ev.Kind)),
+	}
+	if ev.Err != nil {
+		attrs = append(attrs, slog.String("error", ev.Err.Error()))
+	}
+	if len(ev.Meta) > 0 {
+		keys := make([]string, 0, len(ev.Meta))
+		for k := range ev.Meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		meta := make([]any, 0, len(keys))
+		for _, k := range keys {
+			meta = append(meta, slog.String(k, ev.Meta[k]))
+		}
+		attrs = append(attrs, slog.Group("meta", meta...))
+	}
+
+	r := slog.NewRecord(ev.Time, level, ev.Msg, 0)
+	r.AddAttrs(attrs...)
+	return s.l.Handler().Handle(ctx, r)
+}
+
+// Close is a no-op; the logger is owned by the caller.
+func (s *SlogSink) Close() error { return nil }
+
+var (
+	_ EventSink = (*JSONLSink)(nil)
+	_ EventSink = (*SlogSink)(nil)
+)
@@ -0,0 +1,100 @@
+package devices
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{ writes int }
+
+func (s *failingSink) WriteEvent(Event) error {
+	s.writes++
+	return errors.New("disk full")
+}
+func (s *failingSink) Close() error { return nil }
+
+func TestJSONLSinkWritesLines(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	s := NewJSONLSink(&buf)
+
+	require.NoError(t, s.WriteEvent(Event{Device: "a", Kind: EventOpen, Time: time.Unix(1, 0)}))
+	require.NoError(t, s.WriteEvent(Event{Device: "b", Kind: EventError, Err: errors.New("boom")}))
+	require.NoError(t, s.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var ev Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &ev))
+	assert.Equal(t, "b", ev.Device)
+	assert.Equal(t, "boom", ev.Err.Error())
+}
+
+func TestSlogSinkAttributes(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	s := NewSlogSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	when := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.WriteEvent(Event{
+		Device: "soil",
+		Kind:   EventError,
+		Time:   when,
+		Msg:    "read failed",
+		Err:    errors.New("i2c nack"),
+		Meta:   map[string]string{"channel": "0"},
+	}))
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, "ERROR", rec["level"])
+	assert.Equal(t, "read failed", rec["msg"])
+	assert.Equal(t, "soil", rec["device"])
+	assert.Equal(t, "error", rec["kind"])
+	assert.Equal(t, "i2c nack", rec["error"])
+	assert.Equal(t, map[string]any{"channel": "0"}, rec["meta"])
+	assert.Equal(t, "2026-01-01T00:00:00Z", rec["time"])
+}
+
+func TestPipeEventsFansOutAndReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	b := NewBase("dev", 4)
+	b.Emit(EventOpen, "run", nil, nil)
+	b.Emit(EventInfo, "sample", nil, nil)
+	b.Close()
+
+	var buf bytes.Buffer
+	bad := &failingSink{}
+	err := PipeEvents(context.Background(), b.Events(), NewJSONLSink(&buf), bad)
+	require.EqualError(t, err, "disk full")
+	assert.Equal(t, 2, bad.writes)
+
+	n := 0
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		n++
+	}
+	assert.Equal(t, 2, n)
+}
+
+func TestPipeEventsStopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, PipeEvents(ctx, make(chan Event)))
+}
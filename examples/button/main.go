@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/rustyeddy/devices"
 	"github.com/rustyeddy/devices/devices/button"
 	"github.com/rustyeddy/devices/drivers"
 )
@@ -21,8 +23,12 @@ func main() {
 	)
 	defer cancel()
 
-	// Linux GPIO via go-gpiocdev
-	f := drivers.NewGPIOCDevFactory()
+	// GPIO backend: gpiocdev on a Pi, in-memory elsewhere;
+	// override with DEVICES_BACKEND=gpiocdev|vpio|mock.
+	f, err := drivers.GPIOFor("")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	cfg := button.ButtonConfig{
 		Name:     "Button1",
@@ -37,22 +43,7 @@ func main() {
 	b := button.NewButton(cfg)
 
 	// Consume Events() to show lifecycle + edge notifications.
-	go func() {
-		for ev := range b.Events() {
-			// Keep event logging compact, but show meta for edge events.
-			if ev.Err != nil {
-				log.Printf("[event] dev=%s kind=%s msg=%q err=%v meta=%v",
-					ev.Device, ev.Kind, ev.Msg, ev.Err, ev.Meta)
-				continue
-			}
-			if len(ev.Meta) > 0 {
-				log.Printf("[event] dev=%s kind=%s msg=%q meta=%v",
-					ev.Device, ev.Kind, ev.Msg, ev.Meta)
-				continue
-			}
-			log.Printf("[event] dev=%s kind=%s msg=%q", ev.Device, ev.Kind, ev.Msg)
-		}
-	}()
+	go devices.PipeEvents(ctx, b.Events(), devices.NewSlogSink(slog.Default()))
 
 	// Run device
 	go func() {
@@ -30,8 +30,12 @@ func main() {
 		syscall.SIGTERM)
 	defer cancel()
 
-	// Factory (Linux GPIO via go-gpiocdev)
-	f := drivers.NewGPIOCDevFactory()
+	// GPIO backend: gpiocdev on a Pi, in-memory elsewhere;
+	// override with DEVICES_BACKEND=gpiocdev|vpio|mock.
+	f, err := drivers.GPIOFor("")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// LED Config
 	cfg := led.LEDConfig{
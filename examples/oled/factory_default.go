@@ -1,9 +0,0 @@
-//go:build !linux || (!arm && !arm64)
-
-package main
-
-import "github.com/rustyeddy/devices/drivers"
-
-func defaultFactory() drivers.OLEDFactory {
-	return drivers.MockOLEDFactory{}
-}
@@ -2,14 +2,23 @@ package main
 
 import (
 	"context"
+	"log"
 
 	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/drivers"
 )
 
 func main() {
+	// SSD1306 backend: periph on a Pi, a no-op display elsewhere;
+	// override with DEVICES_BACKEND=periph|mock.
+	f, err := drivers.OLEDFor("")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	oled := display.NewOLED(display.OLEDConfig{
 		Name:    "status",
-		Factory: defaultFactory(),
+		Factory: f,
 		Bus:     "1",
 		Addr:    0x3c,
 		Width:   128,
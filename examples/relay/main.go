@@ -20,8 +20,12 @@ func main() {
 	)
 	defer cancel()
 
-	// Factory (Linux GPIO via go-gpiocdev)
-	f := drivers.NewGPIOCDevFactory()
+	// GPIO backend: gpiocdev on a Pi, in-memory elsewhere;
+	// override with DEVICES_BACKEND=gpiocdev|vpio|mock.
+	f, err := drivers.GPIOFor("")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Relay config
 	cfg := relay.RelayConfig{
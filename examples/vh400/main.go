@@ -20,15 +20,27 @@ func main() {
 	)
 	defer cancel()
 
-	// ADS1115 factory (periph.io). Note: implemented for linux+arm/arm64 in this repo.
-	f := drivers.PeriphADCFactory{}
+	// ADS1115 backend: periph on a Pi, a fixed mock reading elsewhere;
+	// override with DEVICES_BACKEND=periph|mock.
+	f, err := drivers.ADCFor("")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Off the Pi, drive the simulated ADC with a slow, noisy sine so the
+	// readings move.
+	if sim, ok := f.(*drivers.SimADCFactory); ok {
+		wave := drivers.SineSource(1.5, 1.0, time.Minute)
+		sim.ADC("1", 0x48).Set(0, drivers.NoiseSource(wave, 0.02, 1))
+	}
 
 	cfg := vh400.VH400Config{
 		Name:        "VH400",
 		Factory:     f,
-		Bus:         "1",  // common on Raspberry Pi; "" may also work depending on periph setup
-		Addr:        0x48, // typical ADS1115 address
-		Channel:     0,    // ADS1115 single-ended A0 (0-3)
+		Chip:        drivers.ChipADS1115, // or drivers.ChipMCP3008 with Bus "SPI0.0"
+		Bus:         "1",                 // common on Raspberry Pi; "" may also work depending on periph setup
+		Addr:        0x48,                // typical ADS1115 address
+		Channel:     0,                   // ADS1115 single-ended A0 (0-3)
 		Interval:    2 * time.Second,
 		EmitInitial: true,
 		Buf:         16,
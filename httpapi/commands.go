@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/display"
+)
+
+// maxBody bounds POST bodies.
+const maxBody = 64 << 10
+
+var (
+	ErrReadOnly     = errors.New("device is read-only")
+	ErrNotWritable  = errors.New("device does not accept commands")
+	ErrValueMissing = errors.New(`body must be {"value": true|false}`)
+	ErrWriteTimeout = errors.New("device did not accept the command in time")
+)
+
+// BoolCommand is the POST body for Sink[bool] devices (relay, LED, switch).
+type BoolCommand struct {
+	Value *bool `json:"value"`
+}
+
+// OLEDCommand is the JSON form of display.OLEDCommand. POST either one
+// command or an array of commands; they are applied in order.
+//
+// Example:
+//
+//	[{"type":"clear"},{"type":"text","x":0,"y":12,"text":"hello"},{"type":"flush"}]
+type OLEDCommand struct {
+	Type  display.OLEDCommandType `json:"type"`
+	X0    int                     `json:"x0,omitempty"`
+	Y0    int                     `json:"y0,omitempty"`
+	X1    int                     `json:"x1,omitempty"`
+	Y1    int                     `json:"y1,omitempty"`
+	X     int                     `json:"x,omitempty"`
+	Y     int                     `json:"y,omitempty"`
+	Len   int                     `json:"len,omitempty"`
+	Width int                     `json:"width,omitempty"`
+	Pixel bool                    `json:"pixel,omitempty"`
+	Text  string                  `json:"text,omitempty"`
+}
+
+func (c OLEDCommand) command() display.OLEDCommand {
+	return display.OLEDCommand{
+		Type: c.Type,
+		X0:   c.X0, Y0: c.Y0, X1: c.X1, Y1: c.Y1,
+		X: c.X, Y: c.Y,
+		Len: c.Len, Width: c.Width,
+		Pixel: display.Pixel(c.Pixel),
+		Text:  c.Text,
+	}
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.device(w, r)
+	if !ok {
+		return
+	}
+	if describe(d).Access == devices.ReadOnly {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, ErrReadOnly)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.WriteTimeout)
+	defer cancel()
+
+	switch sink := d.(type) {
+	case devices.Sink[bool]:
+		var cmd BoolCommand
+		if err := json.Unmarshal(body, &cmd); err != nil || cmd.Value == nil {
+			writeError(w, http.StatusBadRequest, ErrValueMissing)
+			return
+		}
+		select {
+		case sink.In() <- *cmd.Value:
+			writeJSON(w, http.StatusAccepted, cmd)
+		case <-ctx.Done():
+			writeError(w, http.StatusGatewayTimeout, ErrWriteTimeout)
+		}
+
+	case devices.Sink[display.OLEDCommand]:
+		cmds, err := decodeOLED(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		for i, c := range cmds {
+			if err := sendOLED(ctx, sink, c.command()); err != nil {
+				code := http.StatusInternalServerError
+				if errors.Is(err, ErrWriteTimeout) {
+					code = http.StatusGatewayTimeout
+				}
+				writeError(w, code, fmt.Errorf("command %d (%s): %w", i, c.Type, err))
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"applied": len(cmds)})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrNotWritable)
+	}
+}
+
+// decodeOLED accepts a single command object or an array of them.
+func decodeOLED(body []byte) ([]OLEDCommand, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var cmds []OLEDCommand
+		if err := json.Unmarshal(body, &cmds); err != nil {
+			return nil, err
+		}
+		return cmds, nil
+	}
+	var cmd OLEDCommand
+	if err := json.Unmarshal(body, &cmd); err != nil {
+		return nil, err
+	}
+	return []OLEDCommand{cmd}, nil
+}
+
+// sendOLED sends cmd and waits for the display to apply it.
+func sendOLED(ctx context.Context, sink devices.Sink[display.OLEDCommand], cmd display.OLEDCommand) error {
+	cmd.Done = make(chan error, 1)
+	select {
+	case sink.In() <- cmd:
+	case <-ctx.Done():
+		return ErrWriteTimeout
+	}
+	select {
+	case err := <-cmd.Done:
+		return err
+	case <-ctx.Done():
+		return ErrWriteTimeout
+	}
+}
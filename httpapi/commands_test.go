@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/mock"
+)
+
+func TestPostBoolSink(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+
+	rec := do(t, st.srv, "POST", "/devices/pump", `{"value": true}`)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"value": true}`, rec.Body.String())
+
+	// initial state, then the commanded state
+	assert.False(t, <-st.pump.Out())
+	assert.True(t, <-st.pump.Out())
+
+	rec = do(t, st.srv, "POST", "/devices/pump", `{"on": true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPostRejectsReadOnlyAndSources(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+
+	rec := do(t, st.srv, "POST", "/devices/locked", `{"value": true}`)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrReadOnly.Error())
+
+	rec = do(t, st.srv, "POST", "/devices/soil", `{"value": true}`)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrNotWritable.Error())
+
+	rec = do(t, st.srv, "POST", "/devices/nope", `{"value": true}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPostOLEDCommands(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+
+	rec := do(t, st.srv, "POST", "/devices/screen",
+		`[{"type":"clear"},{"type":"set_pixel","x":1,"y":2,"pixel":true},{"type":"flush"}]`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"applied": 3}`, rec.Body.String())
+	assert.Equal(t, display.PixelOn, display.Pixel(st.screen.Background.BitAt(1, 2)))
+
+	rec = do(t, st.srv, "POST", "/devices/screen", `{"type":"bogus"}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown command")
+}
+
+func TestPostTimesOutWhenDeviceIsNotRunning(t *testing.T) {
+	t.Parallel()
+
+	// never run, so its one-slot command buffer fills up
+	sw := mock.NewSwitch(mock.SwitchConfig{Name: "idle", Buf: 1})
+	m := devices.NewManager("m", 0)
+	require.NoError(t, m.Add(sw))
+	srv := NewServer(Config{Manager: m, WriteTimeout: 10 * time.Millisecond})
+
+	rec := do(t, srv, "POST", "/devices/idle", `{"value": true}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = do(t, srv, "POST", "/devices/idle", `{"value": false}`)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+}
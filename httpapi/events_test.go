@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+)
+
+func TestDeviceEventStream(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+	ts := httptest.NewServer(st.srv)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/devices/pump/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// the subscription exists once headers are flushed
+	rec := do(t, st.srv, "POST", "/devices/pump", `{"value": true}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	deadline := time.After(2 * time.Second)
+	var kind string
+	for {
+		select {
+		case line, ok := <-lines:
+			require.True(t, ok, "stream ended early")
+			if k, ok := strings.CutPrefix(line, "event: "); ok {
+				kind = k
+				continue
+			}
+			data, ok := strings.CutPrefix(line, "data: ")
+			if !ok {
+				continue
+			}
+			var ev devices.Event
+			require.NoError(t, json.Unmarshal([]byte(data), &ev))
+			assert.Equal(t, "pump", ev.Device)
+			assert.Equal(t, string(ev.Kind), kind)
+			if ev.Kind == devices.EventInfo && ev.Msg == "set" {
+				assert.Equal(t, "true", ev.Meta["value"])
+				return
+			}
+		case <-deadline:
+			t.Fatal("no set event received")
+		}
+	}
+}
+
+func TestEventStreamEndsOnClose(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+	ts := httptest.NewServer(st.srv)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/devices/soil/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, st.srv.Close())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end")
+	}
+
+	resp2, err := http.Get(ts.URL + "/devices/soil/events")
+	require.NoError(t, err)
+	resp2.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp2.StatusCode)
+
+	resp3, err := http.Get(ts.URL + "/devices/nope/events")
+	require.NoError(t, err)
+	resp3.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp3.StatusCode)
+}
@@ -0,0 +1,309 @@
+// Package httpapi serves a station's devices over HTTP.
+//
+// Routes:
+//
+//	GET  /devices               Descriptor of every device
+//	GET  /devices/{name}        descriptor, latest value and health
+//	POST /devices/{name}        write to a Sink[bool] or send OLED commands
+//	GET  /devices/{name}/events Server-Sent Events stream of device events
+//
+// Example:
+//
+//	srv := httpapi.NewServer(httpapi.Config{Manager: station})
+//	go devices.PipeEvents(ctx, station.Events(), srv)
+//	go httpapi.Watch(ctx, srv, "soil", moistureSub)
+//	http.ListenAndServe(":8080", srv)
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rustyeddy/devices"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Manager is the device registry. Required.
+	Manager *devices.Manager
+
+	// WriteTimeout bounds how long a POST waits for the device to accept
+	// (and, for OLED commands, apply) a command. Default 5s.
+	WriteTimeout time.Duration
+
+	// EventBuf sizes each SSE client's event buffer. Events are dropped
+	// for slow clients. Default 16.
+	EventBuf int
+}
+
+// Health summarizes what a device has reported recently.
+type Health struct {
+	// Status is "unknown" (nothing seen yet), "ok", "error" (the last
+	// error is newer than the last value) or "stopped" (EventClose seen).
+	Status        string         `json:"status"`
+	LastEvent     time.Time      `json:"last_event,omitzero"`
+	LastError     string         `json:"last_error,omitempty"`
+	LastErrorTime time.Time      `json:"last_error_time,omitzero"`
+	Stats         *devices.Stats `json:"stats,omitempty"`
+}
+
+// State is the body of GET /devices/{name}.
+type State struct {
+	Descriptor devices.Descriptor `json:"descriptor"`
+	Value      any                `json:"value,omitempty"`
+	Time       time.Time          `json:"time,omitzero"`
+	Health     Health             `json:"health"`
+}
+
+// deviceState is what the Server has observed for one device.
+type deviceState struct {
+	value     any
+	valueTime time.Time
+
+	lastEvent     time.Time
+	lastError     string
+	lastErrorTime time.Time
+	stopped       bool
+}
+
+// Server is an http.Handler for a Manager's devices.
+//
+// Latest values are recorded with Watch (or SetValue); health and the SSE
+// stream are fed by passing the station's events to the Server, which
+// implements devices.EventSink.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+
+	mu     sync.RWMutex
+	states map[string]*deviceState
+	subs   map[chan devices.Event]string // channel -> device name
+	closed bool
+}
+
+// NewServer constructs a Server and its routes.
+func NewServer(cfg Config) *Server {
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 5 * time.Second
+	}
+	if cfg.EventBuf <= 0 {
+		cfg.EventBuf = 16
+	}
+	s := &Server{
+		cfg:    cfg,
+		mux:    http.NewServeMux(),
+		states: map[string]*deviceState{},
+		subs:   map[chan devices.Event]string{},
+	}
+	s.mux.HandleFunc("GET /devices", s.handleList)
+	s.mux.HandleFunc("GET /devices/{name}", s.handleGet)
+	s.mux.HandleFunc("POST /devices/{name}", s.handlePost)
+	s.mux.HandleFunc("GET /devices/{name}/events", s.handleEvents)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetValue records v as the latest value of device name.
+func (s *Server) SetValue(name string, v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.state(name)
+	st.value = v
+	st.valueTime = time.Now()
+}
+
+// Watch records every value received from in as the latest value of
+// device name, until in is closed or ctx is canceled.
+//
+// Watch consumes in; to serve a device that has other consumers, watch a
+// Hub subscription instead of the device's Out().
+func Watch[T any](ctx context.Context, s *Server, name string, in <-chan T) {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			s.SetValue(name, v)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// WriteEvent updates the device's health and forwards ev to its SSE
+// clients (drop-on-full).
+func (s *Server) WriteEvent(ev devices.Event) error {
+	s.mu.Lock()
+	st := s.state(ev.Device)
+	st.lastEvent = ev.Time
+	switch ev.Kind {
+	case devices.EventError:
+		st.lastError = ev.Msg
+		if ev.Err != nil {
+			st.lastError = ev.Msg + ": " + ev.Err.Error()
+		}
+		st.lastErrorTime = ev.Time
+	case devices.EventOpen:
+		st.stopped = false
+	case devices.EventClose:
+		st.stopped = true
+	}
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	for ch, name := range s.subs {
+		if name != ev.Device {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close ends all SSE streams. The Server keeps serving other routes.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for ch := range s.subs {
+		close(ch)
+	}
+	s.subs = map[chan devices.Event]string{}
+	return nil
+}
+
+// state returns the state for name, creating it. Caller holds s.mu.
+func (s *Server) state(name string) *deviceState {
+	st, ok := s.states[name]
+	if !ok {
+		st = &deviceState{}
+		s.states[name] = st
+	}
+	return st
+}
+
+// subscribe registers an SSE client for device name.
+// ok is false once the Server is closed.
+func (s *Server) subscribe(name string) (ch chan devices.Event, cancel func(), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, false
+	}
+	ch = make(chan devices.Event, s.cfg.EventBuf)
+	s.subs[ch] = name
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}, true
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	list := []devices.Descriptor{}
+	for _, d := range s.cfg.Manager.Devices() {
+		list = append(list, describe(d))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.device(w, r)
+	if !ok {
+		return
+	}
+
+	out := State{Descriptor: describe(d), Health: Health{Status: "unknown"}}
+
+	s.mu.RLock()
+	if st, ok := s.states[d.Name()]; ok {
+		out.Value = st.value
+		out.Time = st.valueTime
+		out.Health = health(st)
+	}
+	s.mu.RUnlock()
+
+	if sd, ok := d.(interface{ Stats() devices.Stats }); ok {
+		stats := sd.Stats()
+		out.Health.Stats = &stats
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func health(st *deviceState) Health {
+	h := Health{
+		Status:        "ok",
+		LastEvent:     st.lastEvent,
+		LastError:     st.lastError,
+		LastErrorTime: st.lastErrorTime,
+	}
+	switch {
+	case st.stopped:
+		h.Status = "stopped"
+	case !st.lastErrorTime.IsZero() && !st.lastErrorTime.Before(st.valueTime):
+		h.Status = "error"
+	case st.lastEvent.IsZero() && st.valueTime.IsZero():
+		h.Status = "unknown"
+	}
+	return h
+}
+
+// device looks up the {name} path value, writing 404 if it is unknown.
+func (s *Server) device(w http.ResponseWriter, r *http.Request) (devices.Device, bool) {
+	name := r.PathValue("name")
+	d, ok := s.cfg.Manager.Get(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("device not found: "+name))
+		return nil, false
+	}
+	return d, true
+}
+
+// describe returns d's Descriptor, or one holding only the name.
+func describe(d devices.Device) devices.Descriptor {
+	if dd, ok := d.(devices.Described); ok {
+		desc := dd.Descriptor()
+		if desc.Name == "" {
+			desc.Name = d.Name()
+		}
+		return desc
+	}
+	return devices.Descriptor{Name: d.Name()}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+var (
+	_ http.Handler      = (*Server)(nil)
+	_ devices.EventSink = (*Server)(nil)
+)
@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/display"
+	"github.com/rustyeddy/devices/drivers"
+	"github.com/rustyeddy/devices/mock"
+)
+
+// readOnlySwitch is a Sink[bool] whose Descriptor says it is read-only.
+type readOnlySwitch struct {
+	*mock.Switch
+}
+
+func (s readOnlySwitch) Descriptor() devices.Descriptor {
+	return devices.Descriptor{Name: s.Name(), Kind: "switch", Access: devices.ReadOnly}
+}
+
+type station struct {
+	srv    *Server
+	pump   *mock.Switch
+	soil   *mock.Sensor[float64]
+	screen *display.OLED
+}
+
+// newStation builds a Server over a pump switch, a soil sensor, a
+// read-only switch and an OLED, and runs the devices until the test ends.
+func newStation(t *testing.T) *station {
+	t.Helper()
+
+	st := &station{
+		pump: mock.NewSwitch(mock.SwitchConfig{Name: "pump"}),
+		soil: mock.NewSensor(mock.SensorConfig[float64]{
+			Name: "soil", Interval: time.Hour, Initial: 42.5, EmitInitial: true,
+		}),
+		screen: display.NewOLED(display.OLEDConfig{
+			Name: "screen", Factory: drivers.MockOLEDFactory{}, Width: 16, Height: 8,
+		}),
+	}
+	m := devices.NewManager("garden", 0)
+	require.NoError(t, m.Add(st.pump))
+	require.NoError(t, m.Add(st.soil))
+	require.NoError(t, m.Add(readOnlySwitch{mock.NewSwitch(mock.SwitchConfig{Name: "locked"})}))
+	require.NoError(t, m.Add(st.screen))
+
+	st.srv = NewServer(Config{Manager: m, WriteTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = m.Run(ctx)
+	}()
+	go func() { _ = devices.PipeEvents(ctx, m.Events(), st.srv) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		_ = st.srv.Close()
+	})
+	return st
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestListDevices(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+	rec := do(t, st.srv, "GET", "/devices", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var list []devices.Descriptor
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 4)
+	assert.Equal(t, "pump", list[0].Name)
+	assert.Equal(t, "soil", list[1].Name)
+	assert.Equal(t, devices.ReadOnly, list[2].Access)
+	assert.Equal(t, "oled", list[3].Kind)
+}
+
+func TestGetDeviceValueAndHealth(t *testing.T) {
+	t.Parallel()
+
+	st := newStation(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Watch(ctx, st.srv, "soil", st.soil.Out())
+
+	var got State
+	require.Eventually(t, func() bool {
+		rec := do(t, st.srv, "GET", "/devices/soil", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		got = State{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return got.Value != nil && got.Health.Status == "ok"
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, "soil", got.Descriptor.Name)
+	assert.Equal(t, 42.5, got.Value)
+	assert.False(t, got.Time.IsZero())
+	require.NotNil(t, got.Health.Stats)
+	assert.NotZero(t, got.Health.Stats.EventsEmitted)
+
+	rec := do(t, st.srv, "GET", "/devices/nope", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHealthStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer(Config{Manager: devices.NewManager("m", 0)})
+	now := time.Now()
+
+	state := func(name string) Health {
+		srv.mu.RLock()
+		defer srv.mu.RUnlock()
+		return health(srv.states[name])
+	}
+
+	_ = srv.WriteEvent(devices.Event{Device: "a", Kind: devices.EventOpen, Time: now})
+	assert.Equal(t, "ok", state("a").Status)
+
+	_ = srv.WriteEvent(devices.Event{Device: "a", Kind: devices.EventError, Msg: "read failed", Err: errors.New("i2c"), Time: time.Now()})
+	h := state("a")
+	assert.Equal(t, "error", h.Status)
+	assert.Equal(t, "read failed: i2c", h.LastError)
+
+	srv.SetValue("a", 1.0)
+	assert.Equal(t, "ok", state("a").Status)
+
+	_ = srv.WriteEvent(devices.Event{Device: "a", Kind: devices.EventClose, Time: time.Now()})
+	assert.Equal(t, "stopped", state("a").Status)
+}
@@ -0,0 +1,217 @@
+package devices
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy controls what a Hub does when a subscriber's buffer is full.
+type DropPolicy string
+
+const (
+	// DropNewest discards the incoming value (default).
+	DropNewest DropPolicy = "drop-newest"
+	// DropOldest discards the oldest buffered value to make room.
+	DropOldest DropPolicy = "drop-oldest"
+	// Block waits until the subscriber has room, stalling every subscriber.
+	// Use only for consumers that must not miss values.
+	Block DropPolicy = "block"
+)
+
+// Hub lets several consumers subscribe to one Source.
+//
+// Every value read from the source's Out() is delivered to all subscribers
+// according to each subscriber's DropPolicy. Subscribers can be added and
+// removed while the Hub runs. All subscriber channels are closed when the
+// source's Out() closes (i.e. when Run returns).
+//
+// Hub is itself a Source wrapping src; Out() behaves as one more
+// DropNewest subscriber, and events from src are passed through.
+type Hub[T any] struct {
+	wrapper[T, T]
+
+	mu     sync.RWMutex
+	subs   map[<-chan T]*subscriber[T]
+	ended  map[<-chan T]*subscriber[T] // canceled or closed, for Dropped
+	closed bool
+}
+
+// NewHub constructs a Hub over src.
+func NewHub[T any](src Source[T]) *Hub[T] {
+	return &Hub[T]{
+		wrapper: newWrapper[T, T](src, 16),
+		subs:    map[<-chan T]*subscriber[T]{},
+		ended:   map[<-chan T]*subscriber[T]{},
+	}
+}
+
+// Subscribe adds a DropNewest subscriber with a buffer of buf values.
+// Call cancel to unsubscribe; it closes the channel and is safe to call
+// more than once.
+func (h *Hub[T]) Subscribe(buf int) (<-chan T, func()) {
+	return h.SubscribeWith(buf, DropNewest)
+}
+
+// SubscribeWith adds a subscriber with the given drop policy.
+// After Run has returned it returns a closed channel.
+func (h *Hub[T]) SubscribeWith(buf int, policy DropPolicy) (<-chan T, func()) {
+	if buf < 0 {
+		buf = 0
+	}
+	if policy == "" {
+		policy = DropNewest
+	}
+	s := &subscriber[T]{
+		ch:     make(chan T, buf),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		s.close()
+		return s.ch, func() {}
+	}
+	h.subs[s.ch] = s
+
+	cancel := func() {
+		h.mu.Lock()
+		if _, ok := h.subs[s.ch]; ok {
+			delete(h.subs, s.ch)
+			h.ended[s.ch] = s
+		}
+		h.mu.Unlock()
+		s.close()
+	}
+	return s.ch, cancel
+}
+
+// Subscribers returns the number of active subscribers.
+func (h *Hub[T]) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Dropped returns the number of values dropped for the subscriber that
+// owns ch. The count stays readable after the subscriber is canceled or
+// the Hub stops; it is 0 for unknown channels.
+func (h *Hub[T]) Dropped(ch <-chan T) uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if s, ok := h.subs[ch]; ok {
+		return s.dropped.Load()
+	}
+	if s, ok := h.ended[ch]; ok {
+		return s.dropped.Load()
+	}
+	return 0
+}
+
+// Run runs the source and broadcasts its values until it stops.
+func (h *Hub[T]) Run(ctx context.Context) error {
+	return h.run(ctx, func(in <-chan T) {
+		defer h.closeAll()
+
+		for v := range in {
+			h.send(v)
+
+			// Snapshot so Block subscribers never wait while holding the lock.
+			h.mu.RLock()
+			subs := make([]*subscriber[T], 0, len(h.subs))
+			for _, s := range h.subs {
+				subs = append(subs, s)
+			}
+			h.mu.RUnlock()
+
+			for _, s := range subs {
+				s.deliver(ctx, v)
+			}
+		}
+	})
+}
+
+func (h *Hub[T]) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch, s := range h.subs {
+		s.close()
+		h.ended[ch] = s
+	}
+	h.subs = map[<-chan T]*subscriber[T]{}
+	h.closed = true
+}
+
+// subscriber is a single Hub consumer.
+//
+// mu serializes delivery and close so the channel is never written
+// after it has been closed.
+type subscriber[T any] struct {
+	ch      chan T
+	policy  DropPolicy
+	dropped atomic.Uint64
+
+	done chan struct{}
+	once sync.Once
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *subscriber[T]) deliver(ctx context.Context, v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	policy := s.policy
+	if policy == DropOldest && cap(s.ch) == 0 {
+		// nothing buffered to discard
+		policy = DropNewest
+	}
+
+	switch policy {
+	case Block:
+		select {
+		case s.ch <- v:
+		case <-s.done:
+		case <-ctx.Done():
+			s.dropped.Add(1)
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- v:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case s.ch <- v:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (s *subscriber[T]) close() {
+	s.once.Do(func() {
+		close(s.done) // unblock a pending Block delivery
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+	})
+}
@@ -0,0 +1,175 @@
+package devices
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHub runs a hub over a feed source and returns the feed and a stop func.
+func startHub(t *testing.T) (*Hub[int], chan<- int, func()) {
+	t.Helper()
+
+	feed := make(chan int)
+	h := NewHub[int](feedSource("src", feed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.Run(ctx) }()
+
+	return h, feed, func() {
+		cancel()
+		require.NoError(t, <-errCh)
+	}
+}
+
+// flush sends a marker through the hub to a dedicated blocking subscriber,
+// guaranteeing every earlier value has been delivered to all subscribers.
+// The marker itself may still be in flight to other subscribers.
+func flush(t *testing.T, h *Hub[int], feed chan<- int) {
+	t.Helper()
+	ch, cancel := h.SubscribeWith(0, Block)
+	defer cancel()
+	feed <- -1
+	for recv(t, ch) != -1 {
+		// earlier values still in flight
+	}
+}
+
+func TestHubSubscribeAndCancel(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+
+	a, cancelA := h.Subscribe(4)
+	b, cancelB := h.Subscribe(4)
+	assert.Equal(t, 2, h.Subscribers())
+
+	feed <- 1
+	assert.Equal(t, 1, recv(t, a))
+	assert.Equal(t, 1, recv(t, b))
+
+	cancelA()
+	cancelA() // idempotent
+	assert.Equal(t, 1, h.Subscribers())
+	_, ok := <-a
+	assert.False(t, ok)
+
+	feed <- 2
+	assert.Equal(t, 2, recv(t, b))
+
+	stop()
+	cancelB() // after close is harmless
+
+	_, ok = <-b
+	assert.False(t, ok, "subscriber channels close when the source stops")
+
+	late, _ := h.Subscribe(1)
+	_, ok = <-late
+	assert.False(t, ok)
+}
+
+func TestHubDropNewestCountsDrops(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+	defer stop()
+
+	ch, cancel := h.Subscribe(1)
+	defer cancel()
+
+	feed <- 1
+	feed <- 2
+	feed <- 3
+	flush(t, h, feed)
+
+	// 2, 3 and the flush marker are dropped
+	require.Eventually(t, func() bool { return h.Dropped(ch) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, recv(t, ch))
+}
+
+func TestHubDroppedAfterCancelAndStop(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+
+	a, cancelA := h.Subscribe(1)
+	b, _ := h.Subscribe(1)
+	feed <- 1
+	feed <- 2
+	flush(t, h, feed)
+	require.Eventually(t, func() bool { return h.Dropped(a) == 2 && h.Dropped(b) == 2 }, time.Second, time.Millisecond)
+
+	cancelA()
+	assert.Equal(t, uint64(2), h.Dropped(a), "count outlives cancel")
+
+	stop()
+	assert.Equal(t, uint64(2), h.Dropped(b), "count outlives the hub")
+	assert.Equal(t, uint64(0), h.Dropped(make(chan int)))
+}
+
+func TestHubDropOldestKeepsLatest(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+	defer stop()
+
+	ch, cancel := h.SubscribeWith(2, DropOldest)
+	defer cancel()
+
+	for v := 1; v <= 4; v++ {
+		feed <- v
+	}
+	flush(t, h, feed)
+
+	require.Eventually(t, func() bool { return h.Dropped(ch) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, 4, recv(t, ch))
+	assert.Equal(t, -1, recv(t, ch))
+}
+
+func TestHubBlockDeliversAll(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+	defer stop()
+
+	ch, cancel := h.SubscribeWith(0, Block)
+	defer cancel()
+
+	go func() {
+		for v := 1; v <= 5; v++ {
+			feed <- v
+		}
+	}()
+
+	for v := 1; v <= 5; v++ {
+		assert.Equal(t, v, recv(t, ch))
+	}
+	assert.Equal(t, uint64(0), h.Dropped(ch))
+}
+
+func TestHubCancelUnblocksBlockingSubscriber(t *testing.T) {
+	t.Parallel()
+
+	h, feed, stop := startHub(t)
+	defer stop()
+
+	_, cancelSlow := h.SubscribeWith(0, Block)
+	fast, cancelFast := h.Subscribe(4)
+	defer cancelFast()
+
+	sent := make(chan struct{})
+	go func() {
+		feed <- 1
+		close(sent)
+	}()
+	<-sent
+
+	time.Sleep(10 * time.Millisecond)
+	cancelSlow()
+
+	assert.Equal(t, 1, recv(t, fast))
+}
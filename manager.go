@@ -0,0 +1,230 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrManagerRunning  = errors.New("manager is already running")
+	ErrDeviceNil       = errors.New("device is nil")
+	ErrDeviceNameEmpty = errors.New("device name is empty")
+	ErrDeviceExists    = errors.New("device already registered")
+)
+
+// Manager runs a set of devices together as one station.
+//
+// Devices are registered by name with Add, started together by Run,
+// and their event streams are merged into the Manager's Events channel.
+//
+// Lifecycle:
+//   - Create with NewManager and register devices with Add
+//   - Call Run(ctx) once; it blocks until every device's Run has returned
+//   - Run returns the errors of all devices joined (errors.Join)
+//   - Events() is closed when Run returns
+//
+// The Manager implements Device, so a station can itself be nested or
+// supervised like any other device.
+type Manager struct {
+	name string
+
+	mu      sync.RWMutex
+	devices map[string]Device
+	order   []string
+	running bool
+
+	events    chan Event
+	closeOnce sync.Once
+}
+
+// NewManager constructs an empty Manager with a buffered merged event channel.
+func NewManager(name string, eventBuf int) *Manager {
+	if eventBuf <= 0 {
+		eventBuf = 64
+	}
+	return &Manager{
+		name:    name,
+		devices: map[string]Device{},
+		events:  make(chan Event, eventBuf),
+	}
+}
+
+// Name returns the manager name.
+func (m *Manager) Name() string { return m.name }
+
+// Events returns the merged event stream of all registered devices.
+// Events are forwarded unchanged, so Event.Device identifies the source.
+// Like Base.Emit, events are dropped if the channel buffer is full.
+func (m *Manager) Events() <-chan Event { return m.events }
+
+// Add registers a device under its Name().
+// Devices must be added before Run is called.
+func (m *Manager) Add(d Device) error {
+	if d == nil {
+		return ErrDeviceNil
+	}
+	name := d.Name()
+	if name == "" {
+		return ErrDeviceNameEmpty
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return ErrManagerRunning
+	}
+	if _, ok := m.devices[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDeviceExists, name)
+	}
+	m.devices[name] = d
+	m.order = append(m.order, name)
+	return nil
+}
+
+// Get returns the device registered under name.
+func (m *Manager) Get(name string) (Device, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.devices[name]
+	return d, ok
+}
+
+// Devices returns all registered devices in registration order.
+func (m *Manager) Devices() []Device {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Device, 0, len(m.order))
+	for _, name := range m.order {
+		out = append(out, m.devices[name])
+	}
+	return out
+}
+
+// ByKind returns the devices whose Descriptor().Kind equals kind,
+// in registration order. Devices that are not Described are skipped.
+func (m *Manager) ByKind(kind string) []Device {
+	var out []Device
+	for _, d := range m.Devices() {
+		if desc, ok := d.(Described); ok && desc.Descriptor().Kind == kind {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Descriptors returns the Descriptor of every Described device,
+// in registration order.
+func (m *Manager) Descriptors() []Descriptor {
+	var out []Descriptor
+	for _, d := range m.Devices() {
+		if desc, ok := d.(Described); ok {
+			out = append(out, desc.Descriptor())
+		}
+	}
+	return out
+}
+
+// Run starts every registered device and blocks until all of them return.
+//
+// Cancel ctx to stop the station. Errors returned by devices are wrapped
+// with the device name and joined; context.Canceled returned after ctx
+// is canceled is not treated as an error.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrManagerRunning
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	devs := m.Devices()
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		errs  []error
+	)
+
+	for _, d := range devs {
+		d := d
+		done := make(chan struct{})
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			forward(d.Events(), done, m.send)
+		}()
+		go func() {
+			defer wg.Done()
+			defer close(done)
+			err := d.Run(ctx)
+			if err == nil || (ctx.Err() != nil && errors.Is(err, context.Canceled)) {
+				return
+			}
+			errMu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", d.Name(), err))
+			errMu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	m.closeOnce.Do(func() { close(m.events) })
+
+	return errors.Join(errs...)
+}
+
+// send forwards an event into the merged stream without blocking.
+func (m *Manager) send(ev Event) {
+	select {
+	case m.events <- ev:
+	default:
+		// drop if slow consumer, same policy as Base.Emit
+	}
+}
+
+// Close closes every registered device and returns their errors joined.
+func (m *Manager) Close() error {
+	var errs []error
+	for _, d := range m.Devices() {
+		if err := d.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var _ Device = (*Manager)(nil)
+
+// forward copies values to send until in is closed, or done is closed
+// and the remaining buffered values have been drained.
+//
+// Some devices return from Run without closing their channels (e.g. on
+// early configuration errors), so done must be closed once Run has returned.
+func forward[T any](in <-chan T, done <-chan struct{}, send func(T)) {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			send(v)
+
+		case <-done:
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					send(v)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type managedDevice struct {
+	Base
+	kind string
+	run  func(ctx context.Context, b *Base) error
+}
+
+func newManagedDevice(name, kind string, run func(ctx context.Context, b *Base) error) *managedDevice {
+	return &managedDevice{Base: NewBase(name, 16), kind: kind, run: run}
+}
+
+func (d *managedDevice) Run(ctx context.Context) error { return d.run(ctx, &d.Base) }
+
+func (d *managedDevice) Descriptor() Descriptor {
+	return Descriptor{Name: d.Name(), Kind: d.kind}
+}
+
+func runUntilCanceled(ctx context.Context, b *Base) error {
+	b.Emit(EventOpen, "run", nil, nil)
+	<-ctx.Done()
+	b.Emit(EventClose, "stop", nil, nil)
+	b.Close()
+	return nil
+}
+
+func TestManagerAddValidation(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager("station", 0)
+	assert.Equal(t, "station", m.Name())
+
+	require.ErrorIs(t, m.Add(nil), ErrDeviceNil)
+	require.ErrorIs(t, m.Add(newManagedDevice("", "x", runUntilCanceled)), ErrDeviceNameEmpty)
+
+	require.NoError(t, m.Add(newManagedDevice("a", "relay", runUntilCanceled)))
+	require.ErrorIs(t, m.Add(newManagedDevice("a", "relay", runUntilCanceled)), ErrDeviceExists)
+}
+
+func TestManagerLookup(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager("station", 0)
+	require.NoError(t, m.Add(newManagedDevice("r1", "relay", runUntilCanceled)))
+	require.NoError(t, m.Add(newManagedDevice("b1", "button", runUntilCanceled)))
+	require.NoError(t, m.Add(newManagedDevice("r2", "relay", runUntilCanceled)))
+
+	d, ok := m.Get("b1")
+	require.True(t, ok)
+	assert.Equal(t, "b1", d.Name())
+
+	_, ok = m.Get("missing")
+	assert.False(t, ok)
+
+	relays := m.ByKind("relay")
+	require.Len(t, relays, 2)
+	assert.Equal(t, "r1", relays[0].Name())
+	assert.Equal(t, "r2", relays[1].Name())
+
+	descs := m.Descriptors()
+	require.Len(t, descs, 3)
+	assert.Equal(t, "button", descs[1].Kind)
+}
+
+func TestManagerRunMergesEvents(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager("station", 0)
+	require.NoError(t, m.Add(newManagedDevice("a", "x", runUntilCanceled)))
+	require.NoError(t, m.Add(newManagedDevice("b", "x", runUntilCanceled)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- m.Run(ctx) }()
+
+	opened := map[string]bool{}
+	for len(opened) < 2 {
+		select {
+		case ev := <-m.Events():
+			if ev.Kind == EventOpen {
+				opened[ev.Device] = true
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for open events")
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	// merged stream closes after Run returns
+	for range m.Events() {
+	}
+
+	require.ErrorIs(t, m.Run(context.Background()), ErrManagerRunning)
+	require.ErrorIs(t, m.Add(newManagedDevice("c", "x", runUntilCanceled)), ErrManagerRunning)
+}
+
+func TestManagerRunAggregatesErrors(t *testing.T) {
+	t.Parallel()
+
+	errA := errors.New("open failed")
+
+	m := NewManager("station", 0)
+	require.NoError(t, m.Add(newManagedDevice("a", "x", func(ctx context.Context, b *Base) error {
+		// fails without closing its events, like devices do on early errors
+		b.Emit(EventError, "open failed", errA, nil)
+		return errA
+	})))
+	require.NoError(t, m.Add(newManagedDevice("b", "x", func(ctx context.Context, b *Base) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- m.Run(ctx) }()
+
+	select {
+	case ev := <-m.Events():
+		assert.Equal(t, "a", ev.Device)
+		assert.Equal(t, EventError, ev.Kind)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for error event")
+	}
+
+	cancel()
+	err := <-errCh
+	require.ErrorIs(t, err, errA)
+	assert.Contains(t, err.Error(), "a: open failed")
+	assert.NotErrorIs(t, err, context.Canceled)
+}
+
+func TestManagerClose(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager("station", 0)
+	a := newManagedDevice("a", "x", runUntilCanceled)
+	require.NoError(t, m.Add(a))
+	require.NoError(t, m.Close())
+
+	_, ok := <-a.Events()
+	assert.False(t, ok)
+}
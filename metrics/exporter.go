@@ -0,0 +1,319 @@
+// Package metrics exposes device readings and health in the Prometheus
+// text exposition format, using only the standard library.
+//
+// An Exporter collects:
+//   - the latest value of every watched device (devices_value gauge)
+//   - reads, errors and restarts per device (counters)
+//   - dropped events/samples for devices that report devices.Stats
+//
+// Example garden station:
+//
+//	exp := metrics.NewExporter()
+//	exp.Register(vh)     // *vh400.VH400
+//	exp.Register(env)    // *bme280.BME280
+//
+//	hub := devices.NewHub[float64](vh)
+//	moisture, _ := hub.Subscribe(4)
+//	go metrics.Watch(ctx, exp, vh.Name(), moisture, metrics.Float)
+//	go metrics.Watch(ctx, exp, env.Name(), env.Out(), metrics.Env)
+//	go devices.PipeEvents(ctx, station.Events(), exp)
+//
+//	http.Handle("/metrics", exp)
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rustyeddy/devices"
+)
+
+// ContentType is the Prometheus text format served by Exporter.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Reading is one field of a device value.
+//
+// Field distinguishes the series of multi-value devices (e.g. "temperature"
+// for a BME280) and is empty for single-value devices. Unit overrides the
+// Descriptor unit when set.
+type Reading struct {
+	Field string
+	Unit  string
+	Value float64
+}
+
+type statser interface {
+	Stats() devices.Stats
+}
+
+// series holds everything known about one device.
+type series struct {
+	desc   devices.Descriptor
+	stats  statser
+	values map[string]Reading // by field
+
+	reads    uint64
+	errors   uint64
+	restarts uint64
+}
+
+// Exporter collects device metrics and serves them over HTTP.
+//
+// Exporter implements http.Handler and devices.EventSink; feed it a
+// (merged) event stream with devices.PipeEvents to count errors and
+// restarts. It is safe for concurrent use.
+type Exporter struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// NewExporter constructs an empty Exporter.
+func NewExporter() *Exporter {
+	return &Exporter{series: map[string]*series{}}
+}
+
+// Register records d's Descriptor (used for labels) and, if d reports
+// devices.Stats, exports its drop counters. Registering is optional:
+// values and events for unknown devices are exported with the name label
+// only.
+func (e *Exporter) Register(d devices.Device) {
+	if d == nil {
+		return
+	}
+	desc := devices.Descriptor{Name: d.Name()}
+	if dd, ok := d.(devices.Described); ok {
+		desc = dd.Descriptor()
+		if desc.Name == "" {
+			desc.Name = d.Name()
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.get(desc.Name)
+	s.desc = desc
+	if st, ok := d.(statser); ok {
+		s.stats = st
+	}
+}
+
+// RegisterManager registers every device of m.
+func (e *Exporter) RegisterManager(m *devices.Manager) {
+	for _, d := range m.Devices() {
+		e.Register(d)
+	}
+}
+
+// Set records the latest readings of device name and counts one read.
+func (e *Exporter) Set(name string, readings ...Reading) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.get(name)
+	s.reads++
+	for _, r := range readings {
+		s.values[r.Field] = r
+	}
+}
+
+// WriteEvent counts EventError as an error and the Supervisor's
+// EventInfo "restart" as a restart of ev.Device.
+func (e *Exporter) WriteEvent(ev devices.Event) error {
+	switch {
+	case ev.Kind == devices.EventError:
+	case ev.Kind == devices.EventInfo && ev.Msg == "restart":
+	default:
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.get(ev.Device)
+	if ev.Kind == devices.EventError {
+		s.errors++
+	} else {
+		s.restarts++
+	}
+	return nil
+}
+
+// Close is a no-op; it lets Exporter be used as a devices.EventSink.
+func (e *Exporter) Close() error { return nil }
+
+// get returns the series for name, creating it. Caller holds e.mu.
+func (e *Exporter) get(name string) *series {
+	s, ok := e.series[name]
+	if !ok {
+		s = &series{
+			desc:   devices.Descriptor{Name: name},
+			values: map[string]Reading{},
+		}
+		e.series[name] = s
+	}
+	return s
+}
+
+// Watch records every value received from in until in is closed or ctx
+// is canceled. conv turns a value into readings (see Float, Bool, Env).
+//
+// Watch consumes in; to export a device that has other consumers, watch a
+// Hub subscription instead of the device's Out().
+func Watch[T any](ctx context.Context, e *Exporter, name string, in <-chan T, conv func(T) []Reading) {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			e.Set(name, conv(v)...)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = e.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text format to w.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	e.mu.Lock()
+	names := make([]string, 0, len(e.series))
+	for name := range e.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	header(&b, "devices_value", "gauge", "Latest value reported by a device.")
+	for _, name := range names {
+		s := e.series[name]
+		fields := make([]string, 0, len(s.values))
+		for f := range s.values {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		for _, f := range fields {
+			r := s.values[f]
+			unit := r.Unit
+			if unit == "" {
+				unit = s.desc.Unit
+			}
+			labels := deviceLabels(s.desc)
+			labels = append(labels, label{"unit", unit}, label{"field", r.Field})
+			line(&b, "devices_value", labels, formatFloat(r.Value))
+		}
+	}
+
+	counters := []struct {
+		name, help string
+		value      func(*series) uint64
+	}{
+		{"devices_reads_total", "Values read from a device.", func(s *series) uint64 { return s.reads }},
+		{"devices_errors_total", "Error events emitted by a device.", func(s *series) uint64 { return s.errors }},
+		{"devices_restarts_total", "Restarts of a supervised device.", func(s *series) uint64 { return s.restarts }},
+	}
+	for _, c := range counters {
+		header(&b, c.name, "counter", c.help)
+		for _, name := range names {
+			s := e.series[name]
+			line(&b, c.name, deviceLabels(s.desc), strconv.FormatUint(c.value(s), 10))
+		}
+	}
+
+	stats := map[string]devices.Stats{}
+	for _, name := range names {
+		if st := e.series[name].stats; st != nil {
+			stats[name] = st.Stats()
+		}
+	}
+	if len(stats) > 0 {
+		drops := []struct {
+			name, help string
+			value      func(devices.Stats) uint64
+		}{
+			{"devices_events_dropped_total", "Events dropped because the event buffer was full.", func(s devices.Stats) uint64 { return s.EventsDropped }},
+			{"devices_samples_dropped_total", "Values dropped because the output buffer was full.", func(s devices.Stats) uint64 { return s.SamplesDropped }},
+		}
+		for _, d := range drops {
+			header(&b, d.name, "counter", d.help)
+			for _, name := range names {
+				st, ok := stats[name]
+				if !ok {
+					continue
+				}
+				line(&b, d.name, deviceLabels(e.series[name].desc), strconv.FormatUint(d.value(st), 10))
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+type label struct{ name, value string }
+
+// deviceLabels returns the name, kind and tags labels of desc.
+func deviceLabels(desc devices.Descriptor) []label {
+	return []label{
+		{"name", desc.Name},
+		{"kind", desc.Kind},
+		{"tags", strings.Join(desc.Tags, ",")},
+	}
+}
+
+func header(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// line writes one sample; empty labels are omitted as Prometheus treats
+// them as absent anyway.
+func line(b *strings.Builder, name string, labels []label, value string) {
+	b.WriteString(name)
+	first := true
+	for _, l := range labels {
+		if l.value == "" {
+			continue
+		}
+		if first {
+			b.WriteByte('{')
+			first = false
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(l.value))
+		b.WriteByte('"')
+	}
+	if !first {
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// formatFloat renders v as Prometheus expects, including +Inf, -Inf, NaN.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	_ http.Handler      = (*Exporter)(nil)
+	_ devices.EventSink = (*Exporter)(nil)
+)
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/mock"
+)
+
+// describedDevice is a minimal device with a Descriptor and Stats.
+type describedDevice struct {
+	devices.Base
+	desc devices.Descriptor
+}
+
+func newDescribed(desc devices.Descriptor) *describedDevice {
+	return &describedDevice{Base: devices.NewBase(desc.Name, 1), desc: desc}
+}
+
+func (d *describedDevice) Descriptor() devices.Descriptor { return d.desc }
+func (d *describedDevice) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestExporterValuesAndLabels(t *testing.T) {
+	t.Parallel()
+
+	e := NewExporter()
+	e.Register(newDescribed(devices.Descriptor{Name: "VH400", Kind: "vh400", Unit: "%", Tags: []string{"adc", "sensor"}}))
+	e.Register(newDescribed(devices.Descriptor{Name: "env", Kind: "bme280"}))
+	e.Register(newDescribed(devices.Descriptor{Name: "pump", Kind: "relay"}))
+
+	e.Set("VH400", Float(42.5)...)
+	e.Set("env", Env(envSample())...)
+	e.Set("pump", Bool(true)...)
+	e.Set("pump", Bool(false)...)
+
+	body := scrape(t, e)
+
+	assert.Contains(t, body, "# TYPE devices_value gauge\n")
+	assert.Contains(t, body, `devices_value{name="VH400",kind="vh400",tags="adc,sensor",unit="%"} 42.5`+"\n")
+	assert.Contains(t, body, `devices_value{name="env",kind="bme280",unit="C",field="temperature"} 21.5`+"\n")
+	assert.Contains(t, body, `devices_value{name="env",kind="bme280",unit="Pa",field="pressure"} 101325`+"\n")
+	assert.Contains(t, body, `devices_value{name="env",kind="bme280",unit="%RH",field="humidity"} 40`+"\n")
+	assert.Contains(t, body, `devices_value{name="pump",kind="relay"} 0`+"\n")
+
+	assert.Contains(t, body, "# TYPE devices_reads_total counter\n")
+	assert.Contains(t, body, `devices_reads_total{name="pump",kind="relay"} 2`+"\n")
+	assert.Contains(t, body, `devices_events_dropped_total{name="VH400",kind="vh400",tags="adc,sensor"} 0`+"\n")
+}
+
+func TestExporterCountsErrorsAndRestarts(t *testing.T) {
+	t.Parallel()
+
+	e := NewExporter()
+	events := make(chan devices.Event, 4)
+	events <- devices.Event{Device: "pump", Kind: devices.EventError, Err: errors.New("boom")}
+	events <- devices.Event{Device: "pump", Kind: devices.EventInfo, Msg: "restart"}
+	events <- devices.Event{Device: "pump", Kind: devices.EventInfo, Msg: "set"}
+	events <- devices.Event{Device: "pump", Kind: devices.EventError}
+	close(events)
+
+	require.NoError(t, devices.PipeEvents(context.Background(), events, e))
+
+	body := scrape(t, e)
+	assert.Contains(t, body, `devices_errors_total{name="pump"} 2`+"\n")
+	assert.Contains(t, body, `devices_restarts_total{name="pump"} 1`+"\n")
+	assert.Contains(t, body, `devices_reads_total{name="pump"} 0`+"\n")
+	assert.NotContains(t, body, "devices_events_dropped_total", "no Stats without Register")
+}
+
+func TestWatchRecordsValues(t *testing.T) {
+	t.Parallel()
+
+	e := NewExporter()
+	sw := mock.NewSwitch(mock.SwitchConfig{Name: "sw"})
+	e.Register(sw)
+
+	in := make(chan bool, 2)
+	in <- true
+	in <- true
+	close(in)
+	Watch(context.Background(), e, "sw", in, Bool)
+
+	body := scrape(t, e)
+	assert.Contains(t, body, `devices_value{name="sw"} 1`+"\n")
+	assert.Contains(t, body, `devices_reads_total{name="sw"} 2`+"\n")
+}
+
+func TestLabelEscapingAndSpecialValues(t *testing.T) {
+	t.Parallel()
+
+	e := NewExporter()
+	e.Set("a\"b\\c\nd", Float(math.Inf(1))...)
+	e.Set("nan", Float(math.NaN())...)
+
+	var b strings.Builder
+	_, err := e.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), `devices_value{name="a\"b\\c\nd"} +Inf`+"\n")
+	assert.Contains(t, b.String(), `devices_value{name="nan"} NaN`+"\n")
+}
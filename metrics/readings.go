@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"github.com/rustyeddy/devices/devices/bme280"
+)
+
+// Float converts a single float64 value (e.g. VH400 moisture).
+func Float(v float64) []Reading {
+	return []Reading{{Value: v}}
+}
+
+// Bool converts on/off state (relay, LED, button) to 1/0.
+func Bool(v bool) []Reading {
+	if v {
+		return []Reading{{Value: 1}}
+	}
+	return []Reading{{Value: 0}}
+}
+
+// Env converts a BME280 sample into separate temperature, pressure and
+// humidity series.
+func Env(e bme280.Env) []Reading {
+	return []Reading{
+		{Field: "temperature", Unit: "C", Value: e.Temperature},
+		{Field: "pressure", Unit: "Pa", Value: e.Pressure},
+		{Field: "humidity", Unit: "%RH", Value: e.Humidity},
+	}
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustyeddy/devices/devices/bme280"
+)
+
+func TestReadingConverters(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []Reading{{Value: 42.5}}, Float(42.5))
+	assert.Equal(t, []Reading{{Value: 1}}, Bool(true))
+	assert.Equal(t, []Reading{{Value: 0}}, Bool(false))
+
+	got := Env(envSample())
+	assert.Equal(t, []Reading{
+		{Field: "temperature", Unit: "C", Value: 21.5},
+		{Field: "pressure", Unit: "Pa", Value: 101325},
+		{Field: "humidity", Unit: "%RH", Value: 40},
+	}, got)
+}
+
+func envSample() bme280.Env {
+	return bme280.Env{Temperature: 21.5, Pressure: 101325, Humidity: 40}
+}
@@ -0,0 +1,322 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rustyeddy/devices"
+)
+
+const (
+	// Online and Offline are the availability payloads (the Home Assistant
+	// defaults).
+	Online  = "online"
+	Offline = "offline"
+)
+
+var (
+	ErrClientNil  = errors.New("mqtt: client is nil")
+	ErrManagerNil = errors.New("mqtt: manager is nil")
+	ErrNotRunning = errors.New("mqtt: bridge is not running")
+)
+
+// BridgeConfig configures a Bridge.
+type BridgeConfig struct {
+	Name string
+
+	// Client connects to the broker. Required.
+	Client Client
+
+	// Manager holds the devices to bridge. Required.
+	Manager *devices.Manager
+
+	// Prefix is the first topic level. Default "devices".
+	Prefix string
+
+	// QoS is used for all publications and subscriptions.
+	QoS byte
+
+	// Discovery publishes Home Assistant discovery configs (retained) for
+	// every device with a mapping when Run connects. See Discover.
+	Discovery bool
+
+	// DiscoveryPrefix overrides DefaultDiscoveryPrefix.
+	DiscoveryPrefix string
+
+	// Buf sizes the event channel. Default 16.
+	Buf int
+}
+
+// Bridge connects a station's devices to MQTT:
+//
+//	<prefix>/availability     "online"/"offline" (retained, last will)
+//	<prefix>/<device>/state   latest value as JSON (retained), see Watch
+//	<prefix>/<device>/events  device events as JSON, see WriteEvent
+//	<prefix>/<device>/set     commands for Sink[bool] devices
+//
+// With Discovery set, Home Assistant discovery configs are published too.
+//
+// Commands accept true/false, on/off, 1/0 (any case). Devices whose
+// Descriptor is ReadOnly are not subscribed.
+//
+// Bridge is a Device: Run connects, subscribes and blocks until ctx is
+// canceled, then marks the station offline and disconnects. It is also a
+// devices.EventSink, so the station's events can be piped into it.
+type Bridge struct {
+	devices.Base
+	cfg BridgeConfig
+
+	// stateMu orders state publications so a stale state sent on connect
+	// never overwrites a newer one.
+	stateMu sync.Mutex
+
+	mu      sync.Mutex
+	running bool
+
+	states map[string][]byte // last state payload by device, guarded by stateMu
+}
+
+// NewBridge constructs a Bridge.
+func NewBridge(cfg BridgeConfig) *Bridge {
+	if cfg.Name == "" {
+		cfg.Name = "mqtt"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "devices"
+	}
+	if cfg.Buf <= 0 {
+		cfg.Buf = 16
+	}
+	return &Bridge{
+		Base:   devices.NewBase(cfg.Name, cfg.Buf),
+		cfg:    cfg,
+		states: map[string][]byte{},
+	}
+}
+
+// AvailabilityTopic returns <prefix>/availability.
+func (b *Bridge) AvailabilityTopic() string {
+	return b.cfg.Prefix + "/availability"
+}
+
+// Topic returns <prefix>/<device>/<leaf>.
+func (b *Bridge) Topic(device, leaf string) string {
+	return b.cfg.Prefix + "/" + TopicName(device) + "/" + leaf
+}
+
+// Run connects to the broker and serves commands until ctx is canceled.
+func (b *Bridge) Run(ctx context.Context) error {
+	b.Emit(devices.EventOpen, "run", nil, nil)
+
+	if b.cfg.Client == nil {
+		b.Emit(devices.EventError, "client missing", ErrClientNil, nil)
+		b.Close()
+		return ErrClientNil
+	}
+	if b.cfg.Manager == nil {
+		b.Emit(devices.EventError, "manager missing", ErrManagerNil, nil)
+		b.Close()
+		return ErrManagerNil
+	}
+
+	will := &Message{Topic: b.AvailabilityTopic(), Payload: []byte(Offline), QoS: b.cfg.QoS, Retain: true}
+	if err := b.cfg.Client.Connect(ctx, will); err != nil {
+		b.Emit(devices.EventError, "connect failed", err, nil)
+		b.Close()
+		return err
+	}
+
+	defer func() {
+		b.mu.Lock()
+		b.running = false
+		b.mu.Unlock()
+
+		// best effort; ctx is already canceled
+		_ = b.cfg.Client.Publish(context.Background(), *withPayload(will, Offline))
+		_ = b.cfg.Client.Disconnect()
+		b.Emit(devices.EventClose, "stop", nil, nil)
+		b.Close()
+	}()
+
+	for _, d := range b.cfg.Manager.Devices() {
+		if err := b.subscribe(ctx, d); err != nil {
+			b.Emit(devices.EventError, "subscribe failed", err, map[string]string{"device": d.Name()})
+			return err
+		}
+	}
+
+	if b.cfg.Discovery {
+		if err := b.publishDiscovery(ctx); err != nil {
+			b.Emit(devices.EventError, "publish discovery failed", err, nil)
+			return err
+		}
+	}
+
+	if err := b.cfg.Client.Publish(ctx, *withPayload(will, Online)); err != nil {
+		b.Emit(devices.EventError, "publish availability failed", err, nil)
+		return err
+	}
+
+	b.stateMu.Lock()
+	b.mu.Lock()
+	b.running = true
+	b.mu.Unlock()
+	for device, data := range b.states {
+		m := Message{Topic: b.Topic(device, "state"), Payload: data, QoS: b.cfg.QoS, Retain: true}
+		if err := b.cfg.Client.Publish(ctx, m); err != nil {
+			b.Emit(devices.EventError, "publish state failed", err, map[string]string{"device": device})
+		}
+	}
+	b.stateMu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+func withPayload(m *Message, payload string) *Message {
+	c := *m
+	c.Payload = []byte(payload)
+	return &c
+}
+
+// subscribe routes <device>/set to d if d is a writable Sink[bool].
+func (b *Bridge) subscribe(ctx context.Context, d devices.Device) error {
+	sink, ok := d.(devices.Sink[bool])
+	if !ok {
+		return nil
+	}
+	if desc, ok := d.(devices.Described); ok && desc.Descriptor().Access == devices.ReadOnly {
+		return nil
+	}
+
+	topic := b.Topic(d.Name(), "set")
+	return b.cfg.Client.Subscribe(ctx, topic, b.cfg.QoS, func(m Message) {
+		v, err := ParseBool(m.Payload)
+		if err != nil {
+			b.Emit(devices.EventError, "bad command", err, map[string]string{
+				"device": d.Name(),
+				"topic":  m.Topic,
+			})
+			return
+		}
+		select {
+		case sink.In() <- v:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// publishDiscovery publishes the discovery configs of all Described devices,
+// skipping kinds without a mapping.
+func (b *Bridge) publishDiscovery(ctx context.Context) error {
+	cfg := DiscoveryConfig{
+		Prefix:          b.cfg.Prefix,
+		DiscoveryPrefix: b.cfg.DiscoveryPrefix,
+		Node:            b.cfg.Manager.Name(),
+	}
+	for _, desc := range b.cfg.Manager.Descriptors() {
+		entities, err := Discover(cfg, desc)
+		if errors.Is(err, ErrNoDiscovery) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entities {
+			m, err := e.Message()
+			if err != nil {
+				return err
+			}
+			m.QoS = b.cfg.QoS
+			if err := b.cfg.Client.Publish(ctx, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseBool parses a command payload: true/false, on/off or 1/0, any case.
+func ParseBool(payload []byte) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(string(payload))) {
+	case "true", "on", "1":
+		return true, nil
+	case "false", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("mqtt: invalid bool payload %q", payload)
+}
+
+// PublishState publishes v as JSON to <prefix>/<device>/state (retained).
+// Before Run has connected the state is kept and published on connect.
+func (b *Bridge) PublishState(ctx context.Context, device string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+
+	b.states[device] = data
+	err = b.publish(ctx, Message{Topic: b.Topic(device, "state"), Payload: data, Retain: true})
+	if errors.Is(err, ErrNotRunning) {
+		return nil
+	}
+	return err
+}
+
+func (b *Bridge) publish(ctx context.Context, m Message) error {
+	b.mu.Lock()
+	running := b.running
+	b.mu.Unlock()
+	if !running {
+		return ErrNotRunning
+	}
+	m.QoS = b.cfg.QoS
+	return b.cfg.Client.Publish(ctx, m)
+}
+
+// WriteEvent publishes ev as JSON to <prefix>/<device>/events.
+// It returns ErrNotRunning while disconnected; events are not queued.
+// The Bridge's own events are not published, to avoid feedback loops.
+func (b *Bridge) WriteEvent(ev devices.Event) error {
+	if ev.Device == b.Name() {
+		return nil
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return b.publish(context.Background(), Message{Topic: b.Topic(ev.Device, "events"), Payload: data})
+}
+
+// Watch publishes every value received from in as device's state until in
+// is closed or ctx is canceled. Publish errors are reported as EventError.
+//
+// Watch consumes in; to bridge a device that has other consumers, watch a
+// Hub subscription instead of the device's Out().
+func Watch[T any](ctx context.Context, b *Bridge, device string, in <-chan T) {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			if err := b.PublishState(ctx, device, v); err != nil {
+				b.Emit(devices.EventError, "publish state failed", err, map[string]string{
+					"device": device,
+					"value":  fmt.Sprint(v),
+				})
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+var _ devices.EventSink = (*Bridge)(nil)
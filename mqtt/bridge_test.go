@@ -0,0 +1,199 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/mock"
+)
+
+// lockedSwitch is a Sink[bool] whose Descriptor says it is read-only.
+type lockedSwitch struct {
+	*mock.Switch
+}
+
+func (s lockedSwitch) Descriptor() devices.Descriptor {
+	return devices.Descriptor{Name: s.Name(), Access: devices.ReadOnly}
+}
+
+type bridgeFixture struct {
+	broker *MemoryBroker
+	client *MemoryClient
+	bridge *Bridge
+	pump   *mock.Switch
+	locked lockedSwitch
+	cancel context.CancelFunc
+	errCh  chan error
+}
+
+// startBridge runs a bridge (prefix "garden") over a running pump and
+// locked switch, and waits until it is online.
+func startBridge(t *testing.T) *bridgeFixture {
+	t.Helper()
+
+	f := &bridgeFixture{
+		broker: NewMemoryBroker(),
+		pump:   mock.NewSwitch(mock.SwitchConfig{Name: "pump"}),
+		locked: lockedSwitch{mock.NewSwitch(mock.SwitchConfig{Name: "locked"})},
+		errCh:  make(chan error, 1),
+	}
+	f.client = f.broker.Client()
+
+	m := devices.NewManager("garden", 0)
+	require.NoError(t, m.Add(f.pump))
+	require.NoError(t, m.Add(f.locked))
+
+	f.bridge = NewBridge(BridgeConfig{Client: f.client, Manager: m, Prefix: "garden"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	go func() { _ = m.Run(ctx) }()
+	go func() { f.errCh <- f.bridge.Run(ctx) }()
+	t.Cleanup(cancel)
+
+	// initial states
+	require.False(t, <-f.pump.Out())
+	require.False(t, <-f.locked.Out())
+
+	require.Eventually(t, func() bool {
+		m, ok := f.broker.Retained("garden/availability")
+		return ok && string(m.Payload) == Online
+	}, time.Second, time.Millisecond)
+	return f
+}
+
+func (f *bridgeFixture) publish(t *testing.T, topic, payload string) {
+	t.Helper()
+	c := f.broker.Client()
+	require.NoError(t, c.Connect(context.Background(), nil))
+	require.NoError(t, c.Publish(context.Background(), Message{Topic: topic, Payload: []byte(payload)}))
+}
+
+func TestBridgeAvailability(t *testing.T) {
+	t.Parallel()
+
+	f := startBridge(t)
+	assert.Equal(t, "garden/availability", f.bridge.AvailabilityTopic())
+
+	f.cancel()
+	require.NoError(t, <-f.errCh)
+
+	m, ok := f.broker.Retained("garden/availability")
+	require.True(t, ok)
+	assert.Equal(t, Offline, string(m.Payload))
+	assert.False(t, f.client.Connected())
+}
+
+func TestBridgeLastWill(t *testing.T) {
+	t.Parallel()
+
+	f := startBridge(t)
+	f.client.Kill()
+
+	m, ok := f.broker.Retained("garden/availability")
+	require.True(t, ok)
+	assert.Equal(t, Offline, string(m.Payload))
+}
+
+func TestBridgeCommands(t *testing.T) {
+	t.Parallel()
+
+	f := startBridge(t)
+
+	f.publish(t, "garden/pump/set", "ON")
+	f.publish(t, "garden/pump/set", "false")
+	assert.True(t, <-f.pump.Out())
+	assert.False(t, <-f.pump.Out())
+
+	f.publish(t, "garden/pump/set", "maybe")
+	var bad devices.Event
+	require.Eventually(t, func() bool {
+		select {
+		case ev := <-f.bridge.Events():
+			bad = ev
+			return ev.Msg == "bad command"
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "pump", bad.Meta["device"])
+
+	// read-only devices are not subscribed
+	f.publish(t, "garden/locked/set", "on")
+	select {
+	case v := <-f.locked.Out():
+		t.Fatalf("read-only device received %v", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestBridgeStateAndEvents(t *testing.T) {
+	t.Parallel()
+
+	f := startBridge(t)
+
+	in := make(chan float64, 1)
+	in <- 42.5
+	close(in)
+	Watch(context.Background(), f.bridge, "soil", in)
+
+	m, ok := f.broker.Retained("garden/soil/state")
+	require.True(t, ok)
+	assert.Equal(t, "42.5", string(m.Payload))
+
+	var got []Message
+	sub := f.broker.Client()
+	require.NoError(t, sub.Connect(context.Background(), nil))
+	require.NoError(t, sub.Subscribe(context.Background(), "garden/+/events", 0, func(m Message) { got = append(got, m) }))
+
+	require.NoError(t, f.bridge.WriteEvent(devices.Event{Device: "pump", Kind: devices.EventInfo, Msg: "set", Time: time.Now()}))
+	require.NoError(t, f.bridge.WriteEvent(devices.Event{Device: f.bridge.Name(), Kind: devices.EventInfo, Msg: "self"}))
+	require.Len(t, got, 1)
+	assert.Equal(t, "garden/pump/events", got[0].Topic)
+
+	var ev devices.Event
+	require.NoError(t, json.Unmarshal(got[0].Payload, &ev))
+	assert.Equal(t, "set", ev.Msg)
+}
+
+func TestBridgeStateBeforeConnect(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMemoryBroker()
+	b := NewBridge(BridgeConfig{Client: broker.Client(), Manager: devices.NewManager("m", 0), Prefix: "p"})
+
+	require.NoError(t, b.PublishState(context.Background(), "soil", 1.5))
+	assert.ErrorIs(t, b.WriteEvent(devices.Event{Device: "soil"}), ErrNotRunning)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = b.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		m, ok := broker.Retained("p/soil/state")
+		return ok && string(m.Payload) == "1.5"
+	}, time.Second, time.Millisecond)
+}
+
+func TestParseBool(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"true", "ON", " on ", "1"} {
+		v, err := ParseBool([]byte(s))
+		require.NoError(t, err, s)
+		assert.True(t, v, s)
+	}
+	for _, s := range []string{"false", "OFF", "0"} {
+		v, err := ParseBool([]byte(s))
+		require.NoError(t, err, s)
+		assert.False(t, v, s)
+	}
+	_, err := ParseBool([]byte("2"))
+	assert.Error(t, err)
+}
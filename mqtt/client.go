@@ -0,0 +1,69 @@
+// Package mqtt bridges devices to an MQTT broker.
+//
+// The package does not depend on an MQTT library: the Bridge talks to the
+// broker through the small Client interface, which is easy to adapt to
+// e.g. paho. MemoryBroker is an in-process implementation for tests and
+// examples.
+package mqtt
+
+import (
+	"context"
+	"strings"
+)
+
+// Message is a single MQTT publication.
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Client is the subset of an MQTT client the Bridge needs.
+//
+// Implementations must be safe for concurrent use. Handlers may be called
+// from any goroutine and must not block for long.
+type Client interface {
+	// Connect connects to the broker. If will is non-nil it is registered
+	// as the last-will message, published by the broker if the connection
+	// is lost without Disconnect.
+	Connect(ctx context.Context, will *Message) error
+
+	// Publish sends msg.
+	Publish(ctx context.Context, msg Message) error
+
+	// Subscribe calls h for every message matching filter
+	// (MQTT wildcards + and # are supported).
+	Subscribe(ctx context.Context, filter string, qos byte, h func(Message)) error
+
+	// Disconnect closes the connection cleanly; the will is not published.
+	Disconnect() error
+}
+
+// Match reports whether topic matches the subscription filter.
+func Match(filter, topic string) bool {
+	f := strings.Split(filter, "/")
+	t := strings.Split(topic, "/")
+	for i, part := range f {
+		switch part {
+		case "#":
+			return true
+		case "+":
+			if i >= len(t) {
+				return false
+			}
+		default:
+			if i >= len(t) || t[i] != part {
+				return false
+			}
+		}
+	}
+	return len(f) == len(t)
+}
+
+var topicEscaper = strings.NewReplacer("/", "_", "+", "_", "#", "_", " ", "_")
+
+// TopicName makes a device name safe for use as one topic level.
+func TopicName(name string) string {
+	return topicEscaper.Replace(name)
+}
@@ -0,0 +1,38 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"a/b/c", "a/b/c", true},
+		{"a/b/c", "a/b", false},
+		{"a/b", "a/b/c", false},
+		{"a/+/c", "a/x/c", true},
+		{"a/+/c", "a/x/y", false},
+		{"a/+", "a", false},
+		{"a/#", "a/b/c", true},
+		{"a/#", "a", true},
+		{"#", "anything/at/all", true},
+		{"+/+/set", "garden/pump/set", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, Match(c.filter, c.topic), "%s ~ %s", c.filter, c.topic)
+	}
+}
+
+func TestTopicName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pump", TopicName("pump"))
+	assert.Equal(t, "soil_bed_1", TopicName("soil/bed 1"))
+	assert.Equal(t, "a_b_", TopicName("a+b#"))
+}
@@ -0,0 +1,214 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rustyeddy/devices"
+)
+
+// DefaultDiscoveryPrefix is Home Assistant's default discovery prefix.
+const DefaultDiscoveryPrefix = "homeassistant"
+
+// ErrNoDiscovery is returned for device kinds without a Home Assistant mapping.
+var ErrNoDiscovery = errors.New("mqtt: no home assistant mapping for device kind")
+
+// DiscoveryConfig selects the topics used in discovery payloads. They must
+// match the Bridge that serves the devices.
+type DiscoveryConfig struct {
+	// Prefix is the Bridge topic prefix (state, set and availability).
+	// Default "devices".
+	Prefix string
+
+	// DiscoveryPrefix is the Home Assistant discovery prefix.
+	// Default "homeassistant".
+	DiscoveryPrefix string
+
+	// Node optionally groups the config topics of one station:
+	// <discovery>/<component>/<node>/<object>/config.
+	Node string
+}
+
+// HADevice is the "device" block of a discovery payload.
+type HADevice struct {
+	Identifiers []string `json:"identifiers"`
+	Name        string   `json:"name"`
+	Model       string   `json:"model,omitempty"`
+}
+
+// HAConfig is a Home Assistant MQTT discovery payload.
+// Only the fields used by the mappings below are included.
+type HAConfig struct {
+	Name              string `json:"name"`
+	UniqueID          string `json:"unique_id"`
+	ObjectID          string `json:"object_id,omitempty"`
+	StateTopic        string `json:"state_topic,omitempty"`
+	CommandTopic      string `json:"command_topic,omitempty"`
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	PayloadOn  string `json:"payload_on,omitempty"`
+	PayloadOff string `json:"payload_off,omitempty"`
+
+	DeviceClass       string `json:"device_class,omitempty"`
+	UnitOfMeasurement string `json:"unit_of_measurement,omitempty"`
+	StateClass        string `json:"state_class,omitempty"`
+	ValueTemplate     string `json:"value_template,omitempty"`
+
+	JSONAttributesTopic    string `json:"json_attributes_topic,omitempty"`
+	JSONAttributesTemplate string `json:"json_attributes_template,omitempty"`
+	SourceType             string `json:"source_type,omitempty"`
+
+	Device HADevice `json:"device"`
+}
+
+// Discovery is one entity's config and the topic it is published to
+// (retained).
+type Discovery struct {
+	Component string
+	Topic     string
+	Config    HAConfig
+}
+
+// Message returns d as a retained MQTT message.
+func (d Discovery) Message() (Message, error) {
+	data, err := json.Marshal(d.Config)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Topic: d.Topic, Payload: data, Retain: true}, nil
+}
+
+// Discover maps desc to Home Assistant discovery configs:
+//
+//	relay   switch
+//	led     light
+//	button  binary_sensor
+//	vh400   sensor (moisture, %)
+//	bme280  three sensors: temperature, pressure, humidity
+//	gps     device_tracker
+//
+// Unique IDs are derived from the device name and its hardware address
+// attributes (see UniqueID), so they survive restarts and config reloads.
+func Discover(cfg DiscoveryConfig, desc devices.Descriptor) ([]Discovery, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "devices"
+	}
+	if cfg.DiscoveryPrefix == "" {
+		cfg.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+
+	uid := UniqueID(desc)
+	base := HAConfig{
+		Name:              desc.Name,
+		UniqueID:          uid,
+		ObjectID:          uid,
+		StateTopic:        cfg.Prefix + "/" + TopicName(desc.Name) + "/state",
+		AvailabilityTopic: cfg.Prefix + "/availability",
+		Device: HADevice{
+			Identifiers: []string{uid},
+			Name:        desc.Name,
+			Model:       desc.Kind,
+		},
+	}
+	commandTopic := cfg.Prefix + "/" + TopicName(desc.Name) + "/set"
+
+	one := func(component string, c HAConfig) []Discovery {
+		return []Discovery{{Component: component, Topic: cfg.topic(component, c.ObjectID), Config: c}}
+	}
+
+	// The Bridge publishes bool state as JSON true/false and accepts the
+	// same payloads on /set.
+	switch desc.Kind {
+	case "relay":
+		c := base
+		c.CommandTopic = commandTopic
+		c.PayloadOn, c.PayloadOff = "true", "false"
+		return one("switch", c), nil
+
+	case "led":
+		c := base
+		c.CommandTopic = commandTopic
+		c.PayloadOn, c.PayloadOff = "true", "false"
+		return one("light", c), nil
+
+	case "button":
+		c := base
+		c.PayloadOn, c.PayloadOff = "true", "false"
+		return one("binary_sensor", c), nil
+
+	case "vh400":
+		c := base
+		c.DeviceClass = "moisture"
+		c.UnitOfMeasurement = "%"
+		c.StateClass = "measurement"
+		return one("sensor", c), nil
+
+	case "bme280":
+		fields := []struct{ field, class, unit string }{
+			{"temperature", "temperature", "°C"},
+			{"pressure", "pressure", "Pa"},
+			{"humidity", "humidity", "%"},
+		}
+		out := make([]Discovery, 0, len(fields))
+		for _, f := range fields {
+			c := base
+			c.Name = desc.Name + " " + f.field
+			c.UniqueID = uid + "_" + f.field
+			c.ObjectID = c.UniqueID
+			c.DeviceClass = f.class
+			c.UnitOfMeasurement = f.unit
+			c.StateClass = "measurement"
+			c.ValueTemplate = "{{ value_json." + f.field + " }}"
+			out = append(out, one("sensor", c)...)
+		}
+		return out, nil
+
+	case "gps":
+		c := base
+		c.StateTopic = ""
+		c.JSONAttributesTopic = base.StateTopic
+		c.JSONAttributesTemplate = `{"latitude": {{ value_json.Lat }}, "longitude": {{ value_json.Lon }}}`
+		c.SourceType = "gps"
+		return one("device_tracker", c), nil
+	}
+	return nil, fmt.Errorf("%w: %q (%s)", ErrNoDiscovery, desc.Kind, desc.Name)
+}
+
+func (cfg DiscoveryConfig) topic(component, objectID string) string {
+	parts := []string{cfg.DiscoveryPrefix, component}
+	if cfg.Node != "" {
+		parts = append(parts, idPart(cfg.Node))
+	}
+	parts = append(parts, objectID, "config")
+	return strings.Join(parts, "/")
+}
+
+// idAttributes are the Descriptor attributes that identify the hardware
+// a device is attached to, in the order they appear in unique IDs.
+var idAttributes = []string{"chip", "offset", "bus", "addr", "channel", "port"}
+
+// UniqueID returns a stable Home Assistant unique_id for desc: the name
+// followed by whichever of chip, offset, bus, addr, channel and port are
+// set, e.g. "pump_gpiochip0_17" or "env_1_0x76".
+func UniqueID(desc devices.Descriptor) string {
+	parts := []string{idPart(desc.Name)}
+	for _, k := range idAttributes {
+		if v := desc.Attributes[k]; v != "" {
+			parts = append(parts, idPart(v))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// idPart lowercases s and replaces anything but [a-z0-9-] with '_'.
+func idPart(s string) string {
+	b := []byte(strings.ToLower(s))
+	for i, c := range b {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
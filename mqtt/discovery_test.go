@@ -0,0 +1,145 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rustyeddy/devices"
+	"github.com/rustyeddy/devices/mock"
+)
+
+func TestUniqueID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pump_gpiochip0_17", UniqueID(devices.Descriptor{
+		Name:       "Pump",
+		Attributes: map[string]string{"chip": "gpiochip0", "offset": "17", "bias": "pull-up"},
+	}))
+	assert.Equal(t, "env_1_0x76", UniqueID(devices.Descriptor{
+		Name:       "env",
+		Attributes: map[string]string{"addr": "0x76", "bus": "1"},
+	}))
+	assert.Equal(t, "back_porch", UniqueID(devices.Descriptor{Name: "back porch"}))
+}
+
+func TestDiscoverRelay(t *testing.T) {
+	t.Parallel()
+
+	got, err := Discover(DiscoveryConfig{Prefix: "garden", Node: "Garden"}, devices.Descriptor{
+		Name: "pump", Kind: "relay",
+		Attributes: map[string]string{"chip": "gpiochip0", "offset": "17"},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	d := got[0]
+	assert.Equal(t, "switch", d.Component)
+	assert.Equal(t, "homeassistant/switch/garden/pump_gpiochip0_17/config", d.Topic)
+
+	m, err := d.Message()
+	require.NoError(t, err)
+	assert.True(t, m.Retain)
+	assert.JSONEq(t, `{
+		"name": "pump",
+		"unique_id": "pump_gpiochip0_17",
+		"object_id": "pump_gpiochip0_17",
+		"state_topic": "garden/pump/state",
+		"command_topic": "garden/pump/set",
+		"availability_topic": "garden/availability",
+		"payload_on": "true",
+		"payload_off": "false",
+		"device": {"identifiers": ["pump_gpiochip0_17"], "name": "pump", "model": "relay"}
+	}`, string(m.Payload))
+}
+
+func TestDiscoverKinds(t *testing.T) {
+	t.Parallel()
+
+	cfg := DiscoveryConfig{}
+
+	button, err := Discover(cfg, devices.Descriptor{Name: "btn", Kind: "button"})
+	require.NoError(t, err)
+	assert.Equal(t, "binary_sensor", button[0].Component)
+	assert.Empty(t, button[0].Config.CommandTopic)
+	assert.Equal(t, "homeassistant/binary_sensor/btn/config", button[0].Topic)
+
+	led, err := Discover(cfg, devices.Descriptor{Name: "led", Kind: "led"})
+	require.NoError(t, err)
+	assert.Equal(t, "light", led[0].Component)
+	assert.Equal(t, "devices/led/set", led[0].Config.CommandTopic)
+
+	soil, err := Discover(cfg, devices.Descriptor{Name: "soil", Kind: "vh400", Unit: "%"})
+	require.NoError(t, err)
+	assert.Equal(t, "sensor", soil[0].Component)
+	assert.Equal(t, "moisture", soil[0].Config.DeviceClass)
+	assert.Equal(t, "%", soil[0].Config.UnitOfMeasurement)
+
+	env, err := Discover(cfg, devices.Descriptor{
+		Name: "env", Kind: "bme280",
+		Attributes: map[string]string{"bus": "1", "addr": "0x76"},
+	})
+	require.NoError(t, err)
+	require.Len(t, env, 3)
+	classes := map[string]HAConfig{}
+	for _, d := range env {
+		assert.Equal(t, "sensor", d.Component)
+		assert.Equal(t, []string{"env_1_0x76"}, d.Config.Device.Identifiers, "one HA device for all three")
+		classes[d.Config.DeviceClass] = d.Config
+	}
+	assert.Equal(t, "env_1_0x76_temperature", classes["temperature"].UniqueID)
+	assert.Equal(t, "{{ value_json.pressure }}", classes["pressure"].ValueTemplate)
+	assert.Equal(t, "%", classes["humidity"].UnitOfMeasurement)
+
+	gps, err := Discover(cfg, devices.Descriptor{Name: "gps", Kind: "gps"})
+	require.NoError(t, err)
+	assert.Equal(t, "device_tracker", gps[0].Component)
+	assert.Equal(t, "gps", gps[0].Config.SourceType)
+	assert.Equal(t, "devices/gps/state", gps[0].Config.JSONAttributesTopic)
+
+	_, err = Discover(cfg, devices.Descriptor{Name: "screen", Kind: "oled"})
+	assert.ErrorIs(t, err, ErrNoDiscovery)
+}
+
+// kindSwitch is a mock switch described as the given kind.
+type kindSwitch struct {
+	*mock.Switch
+	kind string
+}
+
+func (s kindSwitch) Descriptor() devices.Descriptor {
+	return devices.Descriptor{Name: s.Name(), Kind: s.kind}
+}
+
+func newDescribedDevice(name, kind string) kindSwitch {
+	return kindSwitch{mock.NewSwitch(mock.SwitchConfig{Name: name}), kind}
+}
+
+func TestBridgePublishesDiscovery(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMemoryBroker()
+	m := devices.NewManager("garden", 0)
+	require.NoError(t, m.Add(newDescribedDevice("pump", "relay")))
+	require.NoError(t, m.Add(newDescribedDevice("screen", "oled")))
+	b := NewBridge(BridgeConfig{Client: broker.Client(), Manager: m, Prefix: "garden", Discovery: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = b.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		_, ok := broker.Retained("garden/availability")
+		return ok
+	}, time.Second, time.Millisecond)
+
+	msg, ok := broker.Retained("homeassistant/switch/garden/pump/config")
+	require.True(t, ok, "published before online")
+	var c HAConfig
+	require.NoError(t, json.Unmarshal(msg.Payload, &c))
+	assert.Equal(t, "garden/pump/set", c.CommandTopic)
+}
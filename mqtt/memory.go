@@ -0,0 +1,168 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNotConnected = errors.New("mqtt: not connected")
+
+// MemoryBroker is an in-process MQTT broker for tests and examples.
+//
+// It keeps retained messages, matches wildcards and publishes a client's
+// will when the client is killed (see MemoryClient.Kill). Delivery is
+// synchronous: Publish returns after every matching handler has run.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	retained map[string]Message
+	clients  map[*MemoryClient]struct{}
+}
+
+// NewMemoryBroker constructs an empty broker.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		retained: map[string]Message{},
+		clients:  map[*MemoryClient]struct{}{},
+	}
+}
+
+// Client returns a new, unconnected client of b.
+func (b *MemoryBroker) Client() *MemoryClient {
+	return &MemoryClient{b: b}
+}
+
+// Retained returns the retained message for topic.
+func (b *MemoryBroker) Retained(topic string) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.retained[topic]
+	return m, ok
+}
+
+func (b *MemoryBroker) publish(msg Message) {
+	b.mu.Lock()
+	if msg.Retain {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.Topic)
+		} else {
+			b.retained[msg.Topic] = msg
+		}
+	}
+	var handlers []func(Message)
+	for c := range b.clients {
+		c.mu.Lock()
+		for _, s := range c.subs {
+			if Match(s.filter, msg.Topic) {
+				handlers = append(handlers, s.h)
+			}
+		}
+		c.mu.Unlock()
+	}
+	b.mu.Unlock()
+
+	// Retain is only set on messages delivered from the retained store.
+	msg.Retain = false
+	for _, h := range handlers {
+		h(msg)
+	}
+}
+
+type subscription struct {
+	filter string
+	h      func(Message)
+}
+
+// MemoryClient is a Client connected to a MemoryBroker.
+type MemoryClient struct {
+	b *MemoryBroker
+
+	mu        sync.Mutex
+	connected bool
+	will      *Message
+	subs      []subscription
+}
+
+// Connect registers the client with the broker.
+func (c *MemoryClient) Connect(ctx context.Context, will *Message) error {
+	c.mu.Lock()
+	c.connected = true
+	c.will = will
+	c.mu.Unlock()
+
+	c.b.mu.Lock()
+	c.b.clients[c] = struct{}{}
+	c.b.mu.Unlock()
+	return nil
+}
+
+// Publish delivers msg to every matching subscriber.
+func (c *MemoryClient) Publish(ctx context.Context, msg Message) error {
+	if !c.Connected() {
+		return ErrNotConnected
+	}
+	c.b.publish(msg)
+	return nil
+}
+
+// Subscribe adds a subscription and delivers matching retained messages.
+func (c *MemoryClient) Subscribe(ctx context.Context, filter string, qos byte, h func(Message)) error {
+	c.mu.Lock()
+	if !c.connected {
+		c.mu.Unlock()
+		return ErrNotConnected
+	}
+	c.subs = append(c.subs, subscription{filter: filter, h: h})
+	c.mu.Unlock()
+
+	c.b.mu.Lock()
+	var retained []Message
+	for topic, m := range c.b.retained {
+		if Match(filter, topic) {
+			retained = append(retained, m)
+		}
+	}
+	c.b.mu.Unlock()
+
+	for _, m := range retained {
+		h(m)
+	}
+	return nil
+}
+
+// Connected reports whether the client is connected.
+func (c *MemoryClient) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+// Disconnect disconnects cleanly; the will is discarded.
+func (c *MemoryClient) Disconnect() error {
+	c.drop()
+	return nil
+}
+
+// Kill simulates a lost connection: the broker publishes the will.
+func (c *MemoryClient) Kill() {
+	if will := c.drop(); will != nil {
+		c.b.publish(*will)
+	}
+}
+
+// drop removes c from the broker and returns its will.
+func (c *MemoryClient) drop() *Message {
+	c.b.mu.Lock()
+	delete(c.b.clients, c)
+	c.b.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	will := c.will
+	c.connected = false
+	c.will = nil
+	c.subs = nil
+	return will
+}
+
+var _ Client = (*MemoryClient)(nil)
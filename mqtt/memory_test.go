@@ -0,0 +1,67 @@
+package mqtt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBrokerRetainAndDeliver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b := NewMemoryBroker()
+
+	pub := b.Client()
+	require.ErrorIs(t, pub.Publish(ctx, Message{Topic: "x"}), ErrNotConnected)
+	require.NoError(t, pub.Connect(ctx, nil))
+	require.NoError(t, pub.Publish(ctx, Message{Topic: "s/a/state", Payload: []byte("1"), Retain: true}))
+
+	sub := b.Client()
+	require.NoError(t, sub.Connect(ctx, nil))
+	var got []Message
+	require.NoError(t, sub.Subscribe(ctx, "s/+/state", 0, func(m Message) { got = append(got, m) }))
+
+	// retained message delivered on subscribe
+	require.Len(t, got, 1)
+	assert.True(t, got[0].Retain)
+	assert.Equal(t, "1", string(got[0].Payload))
+
+	require.NoError(t, pub.Publish(ctx, Message{Topic: "s/a/state", Payload: []byte("2"), Retain: true}))
+	require.NoError(t, pub.Publish(ctx, Message{Topic: "s/a/events", Payload: []byte("e")}))
+	require.Len(t, got, 2)
+	assert.False(t, got[1].Retain, "live messages are not flagged retained")
+
+	m, ok := b.Retained("s/a/state")
+	require.True(t, ok)
+	assert.Equal(t, "2", string(m.Payload))
+
+	// empty retained payload clears the topic
+	require.NoError(t, pub.Publish(ctx, Message{Topic: "s/a/state", Retain: true}))
+	_, ok = b.Retained("s/a/state")
+	assert.False(t, ok)
+}
+
+func TestMemoryClientWill(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b := NewMemoryBroker()
+	will := &Message{Topic: "s/availability", Payload: []byte(Offline), Retain: true}
+
+	clean := b.Client()
+	require.NoError(t, clean.Connect(ctx, will))
+	require.NoError(t, clean.Disconnect())
+	_, ok := b.Retained("s/availability")
+	assert.False(t, ok, "clean disconnect discards the will")
+
+	lost := b.Client()
+	require.NoError(t, lost.Connect(ctx, will))
+	lost.Kill()
+	m, ok := b.Retained("s/availability")
+	require.True(t, ok)
+	assert.Equal(t, Offline, string(m.Payload))
+	assert.False(t, lost.Connected())
+}
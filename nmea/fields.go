@@ -0,0 +1,197 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// fields decodes the data fields of one sentence. The first error is
+// kept and later reads return zero values, so a parser can read every
+// field and check err once.
+type fields struct {
+	typ string
+	f   []string
+	err error
+}
+
+// need fails unless there are at least n fields.
+func (r *fields) need(n int) bool {
+	if r.err == nil && len(r.f) < n {
+		r.err = fmt.Errorf("%w: %s has %d fields, want at least %d", ErrMalformed, r.typ, len(r.f), n)
+	}
+	return r.err == nil
+}
+
+func (r *fields) fail(name, s string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s %s %q", ErrMalformed, r.typ, name, s)
+	}
+}
+
+// str returns field i, or "" past the end: trailing fields added by later
+// NMEA versions are optional.
+func (r *fields) str(i int) string {
+	if i < len(r.f) {
+		return r.f[i]
+	}
+	return ""
+}
+
+// float parses field i as a decimal number, NaN if empty.
+func (r *fields) float(i int, name string) float64 {
+	s := r.str(i)
+	if s == "" {
+		return math.NaN()
+	}
+	if !isDecimal(s, true) {
+		r.fail(name, s)
+		return math.NaN()
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.fail(name, s)
+		return math.NaN()
+	}
+	return v
+}
+
+// int parses field i as an integer, 0 if empty.
+func (r *fields) int(i int, name string) int {
+	s := r.str(i)
+	if s == "" {
+		return 0
+	}
+	if !isDecimal(s, false) {
+		r.fail(name, s)
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		r.fail(name, s)
+		return 0
+	}
+	return v
+}
+
+// latLon parses the four fields from i, "ddmm.mm,N,dddmm.mm,E", into
+// signed decimal degrees; both are NaN if the position is empty.
+func (r *fields) latLon(i int) (lat, lon float64) {
+	if r.str(i) == "" && r.str(i+2) == "" {
+		return math.NaN(), math.NaN()
+	}
+	lat = r.degrees(i, "latitude", 90, "N", "S")
+	lon = r.degrees(i+2, "longitude", 180, "E", "W")
+	return lat, lon
+}
+
+// degrees parses an NMEA (d)ddmm.mmmm angle at i and its hemisphere at
+// i+1; neg is the hemisphere that makes it negative.
+func (r *fields) degrees(i int, name string, limit float64, pos, neg string) float64 {
+	s := r.str(i)
+	v := r.float(i, name)
+	if s == "" {
+		r.fail(name, s) // only half a position
+	}
+	if r.err != nil {
+		return math.NaN()
+	}
+	deg := math.Floor(v / 100)
+	mins := v - deg*100
+	if v < 0 || mins >= 60 || deg+mins/60 > limit {
+		r.fail(name, s)
+		return math.NaN()
+	}
+	v = deg + mins/60
+	switch h := r.str(i + 1); h {
+	case pos:
+	case neg:
+		v = -v
+	default:
+		r.fail(name+" hemisphere", h)
+		return math.NaN()
+	}
+	return v
+}
+
+// time parses an hhmmss(.sss) time of day at i.
+func (r *fields) time(i int) Time {
+	s := r.str(i)
+	if s == "" {
+		return Time{}
+	}
+	if len(s) < 6 || !isDigits(s[:6]) || (len(s) > 6 && (s[6] != '.' || !isDigits(s[7:]))) {
+		r.fail("time", s)
+		return Time{}
+	}
+	t := Time{Valid: true, Hour: atoi2(s[0:]), Minute: atoi2(s[2:]), Second: atoi2(s[4:])}
+	if t.Hour > 23 || t.Minute > 59 || t.Second > 60 { // 60: leap second
+		r.fail("time", s)
+		return Time{}
+	}
+	scale := 100_000_000
+	for _, c := range []byte(s[min(len(s), 7):]) {
+		t.Nanosecond += int(c-'0') * scale
+		scale /= 10
+	}
+	return t
+}
+
+// date parses a ddmmyy date at i. Two digit years are 1980-2079, the
+// span of GPS time.
+func (r *fields) date(i int) Date {
+	s := r.str(i)
+	if s == "" {
+		return Date{}
+	}
+	if len(s) != 6 || !isDigits(s) {
+		r.fail("date", s)
+		return Date{}
+	}
+	d := Date{Valid: true, Day: atoi2(s[0:]), Month: atoi2(s[2:]), Year: 2000 + atoi2(s[4:])}
+	if d.Year >= 2080 {
+		d.Year -= 100
+	}
+	if !validDate(d) {
+		r.fail("date", s)
+		return Date{}
+	}
+	return d
+}
+
+func validDate(d Date) bool {
+	return d.Day >= 1 && d.Day <= 31 && d.Month >= 1 && d.Month <= 12
+}
+
+// isDecimal reports whether s is an optionally signed decimal integer,
+// or with frac set, a number with an optional fraction. ParseFloat alone
+// would also take "NaN", "Inf" and hex.
+func isDecimal(s string, frac bool) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	digits, dot := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' && frac && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// atoi2 converts the two digits at the start of s.
+func atoi2(s string) int { return int(s[0]-'0')*10 + int(s[1]-'0') }
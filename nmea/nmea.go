@@ -0,0 +1,181 @@
+// Package nmea parses NMEA 0183 sentences, as sent by GPS and GNSS
+// receivers over a serial line.
+//
+// Every sentence's framing and "*hh" checksum are checked before its
+// fields are trusted, so a line garbled by a noisy UART is rejected
+// rather than turned into a bogus fix. Any talker is accepted (GP, GN,
+// GL, GA, BD, ...); GGA, RMC, VTG, GSA, GSV, GLL and ZDA are parsed into
+// typed structs:
+//
+//	s, err := nmea.Parse("$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59")
+//	if gga, ok := s.(nmea.GGA); ok {
+//		fmt.Println(gga.Talker, gga.Lat, gga.Lon)
+//	}
+//
+// A Parser does the same and counts the sentences it rejects. With
+// RequireChecksum set it also rejects sentences without a checksum,
+// which Split and Parse accept.
+//
+// Empty numeric fields, which receivers send for values they do not have
+// yet, parse as NaN (float64) or 0 (int).
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by Split and Parse, wrapped with details.
+var (
+	ErrChecksum    = errors.New("nmea: checksum mismatch")
+	ErrMalformed   = errors.New("nmea: malformed sentence")
+	ErrUnsupported = errors.New("nmea: unsupported sentence type")
+)
+
+// Talker IDs of the common satellite systems.
+const (
+	TalkerGPS     = "GP"
+	TalkerGNSS    = "GN" // a fix combining several systems
+	TalkerGLONASS = "GL"
+	TalkerGalileo = "GA"
+	TalkerBeiDou  = "BD" // also "GB" since NMEA 4.11
+)
+
+// Header is the address of a sentence: "$GNGGA" has Talker "GN" and
+// Type "GGA". Proprietary sentences ("$PMTK...") have Talker "P".
+type Header struct {
+	Talker string
+	Type   string
+}
+
+// Head returns h, so every sentence type is a Sentence.
+func (h Header) Head() Header { return h }
+
+// Sentence is a parsed sentence: a GGA, RMC, VTG, GSA, GSV, GLL or ZDA,
+// or a bare Header for a type this package does not parse.
+type Sentence interface {
+	Head() Header
+}
+
+// Checksum returns the XOR of the bytes of body, the part of a sentence
+// between "$" and "*".
+func Checksum(body string) byte {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return sum
+}
+
+// Split checks the framing and checksum of line and returns its address
+// and data fields. Surrounding whitespace, such as the trailing CR LF,
+// is ignored. A sentence without a checksum is accepted; see
+// Parser.RequireChecksum.
+func Split(line string) (Header, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || (line[0] != '$' && line[0] != '!') {
+		return Header{}, nil, fmt.Errorf("%w: missing $", ErrMalformed)
+	}
+	body := line[1:]
+	if i := strings.LastIndexByte(body, '*'); i >= 0 {
+		hex := body[i+1:]
+		want, err := strconv.ParseUint(hex, 16, 8)
+		if err != nil || len(hex) != 2 {
+			return Header{}, nil, fmt.Errorf("%w: bad checksum %q", ErrMalformed, hex)
+		}
+		body = body[:i]
+		if got := Checksum(body); got != byte(want) {
+			return Header{}, nil, fmt.Errorf("%w: got %02X, want %02X", ErrChecksum, got, want)
+		}
+	}
+	for i := 0; i < len(body); i++ {
+		if c := body[i]; c < 0x20 || c > 0x7e || c == '$' || c == '!' || c == '*' {
+			return Header{}, nil, fmt.Errorf("%w: invalid character %q", ErrMalformed, c)
+		}
+	}
+
+	fields := strings.Split(body, ",")
+	h, err := parseAddress(fields[0])
+	if err != nil {
+		return Header{}, nil, err
+	}
+	return h, fields[1:], nil
+}
+
+// parseAddress splits "GPGGA" into talker and type.
+func parseAddress(addr string) (Header, error) {
+	for i := 0; i < len(addr); i++ {
+		if c := addr[i]; (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return Header{}, fmt.Errorf("%w: bad address %q", ErrMalformed, addr)
+		}
+	}
+	switch {
+	case len(addr) >= 2 && addr[0] == 'P':
+		return Header{Talker: "P", Type: addr[1:]}, nil
+	case len(addr) == 5:
+		return Header{Talker: addr[:2], Type: addr[2:]}, nil
+	}
+	return Header{}, fmt.Errorf("%w: bad address %q", ErrMalformed, addr)
+}
+
+// Parse checks line with Split and parses its fields. For a well-formed
+// sentence of a type it does not know, it returns the Header and an
+// error wrapping ErrUnsupported.
+func Parse(line string) (Sentence, error) {
+	h, f, err := Split(line)
+	if err != nil {
+		return nil, err
+	}
+	r := &fields{typ: h.Type, f: f}
+	var s Sentence
+	switch h.Type {
+	case "GGA":
+		s = parseGGA(h, r)
+	case "RMC":
+		s = parseRMC(h, r)
+	case "VTG":
+		s = parseVTG(h, r)
+	case "GSA":
+		s = parseGSA(h, r)
+	case "GSV":
+		s = parseGSV(h, r)
+	case "GLL":
+		s = parseGLL(h, r)
+	case "ZDA":
+		s = parseZDA(h, r)
+	default:
+		return h, fmt.Errorf("%w: %s%s", ErrUnsupported, h.Talker, h.Type)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return s, nil
+}
+
+// Time is a UTC time of day. Valid is false if the field was empty.
+type Time struct {
+	Valid      bool
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// Date is a UTC date. Valid is false if the field was empty.
+type Date struct {
+	Valid bool
+	Day   int
+	Month int
+	Year  int
+}
+
+// DateTime combines d and t. It reports false unless both are valid.
+func DateTime(d Date, t Time) (time.Time, bool) {
+	if !d.Valid || !t.Valid {
+		return time.Time{}, false
+	}
+	return time.Date(d.Year, time.Month(d.Month), d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, time.UTC), true
+}
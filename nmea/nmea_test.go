@@ -0,0 +1,228 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// samples are real-world sentences, one per supported type and talker.
+var samples = []string{
+	"$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59",
+	"$GPRMC,123519.25,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W,A*2E",
+	"$GLRMC,,V,,,,,,,,,,N*4F",
+	"$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K,A*25",
+	"$GNGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1,1*3A",
+	"$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74",
+	"$GAGSV,1,1,02,07,40,083,46,12,19,314,,7*76",
+	"$BDGLL,4916.45,N,12311.12,W,225444,A,A*4D",
+	"$GPZDA,201530.00,04,07,2002,-05,00*48",
+	"$GPGGA,,,,,,0,00,99.99,,,,,,*48",
+	"$PMTK001,604,3*32",
+	"$GPTXT,01,01,02,ANTSTATUS=OK*3B",
+}
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, byte(0x59), Checksum("GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,"))
+	assert.Equal(t, byte(0), Checksum(""))
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	h, f, err := Split("  $GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, Header{Talker: TalkerGNSS, Type: "GGA"}, h)
+	assert.Len(t, f, 14)
+	assert.Equal(t, "123519", f[0])
+
+	h, f, err = Split("$PMTK001,604,3*32")
+	require.NoError(t, err)
+	assert.Equal(t, Header{Talker: "P", Type: "MTK001"}, h)
+	assert.Equal(t, []string{"604", "3"}, f)
+
+	_, _, err = Split("$GPGGA,1,2") // no checksum is allowed
+	assert.NoError(t, err)
+
+	for _, c := range []struct {
+		line string
+		want error
+		msg  string
+	}{
+		{"", ErrMalformed, "missing $"},
+		{"GPGGA,1*00", ErrMalformed, "missing $"},
+		{"$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*58", ErrChecksum, "got 59, want 58"},
+		{"$GNGGA,123519,4807.038,N,01131.001,E,1,08,0.9,545.4,M,46.9,M,,*59", ErrChecksum, "got 58, want 59"},
+		{"$GPGGA,1*5", ErrMalformed, `bad checksum "5"`},
+		{"$GPGGA,1*ZZ", ErrMalformed, `bad checksum "ZZ"`},
+		{"$GP\x01GA,1", ErrMalformed, "invalid character"},
+		{"$GPG$GA,1", ErrMalformed, "invalid character"},
+		{"$gpgga,1", ErrMalformed, `bad address "gpgga"`},
+		{"$GPGGAX,1", ErrMalformed, `bad address "GPGGAX"`},
+		{"$,1", ErrMalformed, `bad address ""`},
+	} {
+		_, _, err := Split(c.line)
+		assert.ErrorIs(t, err, c.want, c.line)
+		assert.ErrorContains(t, err, c.msg, c.line)
+	}
+}
+
+func TestParseTalkers(t *testing.T) {
+	t.Parallel()
+
+	for _, talker := range []string{TalkerGPS, TalkerGNSS, TalkerGLONASS, TalkerGalileo, TalkerBeiDou, "GB", "GQ"} {
+		body := talker + "GGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,"
+		s, err := Parse(fmt.Sprintf("$%s*%02X", body, Checksum(body)))
+		require.NoError(t, err, talker)
+		gga := s.(GGA)
+		assert.Equal(t, talker, gga.Talker)
+		assert.Equal(t, talker, s.Head().Talker)
+		assert.InDelta(t, 48.1173, gga.Lat, 1e-4)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("$GPTXT,01,01,02,ANTSTATUS=OK*3B")
+	require.ErrorIs(t, err, ErrUnsupported)
+	assert.Equal(t, Header{Talker: "GP", Type: "TXT"}, s)
+
+	s, err = Parse("$PMTK001,604,3*32")
+	require.ErrorIs(t, err, ErrUnsupported)
+	assert.Equal(t, "P", s.Head().Talker)
+}
+
+func TestParseMalformedFields(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		line, msg string
+	}{
+		{"$GPGGA,123519,4807.038,N", "GGA has 3 fields, want at least 9"},
+		{"$GPGGA,123519,48x7.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA latitude "48x7.038"`},
+		{"$GPGGA,123519,NaN,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA latitude "NaN"`},
+		{"$GPGGA,123519,4867.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA latitude "4867.038"`},
+		{"$GPGGA,123519,9107.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA latitude "9107.038"`},
+		{"$GPGGA,123519,4807.038,X,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA latitude hemisphere "X"`},
+		{"$GPGGA,123519,4807.038,N,,E,1,08,0.9,545.4,M,46.9,M,,", `GGA longitude ""`},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,0x8,0.9,545.4,M,46.9,M,,", `GGA satellites "0x8"`},
+		{"$GPGGA,1235,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA time "1235"`},
+		{"$GPGGA,253519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA time "253519"`},
+		{"$GPGGA,123519.x,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", `GGA time "123519.x"`},
+		{"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,320394,,", `RMC date "320394"`},
+		{"$GPGSA,A,3,04,05", "GSA has 4 fields, want at least 17"},
+		{"$GPGSV,1,1,02,07,40,083", "GSV has 3 satellite fields, want a multiple of 4"},
+		{"$GPZDA,201530.00,04,13,2002,00,00", `ZDA date "04,13,2002"`},
+		{"$GPZDA,201530.00,04,07,02,00,00", `ZDA date "04,07,02"`},
+	} {
+		_, err := Parse(c.line)
+		assert.ErrorIs(t, err, ErrMalformed, c.line)
+		assert.ErrorContains(t, err, c.msg, c.line)
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	t.Parallel()
+
+	tm, ok := DateTime(Date{Valid: true, Day: 23, Month: 3, Year: 1994}, Time{Valid: true, Hour: 12, Minute: 35, Second: 19, Nanosecond: 250e6})
+	require.True(t, ok)
+	assert.Equal(t, time.Date(1994, 3, 23, 12, 35, 19, 250e6, time.UTC), tm)
+
+	_, ok = DateTime(Date{}, Time{Valid: true})
+	assert.False(t, ok)
+}
+
+// FuzzParse feeds arbitrary lines to Parse, as a noisy UART would.
+func FuzzParse(f *testing.F) {
+	for _, s := range samples {
+		f.Add(s)
+		f.Add(s[:len(s)/2])
+		f.Add(strings.Replace(s, ",", ",,", 1))
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		s, err := Parse(line)
+		if err != nil {
+			if !errors.Is(err, ErrMalformed) && !errors.Is(err, ErrChecksum) && !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("Parse(%q): unexpected error %v", line, err)
+			}
+			if !errors.Is(err, ErrUnsupported) && s != nil {
+				t.Fatalf("Parse(%q): sentence %#v with error %v", line, s, err)
+			}
+			return
+		}
+		checkSentence(t, line, s)
+	})
+}
+
+// checkSentence checks the invariants of a successfully parsed sentence.
+func checkSentence(t *testing.T, line string, s Sentence) {
+	inRange := func(name string, v, limit float64) {
+		if !math.IsNaN(v) && (v < -limit || v > limit) {
+			t.Fatalf("Parse(%q): %s %v out of range", line, name, v)
+		}
+	}
+	validTime := func(tm Time) {
+		if tm.Valid && (tm.Hour > 23 || tm.Minute > 59 || tm.Second > 60 || tm.Nanosecond >= 1e9) {
+			t.Fatalf("Parse(%q): bad time %+v", line, tm)
+		}
+	}
+	switch s := s.(type) {
+	case GGA:
+		inRange("lat", s.Lat, 90)
+		inRange("lon", s.Lon, 180)
+		validTime(s.Time)
+	case RMC:
+		inRange("lat", s.Lat, 90)
+		inRange("lon", s.Lon, 180)
+		validTime(s.Time)
+		if s.Date.Valid && !validDate(s.Date) {
+			t.Fatalf("Parse(%q): bad date %+v", line, s.Date)
+		}
+	case GLL:
+		inRange("lat", s.Lat, 90)
+		inRange("lon", s.Lon, 180)
+		validTime(s.Time)
+	case GSA:
+		if len(s.PRNs) > 12 {
+			t.Fatalf("Parse(%q): %d PRNs", line, len(s.PRNs))
+		}
+	case ZDA:
+		validTime(s.Time)
+	case VTG, GSV:
+	default:
+		t.Fatalf("Parse(%q): unexpected type %T", line, s)
+	}
+}
+
+// FuzzSplit checks that a sentence rebuilt from Split's output, with a
+// fresh checksum, splits to the same thing.
+func FuzzSplit(f *testing.F) {
+	for _, s := range samples {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		h, fields, err := Split(line)
+		if err != nil {
+			return
+		}
+		addr := h.Talker + h.Type
+		body := strings.Join(append([]string{addr}, fields...), ",")
+		again := fmt.Sprintf("$%s*%02X\r\n", body, Checksum(body))
+		h2, fields2, err := Split(again)
+		if err != nil {
+			t.Fatalf("Split(%q) = %v after Split(%q) succeeded", again, err, line)
+		}
+		if h2 != h || strings.Join(fields2, ",") != strings.Join(fields, ",") {
+			t.Fatalf("Split(%q) = %v %q, want %v %q", again, h2, fields2, h, fields)
+		}
+	})
+}
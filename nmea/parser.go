@@ -0,0 +1,64 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Stats counts the sentences a Parser has seen.
+type Stats struct {
+	Sentences   uint64 `json:"sentences"`    // lines parsed
+	Parsed      uint64 `json:"parsed"`       // of a supported type, without error
+	Unsupported uint64 `json:"unsupported"`  // well formed, of a type not parsed
+	BadChecksum uint64 `json:"bad_checksum"` // failed the checksum
+	Malformed   uint64 `json:"malformed"`    // bad framing or fields
+}
+
+// Parser parses sentences like Parse and counts the outcomes. The zero
+// value is ready to use and safe for concurrent use.
+type Parser struct {
+	// RequireChecksum rejects sentences without a "*hh" checksum as
+	// malformed. Set it for receivers that always send one: otherwise a
+	// single corrupted "*" turns a checked sentence into an unchecked
+	// one, with the old checksum left in its last field.
+	RequireChecksum bool
+
+	sentences   atomic.Uint64
+	parsed      atomic.Uint64
+	unsupported atomic.Uint64
+	badChecksum atomic.Uint64
+	malformed   atomic.Uint64
+}
+
+// Parse parses line with Parse and counts the result.
+func (p *Parser) Parse(line string) (Sentence, error) {
+	p.sentences.Add(1)
+	s, err := Parse(line)
+	if p.RequireChecksum && !errors.Is(err, ErrMalformed) && !strings.Contains(line, "*") {
+		s, err = nil, fmt.Errorf("%w: missing checksum", ErrMalformed)
+	}
+	switch {
+	case err == nil:
+		p.parsed.Add(1)
+	case errors.Is(err, ErrUnsupported):
+		p.unsupported.Add(1)
+	case errors.Is(err, ErrChecksum):
+		p.badChecksum.Add(1)
+	default:
+		p.malformed.Add(1)
+	}
+	return s, err
+}
+
+// Stats returns a snapshot of the counters.
+func (p *Parser) Stats() Stats {
+	return Stats{
+		Sentences:   p.sentences.Load(),
+		Parsed:      p.parsed.Load(),
+		Unsupported: p.unsupported.Load(),
+		BadChecksum: p.badChecksum.Load(),
+		Malformed:   p.malformed.Load(),
+	}
+}
@@ -0,0 +1,53 @@
+package nmea
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserStats(t *testing.T) {
+	t.Parallel()
+
+	var p Parser
+	lines := []string{
+		"$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59",
+		"$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K,A*25",
+		"$GPTXT,01,01,02,ANTSTATUS=OK*3B",
+		"$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*58",
+		"$GNGGA,123519,4807.0\x0038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59", // NUL slips past the XOR
+		"$GPGGA,123519,48x7.038,N",
+		"garbage",
+	}
+	var wg sync.WaitGroup
+	for _, l := range lines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = p.Parse(l)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, Stats{Sentences: 7, Parsed: 2, Unsupported: 1, BadChecksum: 1, Malformed: 3}, p.Stats())
+}
+
+func TestParserRequireChecksum(t *testing.T) {
+	t.Parallel()
+
+	p := Parser{RequireChecksum: true}
+	_, err := p.Parse("$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59")
+	assert.NoError(t, err)
+
+	// A corrupted "*": the checksum would otherwise land in DGPSStation.
+	line := "$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,#59"
+	s, err := Parse(line)
+	assert.NoError(t, err)
+	assert.Equal(t, "#59", s.(GGA).DGPSStation)
+
+	_, err = p.Parse(line)
+	assert.ErrorIs(t, err, ErrMalformed)
+	assert.ErrorContains(t, err, "missing checksum")
+	assert.Equal(t, Stats{Sentences: 2, Parsed: 1, Malformed: 1}, p.Stats())
+}
@@ -0,0 +1,229 @@
+package nmea
+
+import (
+	"fmt"
+	"time"
+)
+
+// KnotsToMPS converts knots to metres per second.
+const KnotsToMPS = 0.514444
+
+// GGA is a fix: time, position and quality.
+type GGA struct {
+	Header
+	Time        Time
+	Lat, Lon    float64 // decimal degrees, negative south/west; NaN without a fix
+	Quality     int     // 0 no fix, 1 GPS, 2 DGPS, 4 RTK, 5 float RTK, 6 estimated
+	Satellites  int     // in use
+	HDOP        float64
+	Altitude    float64 // metres above mean sea level
+	GeoidSep    float64 // metres from the WGS84 ellipsoid to mean sea level
+	DGPSAge     float64 // seconds since the last DGPS update
+	DGPSStation string
+}
+
+func parseGGA(h Header, r *fields) GGA {
+	if !r.need(9) {
+		return GGA{}
+	}
+	g := GGA{Header: h, Time: r.time(0)}
+	g.Lat, g.Lon = r.latLon(1)
+	g.Quality = r.int(5, "quality")
+	g.Satellites = r.int(6, "satellites")
+	g.HDOP = r.float(7, "hdop")
+	g.Altitude = r.float(8, "altitude")
+	g.GeoidSep = r.float(10, "geoid separation")
+	g.DGPSAge = r.float(12, "dgps age")
+	g.DGPSStation = r.str(13)
+	return g
+}
+
+// RMC is the recommended minimum: time, date, position, speed and course.
+type RMC struct {
+	Header
+	Time       Time
+	Status     string  // "A" valid, "V" warning
+	Lat, Lon   float64 // NaN without a fix
+	SpeedKnots float64
+	CourseDeg  float64 // true
+	Date       Date
+	MagVar     float64 // degrees, negative west
+	Mode       string  // NMEA 2.3: "A" autonomous, "D" differential, "N" no fix, ...
+}
+
+// Valid reports whether the receiver flagged the fix as valid.
+func (m RMC) Valid() bool { return m.Status == "A" }
+
+// DateTime returns the UTC time of the fix, if both date and time are set.
+func (m RMC) DateTime() (time.Time, bool) { return DateTime(m.Date, m.Time) }
+
+func parseRMC(h Header, r *fields) RMC {
+	if !r.need(9) {
+		return RMC{}
+	}
+	m := RMC{Header: h, Time: r.time(0), Status: r.str(1)}
+	m.Lat, m.Lon = r.latLon(2)
+	m.SpeedKnots = r.float(6, "speed")
+	m.CourseDeg = r.float(7, "course")
+	m.Date = r.date(8)
+	m.MagVar = r.float(9, "magnetic variation")
+	if r.str(10) == "W" {
+		m.MagVar = -m.MagVar
+	}
+	m.Mode = r.str(11)
+	return m
+}
+
+// VTG is the course and speed over ground.
+type VTG struct {
+	Header
+	TrueCourse float64 // degrees
+	MagCourse  float64 // degrees
+	SpeedKnots float64
+	SpeedKPH   float64
+	Mode       string
+}
+
+func parseVTG(h Header, r *fields) VTG {
+	if !r.need(7) {
+		return VTG{}
+	}
+	return VTG{
+		Header:     h,
+		TrueCourse: r.float(0, "true course"),
+		MagCourse:  r.float(2, "magnetic course"),
+		SpeedKnots: r.float(4, "speed"),
+		SpeedKPH:   r.float(6, "speed"),
+		Mode:       r.str(8),
+	}
+}
+
+// GSA lists the satellites used in the fix and the dilution of precision.
+type GSA struct {
+	Header
+	Mode     string // "M" manual, "A" automatic 2D/3D
+	FixType  int    // 1 none, 2 2D, 3 3D
+	PRNs     []int  // satellites used, up to 12
+	PDOP     float64
+	HDOP     float64
+	VDOP     float64
+	SystemID int // NMEA 4.1: 1 GPS, 2 GLONASS, 3 Galileo, 4 BeiDou; 0 if absent
+}
+
+func parseGSA(h Header, r *fields) GSA {
+	if !r.need(17) {
+		return GSA{}
+	}
+	g := GSA{Header: h, Mode: r.str(0), FixType: r.int(1, "fix type")}
+	for i := 2; i < 14; i++ {
+		if r.str(i) != "" {
+			g.PRNs = append(g.PRNs, r.int(i, "prn"))
+		}
+	}
+	g.PDOP = r.float(14, "pdop")
+	g.HDOP = r.float(15, "hdop")
+	g.VDOP = r.float(16, "vdop")
+	g.SystemID = r.int(17, "system id")
+	return g
+}
+
+// Satellite is one satellite in view, as reported by GSV.
+type Satellite struct {
+	PRN       int
+	Elevation int // degrees
+	Azimuth   int // degrees true
+	SNR       int // dB-Hz; 0 if not tracked
+}
+
+// GSV is one of a series of sentences listing the satellites in view,
+// up to four per sentence.
+type GSV struct {
+	Header
+	Total      int // sentences in the series
+	Number     int // this one, from 1
+	InView     int
+	Satellites []Satellite
+	SignalID   int // NMEA 4.1; 0 if absent
+}
+
+func parseGSV(h Header, r *fields) GSV {
+	if !r.need(3) {
+		return GSV{}
+	}
+	g := GSV{
+		Header: h,
+		Total:  r.int(0, "total"),
+		Number: r.int(1, "number"),
+		InView: r.int(2, "in view"),
+	}
+	n := len(r.f) - 3
+	switch n % 4 {
+	case 0:
+	case 1:
+		g.SignalID = r.int(len(r.f)-1, "signal id")
+	default:
+		r.err = fmt.Errorf("%w: GSV has %d satellite fields, want a multiple of 4", ErrMalformed, n)
+		return GSV{}
+	}
+	for i := 3; i+4 <= len(r.f); i += 4 {
+		g.Satellites = append(g.Satellites, Satellite{
+			PRN:       r.int(i, "prn"),
+			Elevation: r.int(i+1, "elevation"),
+			Azimuth:   r.int(i+2, "azimuth"),
+			SNR:       r.int(i+3, "snr"),
+		})
+	}
+	return g
+}
+
+// GLL is a position with its time.
+type GLL struct {
+	Header
+	Lat, Lon float64 // NaN without a fix
+	Time     Time
+	Status   string // "A" valid, "V" invalid
+	Mode     string
+}
+
+// Valid reports whether the receiver flagged the position as valid.
+func (g GLL) Valid() bool { return g.Status == "A" }
+
+func parseGLL(h Header, r *fields) GLL {
+	if !r.need(4) {
+		return GLL{}
+	}
+	g := GLL{Header: h}
+	g.Lat, g.Lon = r.latLon(0)
+	g.Time = r.time(4)
+	g.Status = r.str(5)
+	g.Mode = r.str(6)
+	return g
+}
+
+// ZDA is the UTC date and time, with the local time zone.
+type ZDA struct {
+	Header
+	Time        Time
+	Date        Date
+	ZoneHours   int
+	ZoneMinutes int
+}
+
+// DateTime returns the UTC time, if both date and time are set.
+func (z ZDA) DateTime() (time.Time, bool) { return DateTime(z.Date, z.Time) }
+
+func parseZDA(h Header, r *fields) ZDA {
+	if !r.need(4) {
+		return ZDA{}
+	}
+	z := ZDA{Header: h, Time: r.time(0)}
+	if r.str(1) != "" || r.str(2) != "" || r.str(3) != "" {
+		z.Date = Date{Valid: true, Day: r.int(1, "day"), Month: r.int(2, "month"), Year: r.int(3, "year")}
+		if r.err == nil && (!validDate(z.Date) || len(r.str(3)) != 4 || !isDigits(r.str(3))) {
+			r.fail("date", fmt.Sprintf("%s,%s,%s", r.str(1), r.str(2), r.str(3)))
+		}
+	}
+	z.ZoneHours = r.int(4, "zone hours")
+	z.ZoneMinutes = r.int(5, "zone minutes")
+	return z
+}
@@ -0,0 +1,132 @@
+package nmea
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse[T Sentence](t *testing.T, line string) T {
+	t.Helper()
+	s, err := Parse(line)
+	require.NoError(t, err)
+	v, ok := s.(T)
+	require.True(t, ok, "got %T", s)
+	return v
+}
+
+func TestParseGGA(t *testing.T) {
+	t.Parallel()
+
+	g := parse[GGA](t, "$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*59")
+	assert.Equal(t, Time{Valid: true, Hour: 12, Minute: 35, Second: 19}, g.Time)
+	assert.InDelta(t, 48.1173, g.Lat, 1e-4)
+	assert.InDelta(t, 11.5167, g.Lon, 1e-4)
+	assert.Equal(t, 1, g.Quality)
+	assert.Equal(t, 8, g.Satellites)
+	assert.Equal(t, 0.9, g.HDOP)
+	assert.Equal(t, 545.4, g.Altitude)
+	assert.Equal(t, 46.9, g.GeoidSep)
+	assert.True(t, math.IsNaN(g.DGPSAge))
+
+	// No fix yet: empty position, no time.
+	g = parse[GGA](t, "$GPGGA,,,,,,0,00,99.99,,,,,,*48")
+	assert.True(t, math.IsNaN(g.Lat))
+	assert.True(t, math.IsNaN(g.Lon))
+	assert.False(t, g.Time.Valid)
+	assert.Equal(t, 0, g.Quality)
+	assert.True(t, math.IsNaN(g.Altitude))
+}
+
+func TestParseRMC(t *testing.T) {
+	t.Parallel()
+
+	m := parse[RMC](t, "$GPRMC,123519.25,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W,A*2E")
+	assert.True(t, m.Valid())
+	assert.Equal(t, 250_000_000, m.Time.Nanosecond)
+	assert.Equal(t, 22.4, m.SpeedKnots)
+	assert.Equal(t, 84.4, m.CourseDeg)
+	assert.Equal(t, Date{Valid: true, Day: 23, Month: 3, Year: 1994}, m.Date)
+	assert.Equal(t, -3.1, m.MagVar)
+	assert.Equal(t, "A", m.Mode)
+	tm, ok := m.DateTime()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(1994, 3, 23, 12, 35, 19, 250e6, time.UTC), tm)
+
+	m = parse[RMC](t, "$GLRMC,,V,,,,,,,,,,N*4F")
+	assert.False(t, m.Valid())
+	assert.Equal(t, "GL", m.Talker)
+	assert.True(t, math.IsNaN(m.Lat))
+	assert.False(t, m.Date.Valid)
+	_, ok = m.DateTime()
+	assert.False(t, ok)
+}
+
+func TestParseVTG(t *testing.T) {
+	t.Parallel()
+
+	v := parse[VTG](t, "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K,A*25")
+	assert.Equal(t, 54.7, v.TrueCourse)
+	assert.Equal(t, 34.4, v.MagCourse)
+	assert.Equal(t, 5.5, v.SpeedKnots)
+	assert.Equal(t, 10.2, v.SpeedKPH)
+	assert.Equal(t, "A", v.Mode)
+}
+
+func TestParseGSA(t *testing.T) {
+	t.Parallel()
+
+	g := parse[GSA](t, "$GNGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1,1*3A")
+	assert.Equal(t, "A", g.Mode)
+	assert.Equal(t, 3, g.FixType)
+	assert.Equal(t, []int{4, 5, 9, 12, 24}, g.PRNs)
+	assert.Equal(t, 2.5, g.PDOP)
+	assert.Equal(t, 1.3, g.HDOP)
+	assert.Equal(t, 2.1, g.VDOP)
+	assert.Equal(t, 1, g.SystemID)
+}
+
+func TestParseGSV(t *testing.T) {
+	t.Parallel()
+
+	g := parse[GSV](t, "$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74")
+	assert.Equal(t, 3, g.Total)
+	assert.Equal(t, 1, g.Number)
+	assert.Equal(t, 11, g.InView)
+	require.Len(t, g.Satellites, 4)
+	assert.Equal(t, Satellite{PRN: 13, Elevation: 6, Azimuth: 292}, g.Satellites[3])
+
+	g = parse[GSV](t, "$GAGSV,1,1,02,07,40,083,46,12,19,314,,7*76")
+	assert.Equal(t, []Satellite{{7, 40, 83, 46}, {12, 19, 314, 0}}, g.Satellites)
+	assert.Equal(t, 7, g.SignalID)
+}
+
+func TestParseGLL(t *testing.T) {
+	t.Parallel()
+
+	g := parse[GLL](t, "$BDGLL,4916.45,N,12311.12,W,225444,A,A*4D")
+	assert.Equal(t, TalkerBeiDou, g.Talker)
+	assert.InDelta(t, 49.2742, g.Lat, 1e-4)
+	assert.InDelta(t, -123.1853, g.Lon, 1e-4)
+	assert.Equal(t, 22, g.Time.Hour)
+	assert.True(t, g.Valid())
+}
+
+func TestParseZDA(t *testing.T) {
+	t.Parallel()
+
+	z := parse[ZDA](t, "$GPZDA,201530.00,04,07,2002,-05,00*48")
+	assert.Equal(t, Date{Valid: true, Day: 4, Month: 7, Year: 2002}, z.Date)
+	assert.Equal(t, -5, z.ZoneHours)
+	tm, ok := z.DateTime()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2002, 7, 4, 20, 15, 30, 0, time.UTC), tm)
+
+	// Before the receiver knows the date.
+	z = parse[ZDA](t, "$GPZDA,201530.00,,,,,")
+	assert.False(t, z.Date.Valid)
+	assert.True(t, z.Time.Valid)
+}
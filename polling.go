@@ -53,6 +53,11 @@ type PollConfig[T any] struct {
 
 	// NewTicker optionally overrides ticker creation (tests).
 	NewTicker func(d time.Duration) Ticker
+
+	// Samples optionally receives each value as a timestamped Sample.
+	// Time is taken when Read returns, not when the value is delivered.
+	// Publishing is always drop-on-full. RunPoller closes Samples on exit.
+	Samples chan Sample[T]
 }
 
 var (
@@ -66,7 +71,8 @@ var (
 // - emits EventOpen at start
 // - optionally reads once immediately (EmitInitial)
 // - reads on each tick
-// - publishes samples to Out (drop-on-full by default)
+// - publishes samples to Out (drop-on-full by default), counted in base.Stats()
+// - optionally publishes timestamped Samples (always drop-on-full)
 // - emits EventInfo on sample, EventError on read errors
 // - on exit: stops ticker, closes Out, emits EventClose, closes Base events
 //
@@ -106,7 +112,21 @@ func RunPoller[T any](ctx context.Context, base *Base, out chan T, cfg PollConfi
 	t := newTicker(cfg.Interval)
 	defer t.Stop()
 
-	publish := func(v T) {
+	var seq uint64
+	read := func() (T, time.Time, error) {
+		v, err := cfg.Read(ctx)
+		return v, time.Now(), err
+	}
+
+	publish := func(v T, at time.Time) {
+		seq++
+		if cfg.Samples != nil {
+			select {
+			case cfg.Samples <- Sample[T]{Value: v, Time: at, Seq: seq, Quality: QualityGood}:
+			default:
+			}
+		}
+
 		if cfg.OnSample != nil {
 			cfg.OnSample(v)
 		}
@@ -114,12 +134,16 @@ func RunPoller[T any](ctx context.Context, base *Base, out chan T, cfg PollConfi
 		if cfg.DropOnFull {
 			select {
 			case out <- v:
+				base.SamplePublished()
 			default:
+				base.SampleDropped()
 			}
 		} else {
 			select {
 			case out <- v:
+				base.SamplePublished()
 			case <-ctx.Done():
+				base.SampleDropped()
 				return
 			}
 		}
@@ -133,29 +157,32 @@ func RunPoller[T any](ctx context.Context, base *Base, out chan T, cfg PollConfi
 
 	defer func() {
 		close(out)
+		if cfg.Samples != nil {
+			close(cfg.Samples)
+		}
 		base.Emit(EventClose, "stop", nil, nil)
 		base.Close()
 	}()
 
 	// initial sample
 	if cfg.EmitInitial {
-		v, err := cfg.Read(ctx)
+		v, at, err := read()
 		if err != nil {
 			base.Emit(EventError, "read failed", err, nil)
 		} else {
-			publish(v)
+			publish(v, at)
 		}
 	}
 
 	for {
 		select {
 		case <-t.C():
-			v, err := cfg.Read(ctx)
+			v, at, err := read()
 			if err != nil {
 				base.Emit(EventError, "read failed", err, nil)
 				continue
 			}
-			publish(v)
+			publish(v, at)
 
 		case <-ctx.Done():
 			return nil
@@ -167,3 +167,88 @@ func TestRunPoller_ContextCancelDuringBlockingSend(t *testing.T) {
 		t.Fatal("expected channel to have the fill value")
 	}
 }
+
+func TestRunPoller_SamplesStampedAtRead(t *testing.T) {
+	t.Parallel()
+
+	base := NewBase("sensor", 16)
+	out := make(chan int, 4)
+	samples := make(chan Sample[int], 4)
+
+	ft := &FakeTicker{Q: make(chan time.Time, 10)}
+	n := 0
+	var readDone time.Time
+	cfg := PollConfig[int]{
+		Interval:    time.Second,
+		EmitInitial: true,
+		DropOnFull:  true,
+		NewTicker:   func(time.Duration) Ticker { return ft },
+		Samples:     samples,
+		Read: func(ctx context.Context) (int, error) {
+			n++
+			readDone = time.Now()
+			return n, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- RunPoller[int](ctx, &base, out, cfg) }()
+
+	s1 := <-samples
+	require.Equal(t, 1, s1.Value)
+	require.Equal(t, uint64(1), s1.Seq)
+	require.Equal(t, QualityGood, s1.Quality)
+	require.False(t, s1.Time.Before(readDone))
+
+	// sample time is the read time, not the (later) delivery time
+	ft.Q <- time.Now()
+	time.Sleep(20 * time.Millisecond)
+	s2 := <-samples
+	require.Equal(t, uint64(2), s2.Seq)
+	require.Less(t, time.Since(s2.Time), time.Since(s1.Time))
+	require.GreaterOrEqual(t, time.Since(s2.Time), 20*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	_, ok := <-samples
+	require.False(t, ok)
+}
+
+func TestRunPoller_CountsPublishedAndDroppedSamples(t *testing.T) {
+	t.Parallel()
+
+	base := NewBase("sensor", 64)
+	out := make(chan int, 1)
+
+	ft := &FakeTicker{Q: make(chan time.Time, 10)}
+	reads := make(chan struct{}, 10)
+	cfg := PollConfig[int]{
+		Interval:    time.Second,
+		EmitInitial: true,
+		DropOnFull:  true,
+		NewTicker:   func(time.Duration) Ticker { return ft },
+		Read: func(ctx context.Context) (int, error) {
+			reads <- struct{}{}
+			return 1, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- RunPoller[int](ctx, &base, out, cfg) }()
+
+	<-reads
+	ft.Q <- time.Now()
+	<-reads
+	ft.Q <- time.Now()
+	<-reads
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	st := base.Stats()
+	require.Equal(t, uint64(1), st.SamplesPublished)
+	require.Equal(t, uint64(2), st.SamplesDropped)
+}
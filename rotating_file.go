@@ -0,0 +1,134 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an io.WriteCloser that rotates the file by size.
+//
+// When a write would grow the file past MaxBytes, the file is renamed to
+// path.1 (path.1 to path.2, and so on, keeping MaxBackups old files) and a
+// new file is started. Writes are never split across files, so each line
+// written by a JSONLSink stays intact.
+//
+// Example rotating event log:
+//
+//	f, err := devices.NewRotatingFile("/var/log/station/events.jsonl", 10<<20, 5)
+//	if err != nil { ... }
+//	sink := devices.NewJSONLSink(f)
+type RotatingFile struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingFile opens (or appends to) path.
+// maxBytes must be > 0; maxBackups < 0 is treated as 0.
+func NewRotatingFile(path string, maxBytes int64, maxBackups int) (*RotatingFile, error) {
+	if path == "" {
+		return nil, errors.New("rotating file: path is required")
+	}
+	if maxBytes <= 0 {
+		return nil, errors.New("rotating file: maxBytes must be > 0")
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+	r := &RotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("rotating file: open %s: %w", r.path, err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("rotating file: stat %s: %w", r.path, err)
+	}
+	r.f = f
+	r.size = st.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would not fit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts backups up by one and starts a new file. If a rename
+// fails, the current file is reopened so that later writes can retry.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("rotating file: close %s: %w", r.path, err)
+	}
+	r.f = nil
+
+	if r.maxBackups == 0 {
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return r.reopen(fmt.Errorf("rotating file: remove %s: %w", r.path, err))
+		}
+		return r.open()
+	}
+
+	_ = os.Remove(r.backup(r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(r.backup(i), r.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return r.reopen(fmt.Errorf("rotating file: rename: %w", err))
+		}
+	}
+	if err := os.Rename(r.path, r.backup(1)); err != nil {
+		return r.reopen(fmt.Errorf("rotating file: rename: %w", err))
+	}
+	return r.open()
+}
+
+// reopen appends to the current file again after a failed rotation and
+// returns err, with the open error if that fails too.
+func (r *RotatingFile) reopen(err error) error {
+	if oerr := r.open(); oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	return err
+}
+
+func (r *RotatingFile) backup(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// Close closes the current file. Further writes return os.ErrClosed.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,93 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileValidation(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRotatingFile("", 10, 1)
+	require.Error(t, err)
+	_, err = NewRotatingFile(filepath.Join(t.TempDir(), "x"), 0, 1)
+	require.Error(t, err)
+}
+
+func TestRotatingFileRotatesAndKeepsBackups(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	r, err := NewRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	read := func(p string) string {
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(b)
+	}
+	assert.Equal(t, "dddddd\n", read(path))
+	assert.Equal(t, "cccccc\n", read(path+".1"))
+	assert.Equal(t, "bbbbbb\n", read(path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = r.Write([]byte("x"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingFileAppendsExisting(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("12345\n"), 0o644))
+
+	r, err := NewRotatingFile(path, 8, 0)
+	require.NoError(t, err)
+	_, err = r.Write([]byte("678\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "678\n", string(b), "no backups kept")
+}
+
+func TestRotatingFileSurvivesFailedRename(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	r, err := NewRotatingFile(path, 10, 1)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// A non-empty directory where the backup goes makes the rename fail.
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "x"), 0o755))
+
+	_, err = r.Write([]byte("aaaaaa\n"))
+	require.NoError(t, err)
+	_, err = r.Write([]byte("bbbbbb\n"))
+	require.ErrorContains(t, err, "rename")
+
+	// Once the obstacle is gone, writing resumes with a rotation.
+	require.NoError(t, os.RemoveAll(path+".1"))
+	_, err = r.Write([]byte("bbbbbb\n"))
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "bbbbbb\n", string(b))
+	b, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaaa\n", string(b))
+}
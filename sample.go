@@ -0,0 +1,76 @@
+package devices
+
+import (
+	"context"
+	"time"
+)
+
+// Quality flags how trustworthy a Sample is.
+type Quality string
+
+const (
+	// QualityGood marks a value read successfully from the hardware.
+	QualityGood Quality = "good"
+	// QualityUncertain marks a value whose timing or origin is approximate,
+	// e.g. stamped on delivery rather than when it was read.
+	QualityUncertain Quality = "uncertain"
+	// QualityBad marks a value known to be invalid.
+	QualityBad Quality = "bad"
+)
+
+// Sample is a value together with when it was taken.
+//
+// Time is when the value was read (or, for edge-driven inputs, when the
+// edge happened), not when it was delivered. Seq increases by one for each
+// value a device produces, so a gap means samples were dropped between the
+// device and the consumer.
+type Sample[T any] struct {
+	Value   T
+	Time    time.Time
+	Seq     uint64
+	Quality Quality
+}
+
+// Age returns how old the sample is relative to now.
+func (s Sample[T]) Age(now time.Time) time.Duration {
+	return now.Sub(s.Time)
+}
+
+// Stale reports whether the sample is older than maxAge.
+func (s Sample[T]) Stale(now time.Time, maxAge time.Duration) bool {
+	return s.Age(now) > maxAge
+}
+
+// SampleSource is implemented by devices that publish a timestamped
+// Sample stream alongside their plain Out() stream.
+//
+// The OutSamples channel is best-effort (drop-on-full) and is closed when
+// Run returns, like Out().
+type SampleSource[T any] interface {
+	OutSamples() <-chan Sample[T]
+}
+
+// Samples adapts any Source into a Source of Samples.
+//
+// Devices that implement SampleSource should be preferred: this adapter
+// can only stamp values when they are received, so samples are marked
+// QualityUncertain.
+func Samples[T any](src Source[T]) Source[Sample[T]] {
+	return &sampler[T]{
+		wrapper: newWrapper[T, Sample[T]](src, 16),
+	}
+}
+
+type sampler[T any] struct {
+	wrapper[T, Sample[T]]
+}
+
+func (s *sampler[T]) Run(ctx context.Context) error {
+	return s.run(ctx, func(in <-chan T) {
+		var seq uint64
+		for v := range in {
+			seq++
+			s.send(Sample[T]{Value: v, Time: time.Now(), Seq: seq, Quality: QualityUncertain})
+		}
+	})
+}
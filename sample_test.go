@@ -0,0 +1,49 @@
+package devices
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleAgeAndStale(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(100, 0)
+	s := Sample[float64]{Value: 1.5, Time: now.Add(-3 * time.Second), Seq: 1, Quality: QualityGood}
+
+	assert.Equal(t, 3*time.Second, s.Age(now))
+	assert.True(t, s.Stale(now, 2*time.Second))
+	assert.False(t, s.Stale(now, 5*time.Second))
+}
+
+func TestSamplesAdapterStampsAndSequences(t *testing.T) {
+	t.Parallel()
+
+	feed := make(chan int)
+	src := Samples[int](feedSource("src", feed))
+	assert.Equal(t, "src", src.Name())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- src.Run(ctx) }()
+
+	before := time.Now()
+	feed <- 10
+	feed <- 11
+
+	s1 := recv(t, src.Out())
+	s2 := recv(t, src.Out())
+	assert.Equal(t, 10, s1.Value)
+	assert.Equal(t, uint64(1), s1.Seq)
+	assert.Equal(t, 11, s2.Value)
+	assert.Equal(t, uint64(2), s2.Seq)
+	assert.Equal(t, QualityUncertain, s1.Quality)
+	assert.False(t, s1.Time.Before(before))
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
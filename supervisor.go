@@ -0,0 +1,324 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RestartPolicy controls when a Supervisor restarts its device.
+type RestartPolicy string
+
+const (
+	// RestartNever runs the device once.
+	RestartNever RestartPolicy = "never"
+	// RestartOnFailure restarts the device only when Run returns an error.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways restarts the device whenever Run returns before ctx is canceled.
+	RestartAlways RestartPolicy = "always"
+)
+
+var (
+	ErrSupervisorNew  = errors.New("supervisor New func is nil")
+	ErrSupervisorNil  = errors.New("supervisor New returned nil device")
+	ErrRestartLimit   = errors.New("restart limit reached")
+	ErrUnknownRestart = errors.New("unknown restart policy")
+)
+
+// SupervisorConfig configures a Supervisor.
+type SupervisorConfig struct {
+	Name string
+
+	// New constructs a fresh device for every run.
+	//
+	// A constructor is required rather than an instance because a device's
+	// Base (and its output channels) are closed permanently when Run returns.
+	New func() Device
+
+	// Policy selects when to restart. Default RestartOnFailure.
+	Policy RestartPolicy
+
+	// InitialBackoff is the delay before the first restart. Default 1s.
+	// Each consecutive restart doubles the delay up to MaxBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the restart delay. Default 1m.
+	// A run that lasts at least MaxBackoff resets the delay to InitialBackoff.
+	MaxBackoff time.Duration
+
+	// MaxRestarts limits the number of restarts within Window.
+	// Zero means unlimited.
+	MaxRestarts int
+
+	// Window is the period MaxRestarts applies to.
+	// Zero means the lifetime of the Supervisor.
+	Window time.Duration
+
+	// EventBuf sizes the supervisor event channel. Default 16.
+	EventBuf int
+
+	// After optionally overrides backoff timers (tests).
+	After func(d time.Duration) <-chan time.Time
+}
+
+// Supervisor runs a device and restarts it according to a RestartPolicy.
+//
+// The Supervisor is itself a Device. Events from the supervised device are
+// forwarded unchanged on the Supervisor's Events channel, interleaved with
+// the Supervisor's own EventInfo/EventError restart notifications.
+type Supervisor struct {
+	Base
+	cfg SupervisorConfig
+
+	mu       sync.Mutex
+	current  Device
+	restarts int
+
+	// attach is called for every new device before it runs; the returned
+	// func is called after its Run has returned. Used by SupervisedSource.
+	attach func(d Device, done <-chan struct{}) func()
+}
+
+// NewSupervisor constructs a Supervisor with defaults applied.
+func NewSupervisor(cfg SupervisorConfig) *Supervisor {
+	if cfg.Policy == "" {
+		cfg.Policy = RestartOnFailure
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+	if cfg.After == nil {
+		cfg.After = time.After
+	}
+	return &Supervisor{
+		Base: NewBase(cfg.Name, cfg.EventBuf),
+		cfg:  cfg,
+	}
+}
+
+// Current returns the device instance that is running (or ran last).
+func (s *Supervisor) Current() Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Restarts returns the number of restarts performed so far.
+func (s *Supervisor) Restarts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restarts
+}
+
+// Run runs the device, restarting it per the policy, until ctx is canceled,
+// the policy says stop, or the restart limit is reached.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.Emit(EventOpen, "run", nil, nil)
+	defer func() {
+		s.Emit(EventClose, "stop", nil, nil)
+		s.Close()
+	}()
+
+	if s.cfg.New == nil {
+		s.Emit(EventError, "new func missing", ErrSupervisorNew, nil)
+		return ErrSupervisorNew
+	}
+	switch s.cfg.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		err := fmt.Errorf("%w: %q", ErrUnknownRestart, s.cfg.Policy)
+		s.Emit(EventError, "invalid policy", err, nil)
+		return err
+	}
+
+	backoff := s.cfg.InitialBackoff
+	var history []time.Time
+
+	for {
+		d := s.cfg.New()
+		if d == nil {
+			s.Emit(EventError, "new device failed", ErrSupervisorNil, nil)
+			return ErrSupervisorNil
+		}
+
+		started := time.Now()
+		err := s.runOnce(ctx, d)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			s.Emit(EventError, "device failed", err, map[string]string{"device": d.Name()})
+		}
+
+		switch {
+		case s.cfg.Policy == RestartNever:
+			return err
+		case s.cfg.Policy == RestartOnFailure && err == nil:
+			return nil
+		}
+
+		now := time.Now()
+		if s.cfg.MaxRestarts > 0 {
+			if s.cfg.Window > 0 {
+				keep := history[:0]
+				for _, t := range history {
+					if now.Sub(t) < s.cfg.Window {
+						keep = append(keep, t)
+					}
+				}
+				history = keep
+			}
+			if len(history) >= s.cfg.MaxRestarts {
+				limitErr := fmt.Errorf("%w: %d restarts", ErrRestartLimit, len(history))
+				if err != nil {
+					limitErr = fmt.Errorf("%w: %w", limitErr, err)
+				}
+				s.Emit(EventError, "restart limit reached", limitErr, nil)
+				return limitErr
+			}
+		}
+		history = append(history, now)
+
+		// A run that stayed up long enough starts the backoff over.
+		if now.Sub(started) >= s.cfg.MaxBackoff {
+			backoff = s.cfg.InitialBackoff
+		}
+
+		s.mu.Lock()
+		s.restarts++
+		attempt := s.restarts
+		s.mu.Unlock()
+
+		meta := map[string]string{
+			"attempt": Itoa(attempt),
+			"backoff": backoff.String(),
+			"policy":  string(s.cfg.Policy),
+		}
+		if err != nil {
+			meta["error"] = err.Error()
+		}
+		s.Emit(EventInfo, "restart", nil, meta)
+
+		select {
+		case <-s.cfg.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > s.cfg.MaxBackoff {
+			backoff = s.cfg.MaxBackoff
+		}
+	}
+}
+
+// runOnce runs a single device instance, forwarding its events, and
+// closes it once Run has returned.
+func (s *Supervisor) runOnce(ctx context.Context, d Device) error {
+	s.mu.Lock()
+	s.current = d
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		forward(d.Events(), done, s.publish)
+	}()
+
+	var detach func()
+	if s.attach != nil {
+		detach = s.attach(d, done)
+	}
+
+	err := d.Run(ctx)
+	close(done)
+	wg.Wait()
+	if detach != nil {
+		detach()
+	}
+	_ = d.Close()
+	return err
+}
+
+// Close closes the current device and the Supervisor's events.
+func (s *Supervisor) Close() error {
+	if d := s.Current(); d != nil {
+		_ = d.Close()
+	}
+	return s.Base.Close()
+}
+
+// SupervisedSource supervises a Source and republishes its values on a
+// stable Out channel that survives restarts.
+type SupervisedSource[T any] struct {
+	*Supervisor
+	out chan T
+}
+
+// NewSupervisedSource constructs a SupervisedSource.
+// cfg.New is ignored; newSrc constructs each fresh Source instead.
+// buf sizes the Out channel (default 16).
+func NewSupervisedSource[T any](cfg SupervisorConfig, buf int, newSrc func() Source[T]) *SupervisedSource[T] {
+	if buf <= 0 {
+		buf = 16
+	}
+	cfg.New = nil
+	if newSrc != nil {
+		cfg.New = func() Device {
+			src := newSrc()
+			if src == nil {
+				return nil
+			}
+			return src
+		}
+	}
+
+	s := &SupervisedSource[T]{
+		Supervisor: NewSupervisor(cfg),
+		out:        make(chan T, buf),
+	}
+	s.attach = func(d Device, done <-chan struct{}) func() {
+		src := d.(Source[T])
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			forward(src.Out(), done, s.send)
+		}()
+		return wg.Wait
+	}
+	return s
+}
+
+// Out returns the value stream; it is closed when Run returns.
+func (s *SupervisedSource[T]) Out() <-chan T { return s.out }
+
+// Run supervises the source until ctx is canceled or supervision stops.
+func (s *SupervisedSource[T]) Run(ctx context.Context) error {
+	defer close(s.out)
+	return s.Supervisor.Run(ctx)
+}
+
+// send publishes to the stable out channel, dropping if it is full.
+func (s *SupervisedSource[T]) send(v T) {
+	select {
+	case s.out <- v:
+	default:
+	}
+}
+
+var (
+	_ Device       = (*Supervisor)(nil)
+	_ Source[bool] = (*SupervisedSource[bool])(nil)
+)
@@ -0,0 +1,230 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// immediateAfter fires backoff timers at once and records requested delays.
+type immediateAfter struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (a *immediateAfter) After(d time.Duration) <-chan time.Time {
+	a.mu.Lock()
+	a.delays = append(a.delays, d)
+	a.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func (a *immediateAfter) Delays() []time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]time.Duration(nil), a.delays...)
+}
+
+func failingDevice(err error) func() Device {
+	return func() Device {
+		return newManagedDevice("inner", "x", func(ctx context.Context, b *Base) error {
+			b.Emit(EventError, "open failed", err, nil)
+			return err
+		})
+	}
+}
+
+func drainEvents(ch <-chan Event) []Event {
+	var evs []Event
+	for ev := range ch {
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+func TestSupervisorDefaults(t *testing.T) {
+	t.Parallel()
+
+	s := NewSupervisor(SupervisorConfig{Name: "sup"})
+	assert.Equal(t, "sup", s.Name())
+	assert.Equal(t, RestartOnFailure, s.cfg.Policy)
+	assert.Equal(t, time.Second, s.cfg.InitialBackoff)
+	assert.Equal(t, time.Minute, s.cfg.MaxBackoff)
+}
+
+func TestSupervisorConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	s := NewSupervisor(SupervisorConfig{Name: "sup"})
+	require.ErrorIs(t, s.Run(context.Background()), ErrSupervisorNew)
+
+	s = NewSupervisor(SupervisorConfig{Name: "sup", Policy: "sometimes", New: failingDevice(assert.AnError)})
+	require.ErrorIs(t, s.Run(context.Background()), ErrUnknownRestart)
+
+	s = NewSupervisor(SupervisorConfig{Name: "sup", New: func() Device { return nil }})
+	require.ErrorIs(t, s.Run(context.Background()), ErrSupervisorNil)
+}
+
+func TestSupervisorRestartNever(t *testing.T) {
+	t.Parallel()
+
+	errOpen := errors.New("open i2c failed")
+	s := NewSupervisor(SupervisorConfig{
+		Name:   "sup",
+		Policy: RestartNever,
+		New:    failingDevice(errOpen),
+	})
+
+	require.ErrorIs(t, s.Run(context.Background()), errOpen)
+	assert.Equal(t, 0, s.Restarts())
+}
+
+func TestSupervisorOnFailureBackoffAndLimit(t *testing.T) {
+	t.Parallel()
+
+	errOpen := errors.New("open i2c failed")
+	after := &immediateAfter{}
+	s := NewSupervisor(SupervisorConfig{
+		Name:           "sup",
+		New:            failingDevice(errOpen),
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     30 * time.Millisecond,
+		MaxRestarts:    4,
+		EventBuf:       64,
+		After:          after.After,
+	})
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, ErrRestartLimit)
+	require.ErrorIs(t, err, errOpen)
+	assert.Equal(t, 4, s.Restarts())
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		30 * time.Millisecond,
+	}, after.Delays())
+
+	var restarts, innerErrors int
+	for _, ev := range drainEvents(s.Events()) {
+		switch {
+		case ev.Device == "sup" && ev.Kind == EventInfo && ev.Msg == "restart":
+			restarts++
+			assert.Equal(t, "open i2c failed", ev.Meta["error"])
+		case ev.Device == "inner" && ev.Kind == EventError:
+			innerErrors++
+		}
+	}
+	assert.Equal(t, 4, restarts)
+	assert.Equal(t, 5, innerErrors, "events of every instance are forwarded")
+}
+
+func TestSupervisorOnFailureStopsOnCleanExit(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	s := NewSupervisor(SupervisorConfig{
+		Name: "sup",
+		New: func() Device {
+			runs++
+			return newManagedDevice("inner", "x", func(ctx context.Context, b *Base) error { return nil })
+		},
+	})
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, 1, runs)
+}
+
+func TestSupervisorRestartAlwaysUntilCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	s := NewSupervisor(SupervisorConfig{
+		Name:   "sup",
+		Policy: RestartAlways,
+		After:  (&immediateAfter{}).After,
+		New: func() Device {
+			runs++
+			if runs == 3 {
+				return newManagedDevice("inner", "x", runUntilCanceled)
+			}
+			return newManagedDevice("inner", "x", func(ctx context.Context, b *Base) error { return nil })
+		},
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return s.Restarts() == 2 }, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-errCh)
+	assert.Equal(t, 3, runs)
+}
+
+func TestSupervisedSourceStableOut(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	s := NewSupervisedSource[int](SupervisorConfig{
+		Name:   "sup",
+		Policy: RestartAlways,
+		After:  (&immediateAfter{}).After,
+	}, 4, func() Source[int] {
+		runs++
+		n := runs
+		return newTestSource("inner", func(ctx context.Context, out chan int) error {
+			out <- n
+			if n < 3 {
+				return errors.New("boom")
+			}
+			<-ctx.Done()
+			return nil
+		})
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Run(ctx) }()
+
+	assert.Equal(t, 1, <-s.Out())
+	assert.Equal(t, 2, <-s.Out())
+	assert.Equal(t, 3, <-s.Out())
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	_, ok := <-s.Out()
+	assert.False(t, ok)
+}
+
+type testSource struct {
+	Base
+	out chan int
+	run func(ctx context.Context, out chan int) error
+}
+
+func newTestSource(name string, run func(ctx context.Context, out chan int) error) *testSource {
+	return &testSource{Base: NewBase(name, 16), out: make(chan int, 16), run: run}
+}
+
+func (s *testSource) Out() <-chan int { return s.out }
+
+func (s *testSource) Run(ctx context.Context) error {
+	defer func() {
+		close(s.out)
+		s.Close()
+	}()
+	return s.run(ctx, s.out)
+}